@@ -10,6 +10,7 @@ import (
 
 	"github.com/smallnest/goskills"
 	"github.com/smallnest/goskills/tool"
+	lgtool "github.com/smallnest/langgraphgo/tool"
 	"github.com/tmc/langchaingo/tools"
 )
 
@@ -19,6 +20,7 @@ type SkillTool struct {
 	description string
 	scriptMap   map[string]string
 	skillPath   string
+	parameters  any // JSON schema for the tool parameters
 }
 
 var _ tools.Tool = &SkillTool{}
@@ -31,6 +33,13 @@ func (t *SkillTool) Description() string {
 	return t.description
 }
 
+// Schema returns the JSON schema of the tool parameters, so agents built with
+// the prebuilt package advertise the real parameters to the model and pass the
+// full arguments object to Call.
+func (t *SkillTool) Schema() map[string]any {
+	return lgtool.SchemaMap(t.parameters)
+}
+
 func (t *SkillTool) Call(ctx context.Context, input string) (string, error) {
 	// input is the JSON string of arguments
 	// We need to parse it based on the tool name, similar to goskills runner.go
@@ -174,33 +183,12 @@ func SkillsToTools(skill goskills.SkillPackage) ([]tools.Tool, error) {
 			continue
 		}
 
-		// Create a description that includes the arguments schema if possible,
-		// but langchaingo tools usually just have a text description.
-		// We can append the JSON schema of parameters to the description to help the LLM.
-		desc := t.Function.Description
-		if t.Function.Parameters != nil {
-			// Convert parameters to JSON string to include in description?
-			// Or just rely on the fact that langchaingo might not use this description for function calling definition if we use bindTools?
-			// Wait, langchaingo's BindTools usually takes the tool struct and inspects it, or takes a definition.
-			// If we return tools.Tool, we are returning an interface.
-			// When using with langchaingo, we often use `tools.Tool` with `BindTools`.
-			// However, `BindTools` in langchaingo often expects structs with fields to infer schema, OR it calls `Name`, `Description`.
-			// If we want to support function calling properly, we might need to implement `Call` but also provide the schema.
-			// But `tools.Tool` interface doesn't have a `Schema` method.
-			// Langchaingo's `BindTools` often uses reflection on the tool struct if it's a struct, or if it's a `Tool` interface, it might be limited.
-			// Actually, for `BindTools` to work with dynamic tools, we might need to pass the schema explicitly or use a specific implementation.
-
-			// BUT, the user asked for "convenience methods to use goskills as []tools.Tool".
-			// If the user uses `prebuilt.create_agent`, it takes `[]tools.Tool`.
-			// `prebuilt.create_agent` uses `BindTools`.
-			// Let's check how `langgraphgo` handles tools.
-		}
-
 		result = append(result, &SkillTool{
 			name:        t.Function.Name,
-			description: desc,
+			description: t.Function.Description,
 			scriptMap:   scriptMap,
 			skillPath:   skill.Path,
+			parameters:  t.Function.Parameters,
 		})
 	}
 
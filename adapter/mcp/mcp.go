@@ -7,6 +7,7 @@ import (
 
 	"github.com/sashabaranov/go-openai"
 	mcpclient "github.com/smallnest/goskills/mcp"
+	lgtool "github.com/smallnest/langgraphgo/tool"
 	"github.com/tmc/langchaingo/tools"
 )
 
@@ -28,6 +29,13 @@ func (t *MCPTool) Description() string {
 	return t.description
 }
 
+// Schema returns the JSON schema of the tool parameters, so agents built with
+// the prebuilt package advertise the real MCP parameters to the model and pass
+// the full arguments object to Call.
+func (t *MCPTool) Schema() map[string]any {
+	return lgtool.SchemaMap(t.parameters)
+}
+
 func (t *MCPTool) Call(ctx context.Context, input string) (string, error) {
 	// Parse input JSON into a map
 	var args map[string]interface{}
@@ -2,6 +2,7 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -104,3 +105,22 @@ func ExampleNewClientFromConfig() {
 	// Now you can use the client to get tools or call them directly
 	_ = client
 }
+
+func TestMCPTool_Schema(t *testing.T) {
+	schema := map[string]any{
+		"type":     "object",
+		"required": []string{"path"},
+	}
+	tool := &MCPTool{name: "read", parameters: schema}
+	assert.Equal(t, schema, tool.Schema())
+
+	// Schemas that are not maps are converted through JSON
+	tool = &MCPTool{name: "read", parameters: json.RawMessage(`{"type": "object"}`)}
+	assert.Equal(t, map[string]any{"type": "object"}, tool.Schema())
+
+	tool = &MCPTool{name: "read"}
+	assert.Nil(t, tool.Schema())
+
+	// Agents detect the schema through this method
+	var _ interface{ Schema() map[string]any } = tool
+}
@@ -71,10 +71,20 @@ func (s *PostgresCheckpointStore) InitSchema(ctx context.Context) error {
 			state JSONB NOT NULL,
 			metadata JSONB,
 			timestamp TIMESTAMPTZ NOT NULL,
-			version INTEGER NOT NULL
+			version INTEGER NOT NULL,
+			next_nodes JSONB,
+			step INTEGER NOT NULL DEFAULT 0,
+			parent_id TEXT NOT NULL DEFAULT '',
+			interrupt JSONB,
+			pending_writes JSONB
 		);
 		CREATE INDEX IF NOT EXISTS idx_%s_execution_id ON %s (execution_id);
-	`, s.tableName, s.tableName, s.tableName)
+		ALTER TABLE %s ADD COLUMN IF NOT EXISTS next_nodes JSONB;
+		ALTER TABLE %s ADD COLUMN IF NOT EXISTS step INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE %s ADD COLUMN IF NOT EXISTS parent_id TEXT NOT NULL DEFAULT '';
+		ALTER TABLE %s ADD COLUMN IF NOT EXISTS interrupt JSONB;
+		ALTER TABLE %s ADD COLUMN IF NOT EXISTS pending_writes JSONB;
+	`, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName)
 
 	_, err := s.pool.Exec(ctx, query)
 	if err != nil {
@@ -100,21 +110,41 @@ func (s *PostgresCheckpointStore) Save(ctx context.Context, checkpoint *graph.Ch
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
+	nextJSON, err := json.Marshal(checkpoint.Next)
+	if err != nil {
+		return fmt.Errorf("failed to marshal next nodes: %w", err)
+	}
+
+	interruptJSON, err := json.Marshal(checkpoint.Interrupt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal interrupt: %w", err)
+	}
+
+	pendingWritesJSON, err := json.Marshal(checkpoint.PendingWrites)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending writes: %w", err)
+	}
+
 	executionID := ""
 	if id, ok := checkpoint.Metadata["execution_id"].(string); ok {
 		executionID = id
 	}
 
 	query := fmt.Sprintf(`
-		INSERT INTO %s (id, execution_id, node_name, state, metadata, timestamp, version)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO %s (id, execution_id, node_name, state, metadata, timestamp, version, next_nodes, step, parent_id, interrupt, pending_writes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		ON CONFLICT (id) DO UPDATE SET
 			execution_id = EXCLUDED.execution_id,
 			node_name = EXCLUDED.node_name,
 			state = EXCLUDED.state,
 			metadata = EXCLUDED.metadata,
 			timestamp = EXCLUDED.timestamp,
-			version = EXCLUDED.version
+			version = EXCLUDED.version,
+			next_nodes = EXCLUDED.next_nodes,
+			step = EXCLUDED.step,
+			parent_id = EXCLUDED.parent_id,
+			interrupt = EXCLUDED.interrupt,
+			pending_writes = EXCLUDED.pending_writes
 	`, s.tableName)
 
 	_, err = s.pool.Exec(ctx, query,
@@ -125,6 +155,11 @@ func (s *PostgresCheckpointStore) Save(ctx context.Context, checkpoint *graph.Ch
 		metadataJSON,
 		checkpoint.Timestamp,
 		checkpoint.Version,
+		nextJSON,
+		checkpoint.Step,
+		checkpoint.ParentID,
+		interruptJSON,
+		pendingWritesJSON,
 	)
 
 	if err != nil {
@@ -137,7 +172,7 @@ func (s *PostgresCheckpointStore) Save(ctx context.Context, checkpoint *graph.Ch
 // Load retrieves a checkpoint by ID
 func (s *PostgresCheckpointStore) Load(ctx context.Context, checkpointID string) (*graph.Checkpoint, error) {
 	query := fmt.Sprintf(`
-		SELECT id, node_name, state, metadata, timestamp, version
+		SELECT id, node_name, state, metadata, timestamp, version, next_nodes, step, parent_id, interrupt, pending_writes
 		FROM %s
 		WHERE id = $1
 	`, s.tableName)
@@ -145,6 +180,7 @@ func (s *PostgresCheckpointStore) Load(ctx context.Context, checkpointID string)
 	var cp graph.Checkpoint
 	var stateJSON []byte
 	var metadataJSON []byte
+	var nextJSON, interruptJSON, pendingWritesJSON []byte
 
 	err := s.pool.QueryRow(ctx, query, checkpointID).Scan(
 		&cp.ID,
@@ -153,6 +189,11 @@ func (s *PostgresCheckpointStore) Load(ctx context.Context, checkpointID string)
 		&metadataJSON,
 		&cp.Timestamp,
 		&cp.Version,
+		&nextJSON,
+		&cp.Step,
+		&cp.ParentID,
+		&interruptJSON,
+		&pendingWritesJSON,
 	)
 
 	if err != nil {
@@ -172,13 +213,40 @@ func (s *PostgresCheckpointStore) Load(ctx context.Context, checkpointID string)
 		}
 	}
 
+	if err := unmarshalFrontier(&cp, nextJSON, interruptJSON, pendingWritesJSON); err != nil {
+		return nil, err
+	}
+
 	return &cp, nil
 }
 
+// unmarshalFrontier decodes the next nodes, pending interrupt and pending writes of a checkpoint
+func unmarshalFrontier(cp *graph.Checkpoint, nextJSON, interruptJSON, pendingWritesJSON []byte) error {
+	if len(nextJSON) > 0 {
+		if err := json.Unmarshal(nextJSON, &cp.Next); err != nil {
+			return fmt.Errorf("failed to unmarshal next nodes: %w", err)
+		}
+	}
+
+	if len(interruptJSON) > 0 {
+		if err := json.Unmarshal(interruptJSON, &cp.Interrupt); err != nil {
+			return fmt.Errorf("failed to unmarshal interrupt: %w", err)
+		}
+	}
+
+	if len(pendingWritesJSON) > 0 {
+		if err := json.Unmarshal(pendingWritesJSON, &cp.PendingWrites); err != nil {
+			return fmt.Errorf("failed to unmarshal pending writes: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // List returns all checkpoints for a given execution
 func (s *PostgresCheckpointStore) List(ctx context.Context, executionID string) ([]*graph.Checkpoint, error) {
 	query := fmt.Sprintf(`
-		SELECT id, node_name, state, metadata, timestamp, version
+		SELECT id, node_name, state, metadata, timestamp, version, next_nodes, step, parent_id, interrupt, pending_writes
 		FROM %s
 		WHERE execution_id = $1
 		ORDER BY timestamp ASC
@@ -195,6 +263,7 @@ func (s *PostgresCheckpointStore) List(ctx context.Context, executionID string)
 		var cp graph.Checkpoint
 		var stateJSON []byte
 		var metadataJSON []byte
+		var nextJSON, interruptJSON, pendingWritesJSON []byte
 
 		err := rows.Scan(
 			&cp.ID,
@@ -203,6 +272,11 @@ func (s *PostgresCheckpointStore) List(ctx context.Context, executionID string)
 			&metadataJSON,
 			&cp.Timestamp,
 			&cp.Version,
+			&nextJSON,
+			&cp.Step,
+			&cp.ParentID,
+			&interruptJSON,
+			&pendingWritesJSON,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan checkpoint row: %w", err)
@@ -218,6 +292,10 @@ func (s *PostgresCheckpointStore) List(ctx context.Context, executionID string)
 			}
 		}
 
+		if err := unmarshalFrontier(&cp, nextJSON, interruptJSON, pendingWritesJSON); err != nil {
+			return nil, err
+		}
+
 		checkpoints = append(checkpoints, &cp)
 	}
 
@@ -28,10 +28,20 @@ func TestPostgresCheckpointStore_Save(t *testing.T) {
 		Metadata: map[string]interface{}{
 			"execution_id": "exec-1",
 		},
+		Next:      []string{"node-b"},
+		Step:      2,
+		ParentID:  "cp-0",
+		Interrupt: &graph.PendingInterrupt{Node: "node-b", Value: "approve?"},
+		PendingWrites: []graph.PendingWrite{
+			{Task: 1, Node: "node-c", Value: "done"},
+		},
 	}
 
 	stateJSON, _ := json.Marshal(cp.State)
 	metadataJSON, _ := json.Marshal(cp.Metadata)
+	nextJSON, _ := json.Marshal(cp.Next)
+	interruptJSON, _ := json.Marshal(cp.Interrupt)
+	pendingWritesJSON, _ := json.Marshal(cp.PendingWrites)
 
 	// Expect INSERT
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO checkpoints")).
@@ -43,6 +53,11 @@ func TestPostgresCheckpointStore_Save(t *testing.T) {
 			metadataJSON,
 			cp.Timestamp,
 			cp.Version,
+			nextJSON,
+			cp.Step,
+			cp.ParentID,
+			interruptJSON,
+			pendingWritesJSON,
 		).
 		WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
@@ -66,11 +81,14 @@ func TestPostgresCheckpointStore_Load(t *testing.T) {
 
 	stateJSON, _ := json.Marshal(state)
 	metadataJSON, _ := json.Marshal(metadata)
+	nextJSON := []byte(`["node-b"]`)
+	interruptJSON := []byte(`{"node":"node-b","value":"approve?"}`)
+	pendingWritesJSON := []byte(`[{"task":1,"node":"node-c","value":"done"}]`)
 
-	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version"}).
-		AddRow(cpID, "node-a", stateJSON, metadataJSON, timestamp, 1)
+	rows := pgxmock.NewRows([]string{"id", "node_name", "state", "metadata", "timestamp", "version", "next_nodes", "step", "parent_id", "interrupt", "pending_writes"}).
+		AddRow(cpID, "node-a", stateJSON, metadataJSON, timestamp, 1, nextJSON, 2, "cp-0", interruptJSON, pendingWritesJSON)
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version FROM checkpoints WHERE id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, node_name, state, metadata, timestamp, version, next_nodes, step, parent_id, interrupt, pending_writes FROM checkpoints WHERE id = $1")).
 		WithArgs(cpID).
 		WillReturnRows(rows)
 
@@ -79,6 +97,11 @@ func TestPostgresCheckpointStore_Load(t *testing.T) {
 	assert.Equal(t, cpID, loaded.ID)
 	assert.Equal(t, "node-a", loaded.NodeName)
 	assert.Equal(t, 1, loaded.Version)
+	assert.Equal(t, []string{"node-b"}, loaded.Next)
+	assert.Equal(t, 2, loaded.Step)
+	assert.Equal(t, "cp-0", loaded.ParentID)
+	assert.Equal(t, &graph.PendingInterrupt{Node: "node-b", Value: "approve?"}, loaded.Interrupt)
+	assert.Equal(t, []graph.PendingWrite{{Task: 1, Node: "node-c", Value: "done"}}, loaded.PendingWrites)
 
 	// Check state
 	loadedState, ok := loaded.State.(map[string]interface{})
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/smallnest/langgraphgo/graph"
@@ -151,6 +152,11 @@ func (s *RedisCheckpointStore) List(ctx context.Context, executionID string) ([]
 		}
 	}
 
+	// Set members are unordered; sort by version so latest is last
+	sort.Slice(checkpoints, func(i, j int) bool {
+		return checkpoints[i].Version < checkpoints[j].Version
+	})
+
 	return checkpoints, nil
 }
 
@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -86,3 +87,44 @@ func TestRedisCheckpointStore(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, list, 0)
 }
+
+func TestRedisCheckpointStore_Frontier(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	store := NewRedisCheckpointStore(RedisOptions{
+		Addr: mr.Addr(),
+	})
+	ctx := context.Background()
+
+	for version := 3; version >= 1; version-- {
+		cp := &graph.Checkpoint{
+			ID:        fmt.Sprintf("cp-%d", version),
+			NodeName:  "node-a",
+			State:     "state",
+			Timestamp: time.Now(),
+			Version:   version,
+			Metadata:  map[string]interface{}{"execution_id": "exec-1"},
+			Next:      []string{"node-b"},
+			Step:      version,
+			ParentID:  fmt.Sprintf("cp-%d", version-1),
+			Interrupt: &graph.PendingInterrupt{Node: "node-b", Value: "approve?"},
+		}
+		assert.NoError(t, store.Save(ctx, cp))
+	}
+
+	loaded, err := store.Load(ctx, "cp-2")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"node-b"}, loaded.Next)
+	assert.Equal(t, 2, loaded.Step)
+	assert.Equal(t, "cp-1", loaded.ParentID)
+	assert.Equal(t, &graph.PendingInterrupt{Node: "node-b", Value: "approve?"}, loaded.Interrupt)
+
+	list, err := store.List(ctx, "exec-1")
+	assert.NoError(t, err)
+	assert.Len(t, list, 3)
+	for i, cp := range list {
+		assert.Equal(t, i+1, cp.Version)
+	}
+}
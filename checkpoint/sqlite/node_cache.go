@@ -0,0 +1,138 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+// SqliteNodeCache implements graph.NodeCache using SQLite, so cached node results
+// survive restarts and can be shared between processes.
+//
+// Values are stored as JSON and read back as generic JSON values: structs are
+// returned as map[string]interface{} and numbers as float64. Set CachePolicy.Decode,
+// e.g. to graph.DecodeAs[MyState](), to get the state type of the node back.
+type SqliteNodeCache struct {
+	db        *sql.DB
+	tableName string
+}
+
+var _ graph.NodeCache = (*SqliteNodeCache)(nil)
+
+// SqliteNodeCacheOptions configuration for the SQLite node cache
+type SqliteNodeCacheOptions struct {
+	Path      string
+	TableName string // Default "node_cache"
+}
+
+// NewSqliteNodeCache creates a new SQLite node cache
+func NewSqliteNodeCache(opts SqliteNodeCacheOptions) (*SqliteNodeCache, error) {
+	db, err := sql.Open("sqlite3", opts.Path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open database: %w", err)
+	}
+
+	tableName := opts.TableName
+	if tableName == "" {
+		tableName = "node_cache"
+	}
+
+	cache := &SqliteNodeCache{
+		db:        db,
+		tableName: tableName,
+	}
+
+	if err := cache.InitSchema(context.Background()); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return cache, nil
+}
+
+// InitSchema creates the necessary table if it doesn't exist
+func (c *SqliteNodeCache) InitSchema(ctx context.Context) error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL,
+			expires_at INTEGER NOT NULL DEFAULT 0
+		);
+	`, c.tableName)
+
+	if _, err := c.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database connection
+func (c *SqliteNodeCache) Close() error {
+	return c.db.Close()
+}
+
+// Get implements graph.NodeCache
+func (c *SqliteNodeCache) Get(ctx context.Context, key string) (interface{}, bool, error) {
+	query := fmt.Sprintf("SELECT value, expires_at FROM %s WHERE key = ?", c.tableName)
+
+	var valueJSON string
+	var expiresAt int64
+	err := c.db.QueryRowContext(ctx, query, key).Scan(&valueJSON, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load cached value: %w", err)
+	}
+
+	if expiresAt != 0 && time.Now().UnixNano() > expiresAt {
+		deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE key = ? AND expires_at = ?", c.tableName)
+		if _, err := c.db.ExecContext(ctx, deleteQuery, key, expiresAt); err != nil {
+			return nil, false, fmt.Errorf("failed to delete expired value: %w", err)
+		}
+		return nil, false, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(valueJSON), &value); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal cached value: %w", err)
+	}
+	return value, true, nil
+}
+
+// Set implements graph.NodeCache
+func (c *SqliteNodeCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl).UnixNano()
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (key, value, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at
+	`, c.tableName)
+
+	if _, err := c.db.ExecContext(ctx, query, key, string(valueJSON), expiresAt); err != nil {
+		return fmt.Errorf("failed to save cached value: %w", err)
+	}
+	return nil
+}
+
+// Clear implements graph.NodeCache
+func (c *SqliteNodeCache) Clear(ctx context.Context) error {
+	query := fmt.Sprintf("DELETE FROM %s", c.tableName)
+	if _, err := c.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqliteNodeCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	cache, err := NewSqliteNodeCache(SqliteNodeCacheOptions{Path: path})
+	require.NoError(t, err)
+	defer cache.Close()
+
+	ctx := context.Background()
+
+	_, ok, err := cache.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "k", map[string]interface{}{"answer": 42}, 0))
+	value, ok, err := cache.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"answer": float64(42)}, value)
+
+	// Overwrite with a TTL
+	require.NoError(t, cache.Set(ctx, "k", "short", 10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+	_, ok, err = cache.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "k2", "v", 0))
+	require.NoError(t, cache.Clear(ctx))
+	_, ok, err = cache.Get(ctx, "k2")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSqliteNodeCache_PersistsAcrossGraphs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	calls := 0
+
+	run := func() interface{} {
+		cache, err := NewSqliteNodeCache(SqliteNodeCacheOptions{Path: path})
+		require.NoError(t, err)
+		defer cache.Close()
+
+		g := graph.NewStateGraph()
+		g.AddNode("load", "load", func(ctx context.Context, state interface{}) (interface{}, error) {
+			calls++
+			return state.(string) + " loaded", nil
+		}, graph.WithCache(graph.CachePolicy{Cache: cache}))
+		g.SetEntryPoint("load")
+		g.AddEdge("load", graph.END)
+		runnable, err := g.Compile()
+		require.NoError(t, err)
+
+		res, err := runnable.Invoke(context.Background(), "doc")
+		require.NoError(t, err)
+		return res
+	}
+
+	assert.Equal(t, "doc loaded", run())
+	assert.Equal(t, "doc loaded", run())
+	assert.Equal(t, 1, calls)
+}
+
+func TestSqliteNodeCache_DecodesStructStates(t *testing.T) {
+	type docState struct {
+		Query string
+		Docs  []string
+	}
+
+	cache, err := NewSqliteNodeCache(SqliteNodeCacheOptions{Path: filepath.Join(t.TempDir(), "cache.db")})
+	require.NoError(t, err)
+	defer cache.Close()
+
+	calls := 0
+	g := graph.NewStateGraph()
+	g.AddNode("retrieve", "retrieve", func(ctx context.Context, state interface{}) (interface{}, error) {
+		calls++
+		s := state.(docState)
+		s.Docs = []string{"about " + s.Query}
+		return s, nil
+	}, graph.WithCache(graph.CachePolicy{Cache: cache, Decode: graph.DecodeAs[docState]()}))
+	g.AddNode("answer", "answer", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return state.(docState), nil
+	})
+	g.SetEntryPoint("retrieve")
+	g.AddEdge("retrieve", "answer")
+	g.AddEdge("answer", graph.END)
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		res, err := runnable.Invoke(context.Background(), docState{Query: "AI"})
+		require.NoError(t, err)
+		assert.Equal(t, docState{Query: "AI", Docs: []string{"about AI"}}, res)
+	}
+	assert.Equal(t, 1, calls)
+}
@@ -57,7 +57,12 @@ func (s *SqliteCheckpointStore) InitSchema(ctx context.Context) error {
 			state TEXT NOT NULL,
 			metadata TEXT,
 			timestamp DATETIME NOT NULL,
-			version INTEGER NOT NULL
+			version INTEGER NOT NULL,
+			next_nodes TEXT,
+			step INTEGER NOT NULL DEFAULT 0,
+			parent_id TEXT NOT NULL DEFAULT '',
+			interrupt TEXT,
+			pending_writes TEXT
 		);
 		CREATE INDEX IF NOT EXISTS idx_%s_execution_id ON %s (execution_id);
 	`, s.tableName, s.tableName, s.tableName)
@@ -66,6 +71,54 @@ func (s *SqliteCheckpointStore) InitSchema(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to create schema: %w", err)
 	}
+	return s.migrateSchema(ctx)
+}
+
+// migrateSchema adds the columns introduced after the initial schema to existing tables
+func (s *SqliteCheckpointStore) migrateSchema(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", s.tableName))
+	if err != nil {
+		return fmt.Errorf("failed to inspect schema: %w", err)
+	}
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			ctype      string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &ctype, &notNull, &defaultVal, &pk); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to inspect schema: %w", err)
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to inspect schema: %w", err)
+	}
+
+	columns := []struct{ name, definition string }{
+		{"next_nodes", "TEXT"},
+		{"step", "INTEGER NOT NULL DEFAULT 0"},
+		{"parent_id", "TEXT NOT NULL DEFAULT ''"},
+		{"interrupt", "TEXT"},
+		{"pending_writes", "TEXT"},
+	}
+	for _, column := range columns {
+		if existing[column.name] {
+			continue
+		}
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", s.tableName, column.name, column.definition)
+		if _, err := s.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to add column %s: %w", column.name, err)
+		}
+	}
+
 	return nil
 }
 
@@ -86,21 +139,41 @@ func (s *SqliteCheckpointStore) Save(ctx context.Context, checkpoint *graph.Chec
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
+	nextJSON, err := json.Marshal(checkpoint.Next)
+	if err != nil {
+		return fmt.Errorf("failed to marshal next nodes: %w", err)
+	}
+
+	interruptJSON, err := json.Marshal(checkpoint.Interrupt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal interrupt: %w", err)
+	}
+
+	pendingWritesJSON, err := json.Marshal(checkpoint.PendingWrites)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending writes: %w", err)
+	}
+
 	executionID := ""
 	if id, ok := checkpoint.Metadata["execution_id"].(string); ok {
 		executionID = id
 	}
 
 	query := fmt.Sprintf(`
-		INSERT INTO %s (id, execution_id, node_name, state, metadata, timestamp, version)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO %s (id, execution_id, node_name, state, metadata, timestamp, version, next_nodes, step, parent_id, interrupt, pending_writes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			execution_id = excluded.execution_id,
 			node_name = excluded.node_name,
 			state = excluded.state,
 			metadata = excluded.metadata,
 			timestamp = excluded.timestamp,
-			version = excluded.version
+			version = excluded.version,
+			next_nodes = excluded.next_nodes,
+			step = excluded.step,
+			parent_id = excluded.parent_id,
+			interrupt = excluded.interrupt,
+			pending_writes = excluded.pending_writes
 	`, s.tableName)
 
 	_, err = s.db.ExecContext(ctx, query,
@@ -111,6 +184,11 @@ func (s *SqliteCheckpointStore) Save(ctx context.Context, checkpoint *graph.Chec
 		string(metadataJSON),
 		checkpoint.Timestamp,
 		checkpoint.Version,
+		string(nextJSON),
+		checkpoint.Step,
+		checkpoint.ParentID,
+		string(interruptJSON),
+		string(pendingWritesJSON),
 	)
 
 	if err != nil {
@@ -123,7 +201,7 @@ func (s *SqliteCheckpointStore) Save(ctx context.Context, checkpoint *graph.Chec
 // Load retrieves a checkpoint by ID
 func (s *SqliteCheckpointStore) Load(ctx context.Context, checkpointID string) (*graph.Checkpoint, error) {
 	query := fmt.Sprintf(`
-		SELECT id, node_name, state, metadata, timestamp, version
+		SELECT id, node_name, state, metadata, timestamp, version, next_nodes, step, parent_id, interrupt, pending_writes
 		FROM %s
 		WHERE id = ?
 	`, s.tableName)
@@ -131,6 +209,7 @@ func (s *SqliteCheckpointStore) Load(ctx context.Context, checkpointID string) (
 	var cp graph.Checkpoint
 	var stateJSON string
 	var metadataJSON string
+	var nextJSON, interruptJSON, pendingWritesJSON sql.NullString
 
 	err := s.db.QueryRowContext(ctx, query, checkpointID).Scan(
 		&cp.ID,
@@ -139,6 +218,11 @@ func (s *SqliteCheckpointStore) Load(ctx context.Context, checkpointID string) (
 		&metadataJSON,
 		&cp.Timestamp,
 		&cp.Version,
+		&nextJSON,
+		&cp.Step,
+		&cp.ParentID,
+		&interruptJSON,
+		&pendingWritesJSON,
 	)
 
 	if err != nil {
@@ -158,13 +242,40 @@ func (s *SqliteCheckpointStore) Load(ctx context.Context, checkpointID string) (
 		}
 	}
 
+	if err := unmarshalFrontier(&cp, nextJSON, interruptJSON, pendingWritesJSON); err != nil {
+		return nil, err
+	}
+
 	return &cp, nil
 }
 
+// unmarshalFrontier decodes the next nodes, pending interrupt and pending writes of a checkpoint
+func unmarshalFrontier(cp *graph.Checkpoint, nextJSON, interruptJSON, pendingWritesJSON sql.NullString) error {
+	if nextJSON.Valid && nextJSON.String != "" {
+		if err := json.Unmarshal([]byte(nextJSON.String), &cp.Next); err != nil {
+			return fmt.Errorf("failed to unmarshal next nodes: %w", err)
+		}
+	}
+
+	if interruptJSON.Valid && interruptJSON.String != "" {
+		if err := json.Unmarshal([]byte(interruptJSON.String), &cp.Interrupt); err != nil {
+			return fmt.Errorf("failed to unmarshal interrupt: %w", err)
+		}
+	}
+
+	if pendingWritesJSON.Valid && pendingWritesJSON.String != "" {
+		if err := json.Unmarshal([]byte(pendingWritesJSON.String), &cp.PendingWrites); err != nil {
+			return fmt.Errorf("failed to unmarshal pending writes: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // List returns all checkpoints for a given execution
 func (s *SqliteCheckpointStore) List(ctx context.Context, executionID string) ([]*graph.Checkpoint, error) {
 	query := fmt.Sprintf(`
-		SELECT id, node_name, state, metadata, timestamp, version
+		SELECT id, node_name, state, metadata, timestamp, version, next_nodes, step, parent_id, interrupt, pending_writes
 		FROM %s
 		WHERE execution_id = ?
 		ORDER BY timestamp ASC
@@ -181,6 +292,7 @@ func (s *SqliteCheckpointStore) List(ctx context.Context, executionID string) ([
 		var cp graph.Checkpoint
 		var stateJSON string
 		var metadataJSON string
+		var nextJSON, interruptJSON, pendingWritesJSON sql.NullString
 
 		err := rows.Scan(
 			&cp.ID,
@@ -189,6 +301,11 @@ func (s *SqliteCheckpointStore) List(ctx context.Context, executionID string) ([
 			&metadataJSON,
 			&cp.Timestamp,
 			&cp.Version,
+			&nextJSON,
+			&cp.Step,
+			&cp.ParentID,
+			&interruptJSON,
+			&pendingWritesJSON,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan checkpoint row: %w", err)
@@ -204,6 +321,10 @@ func (s *SqliteCheckpointStore) List(ctx context.Context, executionID string) ([
 			}
 		}
 
+		if err := unmarshalFrontier(&cp, nextJSON, interruptJSON, pendingWritesJSON); err != nil {
+			return nil, err
+		}
+
 		checkpoints = append(checkpoints, &cp)
 	}
 
@@ -2,6 +2,8 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -80,3 +82,92 @@ func TestSqliteCheckpointStore(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, list, 0)
 }
+
+func TestSqliteCheckpointStore_Frontier(t *testing.T) {
+	store, err := NewSqliteCheckpointStore(SqliteOptions{
+		Path: ":memory:",
+	})
+	assert.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	cp := &graph.Checkpoint{
+		ID:        "cp-1",
+		NodeName:  "node-a",
+		State:     "state",
+		Timestamp: time.Now(),
+		Version:   2,
+		Metadata:  map[string]interface{}{"execution_id": "exec-1"},
+		Next:      []string{"node-b", "node-c"},
+		Step:      3,
+		ParentID:  "cp-0",
+		Interrupt: &graph.PendingInterrupt{Node: "node-b", Value: "approve?"},
+		PendingWrites: []graph.PendingWrite{
+			{Task: 1, Node: "node-c", Value: "done"},
+		},
+	}
+	assert.NoError(t, store.Save(ctx, cp))
+
+	loaded, err := store.Load(ctx, "cp-1")
+	assert.NoError(t, err)
+	assert.Equal(t, cp.Next, loaded.Next)
+	assert.Equal(t, cp.Step, loaded.Step)
+	assert.Equal(t, cp.ParentID, loaded.ParentID)
+	assert.Equal(t, cp.Interrupt, loaded.Interrupt)
+	assert.Equal(t, cp.PendingWrites, loaded.PendingWrites)
+
+	list, err := store.List(ctx, "exec-1")
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, cp.Next, list[0].Next)
+	assert.Equal(t, cp.Interrupt, list[0].Interrupt)
+	assert.Equal(t, cp.PendingWrites, list[0].PendingWrites)
+
+	// A checkpoint without a frontier round-trips to empty fields
+	assert.NoError(t, store.Save(ctx, &graph.Checkpoint{ID: "cp-2", Metadata: map[string]interface{}{"execution_id": "exec-1"}}))
+	loaded, err = store.Load(ctx, "cp-2")
+	assert.NoError(t, err)
+	assert.Empty(t, loaded.Next)
+	assert.Nil(t, loaded.Interrupt)
+	assert.Empty(t, loaded.PendingWrites)
+}
+
+func TestSqliteCheckpointStore_MigratesLegacySchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.db")
+
+	db, err := sql.Open("sqlite3", path)
+	assert.NoError(t, err)
+	_, err = db.Exec(`
+		CREATE TABLE checkpoints (
+			id TEXT PRIMARY KEY,
+			execution_id TEXT NOT NULL,
+			node_name TEXT NOT NULL,
+			state TEXT NOT NULL,
+			metadata TEXT,
+			timestamp DATETIME NOT NULL,
+			version INTEGER NOT NULL
+		);
+		INSERT INTO checkpoints VALUES ('old', 'exec-1', 'node-a', '"state"', '{}', '2024-01-01 00:00:00', 1);
+	`)
+	assert.NoError(t, err)
+	db.Close()
+
+	store, err := NewSqliteCheckpointStore(SqliteOptions{Path: path})
+	assert.NoError(t, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	loaded, err := store.Load(ctx, "old")
+	assert.NoError(t, err)
+	assert.Equal(t, "state", loaded.State)
+	assert.Empty(t, loaded.Next)
+
+	assert.NoError(t, store.Save(ctx, &graph.Checkpoint{
+		ID:       "new",
+		Metadata: map[string]interface{}{"execution_id": "exec-1"},
+		Next:     []string{"node-b"},
+	}))
+	loaded, err = store.Load(ctx, "new")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"node-b"}, loaded.Next)
+}
@@ -157,68 +157,21 @@ func main() {
 		fmt.Printf("Found existing checkpoint: %s (Node: %s)\n", latest.ID, latest.NodeName)
 		fmt.Println("Resuming execution...")
 
-		// In a real Durable Execution setup, we would want to resume FROM the next node.
-		// Currently ResumeFromCheckpoint just loads state.
-		// We need to tell the runner to start from the *next* node of the checkpoint.
-		// Or, if the checkpoint was saved *after* a node completed, we continue from its edges.
-
-		// For this demo, we'll use the state to determine where we are,
-		// but ideally the framework handles this.
-		// Since LangGraphGo's ResumeFromCheckpoint is basic, we might need to manually inspect.
-
-		// However, let's try to use the ResumeFrom config if we implemented it.
-		// graph.Config has ResumeFrom []string.
-
-		// If latest checkpoint is from "step_1", we want to resume.
-		// But wait, if we crashed INSIDE step_2, step_2 didn't finish, so no checkpoint for step_2.
-		// So the latest checkpoint is "step_1".
-		// So we should resume from the state of "step_1".
-		// The graph execution logic should see that "step_1" is done and move to "step_2".
-
-		// To achieve this "continue" behavior, we pass the checkpoint ID.
-		// The runner *should* know that if we provide a checkpoint, we are continuing.
-		// But currently Invoke() starts from EntryPoint unless ResumeFrom is set.
-
-		// Let's manually set ResumeFrom based on the checkpoint's node.
-		// If checkpoint is at "step_1", we want to execute the edges out of "step_1".
-		// Which means the next node is "step_2".
-
-		// Actually, if we load the state of "step_1", and we invoke the graph,
-		// we want the graph to figure out "what's next".
-		// But the graph is stateless.
-
-		// Let's use a trick: We pass the checkpoint state as initial state,
-		// and we set ResumeFrom to the *next* nodes.
-		// But we need to know the next nodes.
-
-		// For this simple linear graph:
-		var nextNode string
-		if latest.NodeName == "step_1" {
-			nextNode = "step_2"
-		} else if latest.NodeName == "step_2" {
-			nextNode = "step_3"
-		} else {
-			// Finished or unknown
-			fmt.Println("Job already finished or unknown state.")
+		// The checkpoint records which nodes still have to run, so the
+		// framework continues right after the last completed step.
+		if len(latest.Next) == 0 {
+			fmt.Println("Job already finished.")
 			return
 		}
 
 		config = &graph.Config{
 			Configurable: map[string]interface{}{
-				"thread_id":     threadID,
-				"checkpoint_id": latest.ID,
+				"thread_id": threadID,
 			},
-			ResumeFrom: []string{nextNode},
 		}
 
-		// We use the checkpoint state as input
-		// runnable.Invoke(ctx, latest.State, config)
-		// Wait, Invoke expects input, not full state?
-		// If we provide ResumeFrom, the runner uses that as start nodes.
-		// And it uses the provided state.
-
-		fmt.Printf("Continuing from %s...\n", nextNode)
-		res, err := runnable.InvokeWithConfig(ctx, latest.State, config)
+		fmt.Printf("Continuing from %v...\n", latest.Next)
+		res, err := runnable.ResumeFromCheckpointWithConfig(ctx, latest.ID, config)
 		if err != nil {
 			log.Fatal(err)
 		}
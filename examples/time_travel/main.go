@@ -85,22 +85,11 @@ func main() {
 	fmt.Println("State Updated. New Checkpoint created.")
 
 	// 4. Resume Execution
-	// We resume from the new checkpoint.
-	// Note: We need to clear InterruptBefore to let it proceed, or use ResumeFrom?
-	// If we just Invoke with new config (pointing to new checkpoint), it should continue?
-	// But we need to tell it to start at B?
-	// The checkpoint knows "Next" nodes? Currently our Checkpoint struct doesn't store Next nodes explicitly.
-	// But `Invoke` logic determines next nodes from current.
-	// If we resume, we usually need to specify `ResumeFrom` or rely on saved state.
-	// For now, let's use `ResumeFrom` = "B".
-
-	resumeConfig := &graph.Config{
-		Configurable: newConfig.Configurable, // Use the checkpoint ID from UpdateState
-		ResumeFrom:   []string{"B"},
-	}
-
+	// newConfig points to the checkpoint created by UpdateState. Invoking the thread
+	// without new input loads that checkpoint and continues with the nodes it records
+	// as next, i.e. B.
 	fmt.Println("\n--- Run 2 (Resume) ---")
-	finalRes, err := runnable.InvokeWithConfig(ctx, nil, resumeConfig) // State loaded from checkpoint
+	finalRes, err := runnable.InvokeWithConfig(ctx, nil, newConfig)
 	if err != nil {
 		log.Fatal(err)
 	}
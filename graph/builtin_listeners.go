@@ -8,6 +8,8 @@ import (
 	"os"
 	"sync"
 	"time"
+
+	lglog "github.com/smallnest/langgraphgo/log"
 )
 
 // ProgressListener provides progress tracking with customizable output
@@ -95,6 +97,18 @@ func (pl *ProgressListener) OnNodeEvent(_ context.Context, event NodeEvent, node
 		emoji := "❌"
 		message = fmt.Sprintf("%s %s failed: %v", emoji, nodeName, err)
 
+	case NodeEventRetry:
+		emoji := "🔁"
+		message = fmt.Sprintf("%s %s failed, retrying: %v", emoji, nodeName, err)
+
+	case NodeEventCacheHit:
+		emoji := "💾"
+		message = fmt.Sprintf("%s %s result read from cache", emoji, nodeName)
+
+	case NodeEventCacheMiss:
+		// The node runs and reports its own progress
+		return
+
 	case NodeEventProgress:
 		if hasCustom {
 			message = fmt.Sprintf("%s %s (in progress)", pl.prefix, customStep)
@@ -118,6 +132,7 @@ func (pl *ProgressListener) OnNodeEvent(_ context.Context, event NodeEvent, node
 // LoggingListener provides structured logging for node events
 type LoggingListener struct {
 	logger       *log.Logger
+	structured   lglog.ContextLogger
 	logLevel     LogLevel
 	includeState bool
 }
@@ -150,6 +165,17 @@ func NewLoggingListenerWithLogger(logger *log.Logger) *LoggingListener {
 	}
 }
 
+// NewStructuredLoggingListener creates a logging listener writing structured records,
+// e.g. to a lglog.SlogLogger. The records have the event, node and error as fields,
+// and the run ID, thread ID and step of the run from the context.
+func NewStructuredLoggingListener(logger lglog.ContextLogger) *LoggingListener {
+	return &LoggingListener{
+		structured:   logger,
+		logLevel:     LogLevelInfo,
+		includeState: false,
+	}
+}
+
 // WithLogLevel sets the minimum log level
 func (ll *LoggingListener) WithLogLevel(level LogLevel) *LoggingListener {
 	ll.logLevel = level
@@ -163,7 +189,7 @@ func (ll *LoggingListener) WithState(enabled bool) *LoggingListener {
 }
 
 // OnNodeEvent implements the NodeListener interface
-func (ll *LoggingListener) OnNodeEvent(_ context.Context, event NodeEvent, nodeName string, state interface{}, err error) {
+func (ll *LoggingListener) OnNodeEvent(ctx context.Context, event NodeEvent, nodeName string, state interface{}, err error) {
 	var level LogLevel
 	var prefix string
 
@@ -180,12 +206,26 @@ func (ll *LoggingListener) OnNodeEvent(_ context.Context, event NodeEvent, nodeN
 	case NodeEventError:
 		level = LogLevelError
 		prefix = "ERROR"
+	case NodeEventRetry:
+		level = LogLevelWarn
+		prefix = "RETRY"
+	case NodeEventCacheHit:
+		level = LogLevelDebug
+		prefix = "CACHE_HIT"
+	case NodeEventCacheMiss:
+		level = LogLevelDebug
+		prefix = "CACHE_MISS"
 	}
 
 	if level < ll.logLevel {
 		return
 	}
 
+	if ll.structured != nil {
+		ll.logRecord(ctx, level, event, nodeName, state, err)
+		return
+	}
+
 	message := fmt.Sprintf("%s %s", prefix, nodeName)
 
 	if err != nil {
@@ -199,12 +239,37 @@ func (ll *LoggingListener) OnNodeEvent(_ context.Context, event NodeEvent, nodeN
 	ll.logger.Println(message)
 }
 
+// logRecord writes a node event as a structured record
+func (ll *LoggingListener) logRecord(ctx context.Context, level LogLevel, event NodeEvent, nodeName string, state interface{}, err error) {
+	msg := "node " + string(event)
+	args := []any{"event", string(event), "node", nodeName}
+	if err != nil {
+		args = append(args, "error", err)
+	}
+	if ll.includeState && state != nil {
+		args = append(args, "state", state)
+	}
+
+	switch level {
+	case LogLevelDebug:
+		ll.structured.DebugContext(ctx, msg, args...)
+	case LogLevelWarn:
+		ll.structured.WarnContext(ctx, msg, args...)
+	case LogLevelError:
+		ll.structured.ErrorContext(ctx, msg, args...)
+	default:
+		ll.structured.InfoContext(ctx, msg, args...)
+	}
+}
+
 // MetricsListener collects performance and execution metrics
 type MetricsListener struct {
 	mutex           sync.RWMutex
 	nodeExecutions  map[string]int
 	nodeDurations   map[string][]time.Duration
 	nodeErrors      map[string]int
+	cacheHits       map[string]int
+	cacheMisses     map[string]int
 	totalExecutions int
 	startTimes      map[string]time.Time
 }
@@ -215,6 +280,8 @@ func NewMetricsListener() *MetricsListener {
 		nodeExecutions: make(map[string]int),
 		nodeDurations:  make(map[string][]time.Duration),
 		nodeErrors:     make(map[string]int),
+		cacheHits:      make(map[string]int),
+		cacheMisses:    make(map[string]int),
 		startTimes:     make(map[string]time.Time),
 	}
 }
@@ -244,6 +311,10 @@ func (ml *MetricsListener) OnNodeEvent(_ context.Context, event NodeEvent, nodeN
 			ml.nodeDurations[nodeName] = append(ml.nodeDurations[nodeName], duration)
 			delete(ml.startTimes, nodeName)
 		}
+	case NodeEventCacheHit:
+		ml.cacheHits[nodeName]++
+	case NodeEventCacheMiss:
+		ml.cacheMisses[nodeName]++
 	case NodeEventProgress:
 		// Progress events are tracked but don't affect timing metrics
 	}
@@ -273,6 +344,30 @@ func (ml *MetricsListener) GetNodeErrors() map[string]int {
 	return result
 }
 
+// GetCacheHits returns the number of results read from the cache for each node
+func (ml *MetricsListener) GetCacheHits() map[string]int {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+
+	result := make(map[string]int)
+	for k, v := range ml.cacheHits {
+		result[k] = v
+	}
+	return result
+}
+
+// GetCacheMisses returns the number of results missing from the cache for each node
+func (ml *MetricsListener) GetCacheMisses() map[string]int {
+	ml.mutex.RLock()
+	defer ml.mutex.RUnlock()
+
+	result := make(map[string]int)
+	for k, v := range ml.cacheMisses {
+		result[k] = v
+	}
+	return result
+}
+
 // GetNodeAverageDuration returns the average duration for each node
 func (ml *MetricsListener) GetNodeAverageDuration() map[string]time.Duration {
 	ml.mutex.RLock()
@@ -336,6 +431,17 @@ func (ml *MetricsListener) PrintSummary(writer io.Writer) {
 			fmt.Fprintf(writer, "  %s: %d errors\n", nodeName, count)
 		}
 	}
+
+	if len(ml.cacheHits) > 0 || len(ml.cacheMisses) > 0 {
+		fmt.Fprintln(writer)
+		fmt.Fprintln(writer, "Cache:")
+		for _, nodeName := range sortedKeys(ml.cacheHits) {
+			fmt.Fprintf(writer, "  %s: %d hits\n", nodeName, ml.cacheHits[nodeName])
+		}
+		for _, nodeName := range sortedKeys(ml.cacheMisses) {
+			fmt.Fprintf(writer, "  %s: %d misses\n", nodeName, ml.cacheMisses[nodeName])
+		}
+	}
 }
 
 // Reset clears all collected metrics
@@ -346,6 +452,8 @@ func (ml *MetricsListener) Reset() {
 	ml.nodeExecutions = make(map[string]int)
 	ml.nodeDurations = make(map[string][]time.Duration)
 	ml.nodeErrors = make(map[string]int)
+	ml.cacheHits = make(map[string]int)
+	ml.cacheMisses = make(map[string]int)
 	ml.startTimes = make(map[string]time.Time)
 	ml.totalExecutions = 0
 }
@@ -415,6 +523,15 @@ func (cl *ChatListener) OnNodeEvent(_ context.Context, event NodeEvent, nodeName
 	case NodeEventError:
 		message = fmt.Sprintf("❌ Error in %s: %v", nodeName, err)
 
+	case NodeEventRetry:
+		message = fmt.Sprintf("🔁 Retrying %s after error: %v", nodeName, err)
+
+	case NodeEventCacheHit:
+		message = fmt.Sprintf("💾 %s answered from cache", nodeName)
+
+	case NodeEventCacheMiss:
+		return
+
 	case NodeEventProgress:
 		if hasCustom {
 			message = fmt.Sprintf("⏳ %s...", customMessage)
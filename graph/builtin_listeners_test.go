@@ -3,6 +3,7 @@ package graph_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/smallnest/langgraphgo/graph"
+	lglog "github.com/smallnest/langgraphgo/log"
 )
 
 const (
@@ -123,6 +125,59 @@ func TestLoggingListener_OnNodeEvent(t *testing.T) {
 	}
 }
 
+func TestStructuredLoggingListener(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	listener := graph.NewStructuredLoggingListener(lglog.NewJSONLogger(&buf, lglog.LogLevelDebug)).
+		WithLogLevel(graph.LogLevelInfo)
+
+	g := graph.NewListenableStateGraph()
+	g.AddNode("greet", "greet", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return "hello", nil
+	})
+	g.AddNode("fail", "fail", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("test error")
+	})
+	g.AddEdge("greet", "fail")
+	g.AddEdge("fail", graph.END)
+	g.SetEntryPoint("greet")
+	g.AddGlobalListener(listener)
+
+	runnable, err := g.CompileListenable()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	config := &graph.Config{Configurable: map[string]interface{}{"thread_id": "thread-1"}}
+	if _, err := runnable.InvokeWithConfig(context.Background(), "hi", config); err == nil {
+		t.Fatal("Expected the run to fail")
+	}
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Expected a JSON record, got %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 4 {
+		t.Fatalf("Expected 4 records, got %d: %s", len(records), buf.String())
+	}
+
+	// The records are enriched with the run, thread and step
+	for _, record := range records {
+		if record["run_id"] == "" || record["run_id"] == nil || record["thread_id"] != "thread-1" {
+			t.Errorf("Expected run and thread fields, got %v", record)
+		}
+	}
+	last := records[3]
+	if last["level"] != "ERROR" || last["msg"] != "node error" || last["node"] != "fail" ||
+		last["error"] != "test error" || last["step"] != float64(2) {
+		t.Errorf("Unexpected error record: %v", last)
+	}
+}
+
 func TestLoggingListener_LogLevel(t *testing.T) {
 	t.Parallel()
 
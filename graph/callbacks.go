@@ -54,9 +54,14 @@ type Config struct {
 	// RunName for this execution
 	RunName string `json:"run_name"`
 
-	// Timeout for the execution
+	// Timeout for the whole execution. When the deadline is reached, running nodes
+	// are cancelled through their context and a GraphTimeoutError is returned.
 	Timeout *time.Duration `json:"timeout"`
 
+	// RecursionLimit is the maximum number of super-steps the graph may execute.
+	// Zero means DefaultRecursionLimit.
+	RecursionLimit int `json:"recursion_limit"`
+
 	// InterruptBefore nodes to stop before execution
 	InterruptBefore []string `json:"interrupt_before"`
 
@@ -0,0 +1,202 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errStoreUnavailable = errors.New("store unavailable")
+
+// slowStore delays saves and fails them while failing is set
+type slowStore struct {
+	*graph.MemoryCheckpointStore
+	delay time.Duration
+
+	mu      sync.Mutex
+	failing bool
+	saves   int
+}
+
+func (s *slowStore) Save(ctx context.Context, checkpoint *graph.Checkpoint) error {
+	time.Sleep(s.delay)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failing {
+		return errStoreUnavailable
+	}
+	s.saves++
+	return s.MemoryCheckpointStore.Save(ctx, checkpoint)
+}
+
+func (s *slowStore) saved() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saves
+}
+
+// newDurableRunnable builds a -> b -> c, appending each node name to a string state
+// after calling hook
+func newDurableRunnable(t *testing.T, config graph.CheckpointConfig, hook func(ctx context.Context, node string) error) *graph.CheckpointableRunnable {
+	g := graph.NewCheckpointableStateGraphWithConfig(config)
+	nodes := []string{"a", "b", "c"}
+	for i, name := range nodes {
+		name := name
+		g.AddNode(name, name, func(ctx context.Context, state interface{}) (interface{}, error) {
+			if hook != nil {
+				if err := hook(ctx, name); err != nil {
+					return nil, err
+				}
+			}
+			return state.(string) + name, nil
+		})
+		if i > 0 {
+			g.AddEdge(nodes[i-1], name)
+		}
+	}
+	g.SetEntryPoint("a")
+	g.AddEdge("c", graph.END)
+
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+	return runnable
+}
+
+func TestDurability_SyncStopsOnSaveError(t *testing.T) {
+	store := &slowStore{MemoryCheckpointStore: graph.NewMemoryCheckpointStore(), failing: true}
+	var ran []string
+	runnable := newDurableRunnable(t, graph.CheckpointConfig{Store: store, AutoSave: true, Durability: graph.DurabilitySync},
+		func(ctx context.Context, node string) error {
+			ran = append(ran, node)
+			return nil
+		})
+
+	_, err := runnable.Invoke(context.Background(), "")
+	require.ErrorIs(t, err, errStoreUnavailable)
+	assert.Equal(t, []string{"a"}, ran)
+}
+
+func TestDurability_AsyncFlushesOnExit(t *testing.T) {
+	store := &slowStore{MemoryCheckpointStore: graph.NewMemoryCheckpointStore(), delay: 5 * time.Millisecond}
+	runnable := newDurableRunnable(t, graph.CheckpointConfig{Store: store, AutoSave: true, Durability: graph.DurabilityAsync, AsyncQueueSize: 1}, nil)
+
+	res, err := runnable.Invoke(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", res)
+
+	// All the checkpoints are saved, in order, once the run returns
+	checkpoints, err := runnable.ListCheckpoints(context.Background())
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 3)
+	for i, cp := range checkpoints {
+		assert.Equal(t, i+1, cp.Version)
+		assert.Equal(t, i+1, cp.Step)
+		if i > 0 {
+			assert.Equal(t, checkpoints[i-1].ID, cp.ParentID)
+		}
+	}
+	assert.Empty(t, checkpoints[2].Next)
+}
+
+func TestDurability_AsyncSurfacesSaveErrors(t *testing.T) {
+	store := &slowStore{MemoryCheckpointStore: graph.NewMemoryCheckpointStore(), failing: true}
+	var ran []string
+	runnable := newDurableRunnable(t, graph.CheckpointConfig{Store: store, AutoSave: true, Durability: graph.DurabilityAsync},
+		func(ctx context.Context, node string) error {
+			ran = append(ran, node)
+			return nil
+		})
+
+	_, err := runnable.Invoke(context.Background(), "")
+	require.ErrorIs(t, err, errStoreUnavailable)
+	assert.Equal(t, []string{"a", "b", "c"}, ran)
+}
+
+func TestDurability_Flush(t *testing.T) {
+	store := &slowStore{MemoryCheckpointStore: graph.NewMemoryCheckpointStore(), delay: 5 * time.Millisecond}
+	var runnable *graph.CheckpointableRunnable
+	runnable = newDurableRunnable(t, graph.CheckpointConfig{Store: store, AutoSave: true, Durability: graph.DurabilityAsync},
+		func(ctx context.Context, node string) error {
+			if node != "c" {
+				return nil
+			}
+			// The checkpoints of a and b are saved once flushed
+			if err := runnable.Flush(ctx); err != nil {
+				return err
+			}
+			assert.Equal(t, 2, store.saved())
+			return nil
+		})
+
+	_, err := runnable.Invoke(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, 3, store.saved())
+
+	// Nothing is queued once the run has returned
+	assert.NoError(t, runnable.Flush(context.Background()))
+}
+
+func TestDurability_ExitSavesOnlyLastStep(t *testing.T) {
+	t.Run("Completed", func(t *testing.T) {
+		store := graph.NewMemoryCheckpointStore()
+		runnable := newDurableRunnable(t, graph.CheckpointConfig{Store: store, AutoSave: true, Durability: graph.DurabilityExit}, nil)
+
+		_, err := runnable.Invoke(context.Background(), "")
+		require.NoError(t, err)
+
+		checkpoints, err := runnable.ListCheckpoints(context.Background())
+		require.NoError(t, err)
+		require.Len(t, checkpoints, 1)
+		assert.Equal(t, "c", checkpoints[0].NodeName)
+		assert.Equal(t, 3, checkpoints[0].Step)
+		assert.Empty(t, checkpoints[0].Next)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		store := graph.NewMemoryCheckpointStore()
+		failures := 1
+		runnable := newDurableRunnable(t, graph.CheckpointConfig{Store: store, AutoSave: true, Durability: graph.DurabilityExit},
+			func(ctx context.Context, node string) error {
+				if node == "c" && failures > 0 {
+					failures--
+					return errors.New("boom")
+				}
+				return nil
+			})
+
+		_, err := runnable.Invoke(context.Background(), "")
+		require.Error(t, err)
+
+		// The run can be resumed from the last step that completed
+		checkpoints, err := runnable.ListCheckpoints(context.Background())
+		require.NoError(t, err)
+		require.Len(t, checkpoints, 1)
+		assert.Equal(t, "b", checkpoints[0].NodeName)
+		assert.Equal(t, []string{"c"}, checkpoints[0].Next)
+
+		res, err := runnable.ResumeFromCheckpoint(context.Background(), checkpoints[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "abc", res)
+	})
+
+	t.Run("Interrupted", func(t *testing.T) {
+		store := graph.NewMemoryCheckpointStore()
+		runnable := newDurableRunnable(t, graph.CheckpointConfig{Store: store, AutoSave: true, Durability: graph.DurabilityExit}, nil)
+
+		_, err := runnable.InvokeWithConfig(context.Background(), "", &graph.Config{InterruptBefore: []string{"c"}})
+		var interrupt *graph.GraphInterrupt
+		require.ErrorAs(t, err, &interrupt)
+
+		checkpoints, err := runnable.ListCheckpoints(context.Background())
+		require.NoError(t, err)
+		require.Len(t, checkpoints, 1)
+		assert.Equal(t, []string{"c"}, checkpoints[0].Next)
+		assert.NotNil(t, checkpoints[0].Interrupt)
+	})
+}
@@ -0,0 +1,231 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newChainRunnable builds A -> B -> C, appending each node name to a string state
+func newChainRunnable(t *testing.T, runs map[string]int, failB *bool) *graph.CheckpointableRunnable {
+	t.Helper()
+
+	g := graph.NewCheckpointableStateGraph()
+	for _, name := range []string{"A", "B", "C"} {
+		name := name
+		g.AddNode(name, name, func(ctx context.Context, state interface{}) (interface{}, error) {
+			runs[name]++
+			if name == "B" && failB != nil && *failB {
+				return nil, errors.New("crash")
+			}
+			return state.(string) + name, nil
+		})
+	}
+	g.SetEntryPoint("A")
+	g.AddEdge("A", "B")
+	g.AddEdge("B", "C")
+	g.AddEdge("C", graph.END)
+
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+	return runnable
+}
+
+func TestCheckpoint_RecordsFrontier(t *testing.T) {
+	runs := map[string]int{}
+	runnable := newChainRunnable(t, runs, nil)
+	ctx := context.Background()
+
+	res, err := runnable.Invoke(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, "ABC", res)
+
+	checkpoints, err := runnable.ListCheckpoints(ctx)
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 3)
+
+	assert.Equal(t, []string{"B"}, checkpoints[0].Next)
+	assert.Equal(t, []string{"C"}, checkpoints[1].Next)
+	assert.Empty(t, checkpoints[2].Next)
+
+	for i, cp := range checkpoints {
+		assert.Equal(t, i+1, cp.Step)
+		assert.Nil(t, cp.Interrupt)
+		if i == 0 {
+			assert.Empty(t, cp.ParentID)
+		} else {
+			assert.Equal(t, checkpoints[i-1].ID, cp.ParentID)
+		}
+	}
+
+	snapshot, err := runnable.GetState(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ABC", snapshot.Values)
+	assert.Empty(t, snapshot.Next)
+	assert.Equal(t, checkpoints[1].ID, snapshot.ParentID)
+}
+
+func TestResumeFromCheckpoint_ContinuesExecution(t *testing.T) {
+	runs := map[string]int{}
+	runnable := newChainRunnable(t, runs, nil)
+	ctx := context.Background()
+
+	_, err := runnable.Invoke(ctx, "")
+	require.NoError(t, err)
+
+	checkpoints, err := runnable.ListCheckpoints(ctx)
+	require.NoError(t, err)
+	first := checkpoints[0]
+
+	res, err := runnable.ResumeFromCheckpoint(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ABC", res)
+	assert.Equal(t, map[string]int{"A": 1, "B": 2, "C": 2}, runs)
+
+	// The resumed run is chained onto the checkpoint it started from
+	checkpoints, err = runnable.ListCheckpoints(ctx)
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 5)
+	assert.Equal(t, first.ID, checkpoints[3].ParentID)
+	assert.Equal(t, 2, checkpoints[3].Step)
+	assert.Equal(t, 3, checkpoints[4].Step)
+
+	// Resuming a finished run has nothing left to execute
+	res, err = runnable.ResumeFromCheckpoint(ctx, checkpoints[4].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ABC", res)
+	assert.Equal(t, 2, runs["C"])
+}
+
+func TestResumeFromCheckpoint_AfterFailure(t *testing.T) {
+	runs := map[string]int{}
+	failB := true
+	runnable := newChainRunnable(t, runs, &failB)
+	ctx := context.Background()
+
+	_, err := runnable.Invoke(ctx, "")
+	require.Error(t, err)
+
+	snapshot, err := runnable.GetState(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "A", snapshot.Values)
+	assert.Equal(t, []string{"B"}, snapshot.Next)
+
+	failB = false
+	res, err := runnable.ResumeFromCheckpoint(ctx, snapshot.Config.Configurable["checkpoint_id"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, "ABC", res)
+	assert.Equal(t, 1, runs["A"])
+}
+
+func TestResumeFromCheckpoint_Interrupts(t *testing.T) {
+	t.Run("InterruptBefore", func(t *testing.T) {
+		runs := map[string]int{}
+		runnable := newChainRunnable(t, runs, nil)
+		ctx := context.Background()
+
+		_, err := runnable.InvokeWithConfig(ctx, "", &graph.Config{InterruptBefore: []string{"C"}})
+		var interrupt *graph.GraphInterrupt
+		require.ErrorAs(t, err, &interrupt)
+
+		checkpoints, err := runnable.ListCheckpoints(ctx)
+		require.NoError(t, err)
+		latest := checkpoints[len(checkpoints)-1]
+		assert.Equal(t, []string{"C"}, latest.Next)
+		assert.Equal(t, 2, latest.Step)
+		require.NotNil(t, latest.Interrupt)
+		assert.Equal(t, "C", latest.Interrupt.Node)
+
+		snapshot, err := runnable.GetState(ctx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"C"}, snapshot.Next)
+
+		res, err := runnable.ResumeFromCheckpoint(ctx, latest.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "ABC", res)
+		assert.Equal(t, 1, runs["B"])
+	})
+
+	t.Run("InterruptAfter", func(t *testing.T) {
+		runs := map[string]int{}
+		runnable := newChainRunnable(t, runs, nil)
+		ctx := context.Background()
+
+		_, err := runnable.InvokeWithConfig(ctx, "", &graph.Config{InterruptAfter: []string{"A"}})
+		var interrupt *graph.GraphInterrupt
+		require.ErrorAs(t, err, &interrupt)
+
+		snapshot, err := runnable.GetState(ctx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "A", snapshot.Values)
+		assert.Equal(t, []string{"B"}, snapshot.Next)
+
+		res, err := runnable.ResumeFromCheckpoint(ctx, snapshot.Config.Configurable["checkpoint_id"].(string))
+		require.NoError(t, err)
+		assert.Equal(t, "ABC", res)
+		assert.Equal(t, 1, runs["A"])
+	})
+
+	t.Run("NodeInterrupt", func(t *testing.T) {
+		g := graph.NewCheckpointableStateGraph()
+		g.AddNode("ask", "ask", func(ctx context.Context, state interface{}) (interface{}, error) {
+			answer, err := graph.Interrupt(ctx, "name?")
+			if err != nil {
+				return nil, err
+			}
+			return state.(string) + answer.(string), nil
+		})
+		g.SetEntryPoint("ask")
+		g.AddEdge("ask", graph.END)
+
+		runnable, err := g.CompileCheckpointable()
+		require.NoError(t, err)
+		ctx := context.Background()
+
+		_, err = runnable.Invoke(ctx, "hello ")
+		var interrupt *graph.GraphInterrupt
+		require.ErrorAs(t, err, &interrupt)
+
+		checkpoints, err := runnable.ListCheckpoints(ctx)
+		require.NoError(t, err)
+		require.Len(t, checkpoints, 1)
+		pending := checkpoints[0]
+		assert.Equal(t, []string{"ask"}, pending.Next)
+		assert.Equal(t, 0, pending.Step)
+		require.NotNil(t, pending.Interrupt)
+		assert.Equal(t, "name?", pending.Interrupt.Value)
+
+		res, err := runnable.ResumeFromCheckpointWithConfig(ctx, pending.ID, &graph.Config{ResumeValue: "world"})
+		require.NoError(t, err)
+		assert.Equal(t, "hello world", res)
+	})
+}
+
+func TestUpdateState_KeepsFrontier(t *testing.T) {
+	runs := map[string]int{}
+	runnable := newChainRunnable(t, runs, nil)
+	ctx := context.Background()
+
+	_, err := runnable.InvokeWithConfig(ctx, "", &graph.Config{InterruptBefore: []string{"C"}})
+	require.Error(t, err)
+
+	before, err := runnable.GetState(ctx, nil)
+	require.NoError(t, err)
+
+	updated, err := runnable.UpdateState(ctx, nil, "AB-edited-", "B")
+	require.NoError(t, err)
+
+	snapshot, err := runnable.GetState(ctx, updated)
+	require.NoError(t, err)
+	assert.Equal(t, "AB-edited-", snapshot.Values)
+	assert.Equal(t, []string{"C"}, snapshot.Next)
+	assert.Equal(t, before.Config.Configurable["checkpoint_id"], snapshot.ParentID)
+
+	res, err := runnable.ResumeFromCheckpoint(ctx, updated.Configurable["checkpoint_id"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, "AB-edited-C", res)
+}
@@ -0,0 +1,374 @@
+package graph_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCheckpointStoreConformance checks the behavior every CheckpointStore must share
+func testCheckpointStoreConformance(t *testing.T, newStore func(t *testing.T) graph.CheckpointStore) {
+	ctx := context.Background()
+
+	newCheckpoint := func(id, execID string, version int) *graph.Checkpoint {
+		return &graph.Checkpoint{
+			ID:        id,
+			NodeName:  "node",
+			State:     map[string]interface{}{"id": id},
+			Timestamp: time.Now().UTC().Truncate(time.Millisecond),
+			Version:   version,
+			Metadata:  map[string]interface{}{"execution_id": execID},
+		}
+	}
+
+	t.Run("SaveAndLoad", func(t *testing.T) {
+		store := newStore(t)
+		checkpoint := newCheckpoint("cp-1", "exec-1", 1)
+		checkpoint.Next = []string{"b", "c"}
+		checkpoint.Step = 2
+		checkpoint.ParentID = "cp-0"
+		checkpoint.Interrupt = &graph.PendingInterrupt{Node: "b", Value: "approve?"}
+		checkpoint.PendingWrites = []graph.PendingWrite{{Task: 1, Node: "c", Value: "done"}}
+		require.NoError(t, store.Save(ctx, checkpoint))
+
+		loaded, err := store.Load(ctx, "cp-1")
+		require.NoError(t, err)
+		assert.Equal(t, checkpoint.ID, loaded.ID)
+		assert.Equal(t, checkpoint.NodeName, loaded.NodeName)
+		assert.Equal(t, checkpoint.State, loaded.State)
+		assert.Equal(t, checkpoint.Metadata, loaded.Metadata)
+		assert.True(t, checkpoint.Timestamp.Equal(loaded.Timestamp))
+		assert.Equal(t, checkpoint.Version, loaded.Version)
+		assert.Equal(t, checkpoint.Next, loaded.Next)
+		assert.Equal(t, checkpoint.Step, loaded.Step)
+		assert.Equal(t, checkpoint.ParentID, loaded.ParentID)
+		assert.Equal(t, checkpoint.Interrupt, loaded.Interrupt)
+		assert.Equal(t, checkpoint.PendingWrites, loaded.PendingWrites)
+	})
+
+	t.Run("LoadMissing", func(t *testing.T) {
+		store := newStore(t)
+		_, err := store.Load(ctx, "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checkpoint not found")
+	})
+
+	t.Run("SaveOverwrites", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.Save(ctx, newCheckpoint("cp-1", "exec-1", 1)))
+
+		updated := newCheckpoint("cp-1", "exec-1", 1)
+		updated.NodeName = "updated"
+		require.NoError(t, store.Save(ctx, updated))
+
+		loaded, err := store.Load(ctx, "cp-1")
+		require.NoError(t, err)
+		assert.Equal(t, "updated", loaded.NodeName)
+
+		listed, err := store.List(ctx, "exec-1")
+		require.NoError(t, err)
+		assert.Len(t, listed, 1)
+	})
+
+	t.Run("ListOrdersByVersion", func(t *testing.T) {
+		store := newStore(t)
+		for _, version := range []int{3, 1, 2} {
+			require.NoError(t, store.Save(ctx, newCheckpoint(fmt.Sprintf("cp-%d", version), "exec-1", version)))
+		}
+		require.NoError(t, store.Save(ctx, newCheckpoint("other", "exec-2", 1)))
+
+		listed, err := store.List(ctx, "exec-1")
+		require.NoError(t, err)
+		require.Len(t, listed, 3)
+		for i, checkpoint := range listed {
+			assert.Equal(t, i+1, checkpoint.Version)
+		}
+	})
+
+	t.Run("ListUnknownExecution", func(t *testing.T) {
+		store := newStore(t)
+		listed, err := store.List(ctx, "unknown")
+		require.NoError(t, err)
+		assert.Empty(t, listed)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.Save(ctx, newCheckpoint("cp-1", "exec-1", 1)))
+		require.NoError(t, store.Save(ctx, newCheckpoint("cp-2", "exec-1", 2)))
+
+		require.NoError(t, store.Delete(ctx, "cp-1"))
+		_, err := store.Load(ctx, "cp-1")
+		assert.Error(t, err)
+
+		listed, err := store.List(ctx, "exec-1")
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, "cp-2", listed[0].ID)
+
+		// Deleting a missing checkpoint is not an error
+		assert.NoError(t, store.Delete(ctx, "missing"))
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.Save(ctx, newCheckpoint("cp-1", "exec-1", 1)))
+		require.NoError(t, store.Save(ctx, newCheckpoint("cp-2", "exec-1", 2)))
+		require.NoError(t, store.Save(ctx, newCheckpoint("cp-3", "exec-2", 1)))
+
+		require.NoError(t, store.Clear(ctx, "exec-1"))
+
+		listed, err := store.List(ctx, "exec-1")
+		require.NoError(t, err)
+		assert.Empty(t, listed)
+		_, err = store.Load(ctx, "cp-1")
+		assert.Error(t, err)
+
+		listed, err = store.List(ctx, "exec-2")
+		require.NoError(t, err)
+		assert.Len(t, listed, 1)
+	})
+
+	t.Run("ConcurrentSaves", func(t *testing.T) {
+		store := newStore(t)
+		var wg sync.WaitGroup
+		for i := 1; i <= 20; i++ {
+			wg.Add(1)
+			go func(version int) {
+				defer wg.Done()
+				assert.NoError(t, store.Save(ctx, newCheckpoint(fmt.Sprintf("cp-%d", version), "exec-1", version)))
+			}(i)
+		}
+		wg.Wait()
+
+		listed, err := store.List(ctx, "exec-1")
+		require.NoError(t, err)
+		assert.Len(t, listed, 20)
+	})
+}
+
+func TestMemoryCheckpointStore_Conformance(t *testing.T) {
+	testCheckpointStoreConformance(t, func(t *testing.T) graph.CheckpointStore {
+		return graph.NewMemoryCheckpointStore()
+	})
+}
+
+func TestFileCheckpointStore_Conformance(t *testing.T) {
+	testCheckpointStoreConformance(t, func(t *testing.T) graph.CheckpointStore {
+		store, err := graph.NewFileCheckpointStore(t.TempDir())
+		require.NoError(t, err)
+		return store
+	})
+}
+
+func TestFileCheckpointStore_Durability(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	save := func(store *graph.FileCheckpointStore, id, execID string, version int) {
+		require.NoError(t, store.Save(ctx, &graph.Checkpoint{
+			ID:       id,
+			State:    id,
+			Version:  version,
+			Metadata: map[string]interface{}{"execution_id": execID},
+		}))
+	}
+
+	t.Run("ReopenedStoreSeesCheckpoints", func(t *testing.T) {
+		store, err := graph.NewFileCheckpointStore(dir)
+		require.NoError(t, err)
+		save(store, "cp-1", "thread/1", 1)
+		save(store, "cp-2", "thread/1", 2)
+
+		reopened, err := graph.NewFileCheckpointStore(dir)
+		require.NoError(t, err)
+		listed, err := reopened.List(ctx, "thread/1")
+		require.NoError(t, err)
+		require.Len(t, listed, 2)
+		assert.Equal(t, "cp-2", listed[1].ID)
+	})
+
+	t.Run("OneDirectoryPerExecution", func(t *testing.T) {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+
+		var dirs []string
+		for _, entry := range entries {
+			if entry.IsDir() && strings.HasPrefix(entry.Name(), "exec-") {
+				dirs = append(dirs, entry.Name())
+			}
+		}
+		require.Len(t, dirs, 1)
+
+		files, err := os.ReadDir(filepath.Join(dir, dirs[0]))
+		require.NoError(t, err)
+		for _, file := range files {
+			assert.False(t, strings.HasPrefix(file.Name(), ".tmp-"), "temporary file left behind: %s", file.Name())
+		}
+	})
+
+	t.Run("IndexRebuiltWhenMissing", func(t *testing.T) {
+		matches, err := filepath.Glob(filepath.Join(dir, "exec-*", "index"))
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		require.NoError(t, os.Remove(matches[0]))
+
+		store, err := graph.NewFileCheckpointStore(dir)
+		require.NoError(t, err)
+		listed, err := store.List(ctx, "thread/1")
+		require.NoError(t, err)
+		require.Len(t, listed, 2)
+		assert.Equal(t, "cp-1", listed[0].ID)
+
+		save(store, "cp-3", "thread/1", 3)
+		listed, err = store.List(ctx, "thread/1")
+		require.NoError(t, err)
+		assert.Len(t, listed, 3)
+	})
+
+	t.Run("SeparateStoresShareDirectory", func(t *testing.T) {
+		// Separate instances do not share the in-process mutex, so only the lock file serializes them
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				store, err := graph.NewFileCheckpointStore(dir)
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.NoError(t, store.Save(ctx, &graph.Checkpoint{
+					ID:       fmt.Sprintf("shared-%d", i),
+					Version:  i,
+					Metadata: map[string]interface{}{"execution_id": "shared"},
+				}))
+			}(i)
+		}
+		wg.Wait()
+
+		store, err := graph.NewFileCheckpointStore(dir)
+		require.NoError(t, err)
+		listed, err := store.List(ctx, "shared")
+		require.NoError(t, err)
+		assert.Len(t, listed, 10)
+	})
+}
+
+func TestFileCheckpointStore_IDIndex(t *testing.T) {
+	ctx := context.Background()
+
+	save := func(store *graph.FileCheckpointStore, id, execID string) {
+		require.NoError(t, store.Save(ctx, &graph.Checkpoint{
+			ID:       id,
+			State:    id,
+			Metadata: map[string]interface{}{"execution_id": execID},
+		}))
+	}
+
+	t.Run("LoadAndDeleteDoNotScanExecutions", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := graph.NewFileCheckpointStore(dir)
+		require.NoError(t, err)
+		save(store, "cp-1", "thread-1")
+
+		// An execution holding a copy of the checkpoint is never looked at
+		stray := filepath.Join(dir, "exec-stray")
+		require.NoError(t, os.MkdirAll(stray, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(stray, "cp-1.json"), []byte("not json"), 0o644))
+
+		loaded, err := store.Load(ctx, "cp-1")
+		require.NoError(t, err)
+		assert.Equal(t, "cp-1", loaded.State)
+
+		require.NoError(t, store.Delete(ctx, "cp-1"))
+		_, err = store.Load(ctx, "cp-1")
+		assert.Error(t, err)
+		assert.FileExists(t, filepath.Join(stray, "cp-1.json"))
+	})
+
+	t.Run("MovedCheckpointLeavesOldExecution", func(t *testing.T) {
+		store, err := graph.NewFileCheckpointStore(t.TempDir())
+		require.NoError(t, err)
+		save(store, "cp-1", "thread-1")
+		save(store, "cp-1", "thread-2")
+
+		listed, err := store.List(ctx, "thread-1")
+		require.NoError(t, err)
+		assert.Empty(t, listed)
+
+		require.NoError(t, store.Clear(ctx, "thread-1"))
+		loaded, err := store.Load(ctx, "cp-1")
+		require.NoError(t, err)
+		assert.Equal(t, "thread-2", loaded.Metadata["execution_id"])
+	})
+
+	t.Run("ClearRemovesIDs", func(t *testing.T) {
+		store, err := graph.NewFileCheckpointStore(t.TempDir())
+		require.NoError(t, err)
+		save(store, "cp-1", "thread-1")
+		require.NoError(t, store.Clear(ctx, "thread-1"))
+
+		_, err = store.Load(ctx, "cp-1")
+		assert.Error(t, err)
+		listed, err := store.List(ctx, "thread-1")
+		require.NoError(t, err)
+		assert.Empty(t, listed)
+	})
+
+	t.Run("BuiltForStoreWithoutIt", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := graph.NewFileCheckpointStore(dir)
+		require.NoError(t, err)
+		save(store, "cp-1", "thread/1")
+		save(store, "cp-2", "thread-2")
+		require.NoError(t, os.RemoveAll(filepath.Join(dir, "ids")))
+
+		reopened, err := graph.NewFileCheckpointStore(dir)
+		require.NoError(t, err)
+		for _, id := range []string{"cp-1", "cp-2"} {
+			loaded, err := reopened.Load(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, id, loaded.State)
+		}
+	})
+}
+
+func TestFileCheckpointStore_WithCheckpointableRunnable(t *testing.T) {
+	store, err := graph.NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+
+	g := graph.NewCheckpointableStateGraphWithConfig(graph.CheckpointConfig{Store: store, AutoSave: true})
+	g.AddNode("A", "A", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return state.(string) + "A", nil
+	})
+	g.AddNode("B", "B", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return state.(string) + "B", nil
+	})
+	g.SetEntryPoint("A")
+	g.AddEdge("A", "B")
+	g.AddEdge("B", graph.END)
+
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = runnable.Invoke(ctx, "")
+	require.NoError(t, err)
+
+	checkpoints, err := runnable.ListCheckpoints(ctx)
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 2)
+
+	res, err := runnable.ResumeFromCheckpoint(ctx, checkpoints[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "AB", res)
+}
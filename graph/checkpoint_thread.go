@@ -0,0 +1,127 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// threadLock serializes the runs of a thread
+type threadLock struct {
+	mutex sync.Mutex
+	refs  int
+}
+
+// lockThread waits until no other run of the thread is in progress, and returns the
+// function releasing the thread
+func (cr *CheckpointableRunnable) lockThread(threadID string) func() {
+	cr.threadsMutex.Lock()
+	if cr.threads == nil {
+		cr.threads = make(map[string]*threadLock)
+	}
+	lock, ok := cr.threads[threadID]
+	if !ok {
+		lock = &threadLock{}
+		cr.threads[threadID] = lock
+	}
+	lock.refs++
+	cr.threadsMutex.Unlock()
+
+	lock.mutex.Lock()
+	return func() {
+		lock.mutex.Unlock()
+
+		cr.threadsMutex.Lock()
+		defer cr.threadsMutex.Unlock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(cr.threads, threadID)
+		}
+	}
+}
+
+// threadConfig returns the thread and checkpoint a config refers to
+func threadConfig(config *Config) (threadID, checkpointID string) {
+	if config == nil || config.Configurable == nil {
+		return "", ""
+	}
+	threadID, _ = config.Configurable["thread_id"].(string)
+	checkpointID, _ = config.Configurable["checkpoint_id"].(string)
+	return threadID, checkpointID
+}
+
+// invokeThread runs the graph as the next run of a thread, see InvokeWithConfig
+func (cr *CheckpointableRunnable) invokeThread(ctx context.Context, initialState interface{}, config *Config, threadID, checkpointID string) (interface{}, error) {
+	unlock := cr.lockThread(threadID)
+	defer unlock()
+
+	var latest *Checkpoint
+	if checkpointID != "" {
+		checkpoint, err := cr.config.Store.Load(ctx, checkpointID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load checkpoint: %w", err)
+		}
+		latest = checkpoint
+	} else {
+		// Checkpoints of subgraph runs are skipped
+		checkpoints, err := cr.config.Store.List(ctx, threadID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load checkpoints of thread %s: %w", threadID, err)
+		}
+		latest = latestCheckpoint(checkpoints, "")
+	}
+
+	// First run of the thread
+	if latest == nil {
+		return cr.invoke(ctx, initialState, config, nil)
+	}
+
+	// Without new input, continue the pending work of the thread, e.g. an interrupt
+	if initialState == nil {
+		return cr.resume(ctx, latest, config)
+	}
+
+	state, err := cr.mergeState(latest.State, initialState)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run the graph again from its entry point, chaining the checkpoints of the run
+	// onto the checkpoint of the thread
+	parent := *latest
+	parent.PendingWrites = nil
+	return cr.invoke(ctx, state, config, &parent)
+}
+
+// mergeState merges values into the current state through the schema of the graph.
+// Without a schema, maps are merged key by key and other values replace the state.
+func (cr *CheckpointableRunnable) mergeState(current, values interface{}) (interface{}, error) {
+	schema := cr.runnable.graph.Schema
+	if schema != nil {
+		if current == nil {
+			current = schema.Init()
+		}
+		merged, err := schema.Update(current, values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge state: %w", err)
+		}
+		return merged, nil
+	}
+
+	curMap, ok := current.(map[string]interface{})
+	if !ok {
+		return values, nil
+	}
+	valMap, ok := values.(map[string]interface{})
+	if !ok {
+		return values, nil
+	}
+	merged := make(map[string]interface{}, len(curMap)+len(valMap))
+	for k, v := range curMap {
+		merged[k] = v
+	}
+	for k, v := range valMap {
+		merged[k] = v
+	}
+	return merged, nil
+}
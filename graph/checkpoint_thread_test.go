@@ -0,0 +1,122 @@
+package graph_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newChatRunnable builds a single "reply" node answering the last message of the
+// "messages" state with an echo
+func newChatRunnable(t *testing.T) *graph.CheckpointableRunnable {
+	g := graph.NewCheckpointableStateGraph()
+	schema := graph.NewMapSchema()
+	schema.RegisterReducer("messages", graph.AppendReducer)
+	g.SetSchema(schema)
+
+	g.AddNode("reply", "reply", func(ctx context.Context, state interface{}) (interface{}, error) {
+		messages := state.(map[string]interface{})["messages"].([]string)
+		return map[string]interface{}{"messages": []string{"echo: " + messages[len(messages)-1]}}, nil
+	})
+	g.SetEntryPoint("reply")
+	g.AddEdge("reply", graph.END)
+
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+	return runnable
+}
+
+func threadConfig(threadID string) *graph.Config {
+	return &graph.Config{Configurable: map[string]interface{}{"thread_id": threadID}}
+}
+
+func say(t *testing.T, runnable *graph.CheckpointableRunnable, threadID, message string) []string {
+	res, err := runnable.InvokeWithConfig(context.Background(), map[string]interface{}{"messages": []string{message}}, threadConfig(threadID))
+	require.NoError(t, err)
+	return res.(map[string]interface{})["messages"].([]string)
+}
+
+func TestThread_ContinuesConversation(t *testing.T) {
+	runnable := newChatRunnable(t)
+
+	assert.Equal(t, []string{"hi", "echo: hi"}, say(t, runnable, "alice", "hi"))
+	assert.Equal(t, []string{"hello", "echo: hello"}, say(t, runnable, "bob", "hello"))
+	assert.Equal(t, []string{"hi", "echo: hi", "again", "echo: again"}, say(t, runnable, "alice", "again"))
+
+	snapshot, err := runnable.GetState(context.Background(), threadConfig("bob"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hello", "echo: hello"}, snapshot.Values.(map[string]interface{})["messages"])
+
+	// The second run of the thread is chained onto the first one
+	snapshot, err = runnable.GetState(context.Background(), threadConfig("alice"))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", snapshot.Metadata["execution_id"])
+	parent, err := runnable.LoadCheckpoint(context.Background(), snapshot.ParentID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hi", "echo: hi"}, parent.State.(map[string]interface{})["messages"])
+}
+
+func TestThread_ConcurrentSessions(t *testing.T) {
+	runnable := newChatRunnable(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		threadID := fmt.Sprintf("session-%d", i%5)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := runnable.InvokeWithConfig(context.Background(), map[string]interface{}{"messages": []string{"ping"}}, threadConfig(threadID))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// No run of a session was lost
+	for i := 0; i < 5; i++ {
+		snapshot, err := runnable.GetState(context.Background(), threadConfig(fmt.Sprintf("session-%d", i)))
+		require.NoError(t, err)
+		assert.Len(t, snapshot.Values.(map[string]interface{})["messages"], 8)
+	}
+}
+
+func TestThread_ResumesWithoutInput(t *testing.T) {
+	runnable := newDurableRunnable(t, graph.DefaultCheckpointConfig(), nil)
+	ctx := context.Background()
+
+	config := threadConfig("job")
+	config.InterruptBefore = []string{"c"}
+	_, err := runnable.InvokeWithConfig(ctx, "", config)
+	var interrupt *graph.GraphInterrupt
+	require.ErrorAs(t, err, &interrupt)
+
+	res, err := runnable.InvokeWithConfig(ctx, nil, threadConfig("job"))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", res)
+
+	// The thread has finished, so there is nothing left to run
+	res, err = runnable.InvokeWithConfig(ctx, nil, threadConfig("job"))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", res)
+}
+
+func TestThread_ForksFromCheckpoint(t *testing.T) {
+	runnable := newChatRunnable(t)
+	ctx := context.Background()
+
+	say(t, runnable, "alice", "hi")
+	first, err := runnable.GetState(ctx, threadConfig("alice"))
+	require.NoError(t, err)
+	say(t, runnable, "alice", "again")
+
+	// Continue from the first checkpoint instead of the latest one
+	config := threadConfig("alice")
+	config.Configurable["checkpoint_id"] = first.Config.Configurable["checkpoint_id"]
+	res, err := runnable.InvokeWithConfig(ctx, map[string]interface{}{"messages": []string{"other"}}, config)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hi", "echo: hi", "other", "echo: other"}, res.(map[string]interface{})["messages"])
+}
@@ -0,0 +1,144 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Durability controls when the checkpoints of a run are written to the store
+type Durability string
+
+const (
+	// DurabilitySync saves the checkpoint of each step before the next step starts.
+	// A failed save stops the run with the save error.
+	DurabilitySync Durability = "sync"
+
+	// DurabilityAsync saves the checkpoints in the background, in order, while the
+	// next steps run. The run waits for the queued checkpoints to be saved before it
+	// returns, and returns the errors of the saves that failed.
+	DurabilityAsync Durability = "async"
+
+	// DurabilityExit only saves the checkpoint of the last step when the run ends,
+	// or the checkpoint of the step it was interrupted or failed in.
+	DurabilityExit Durability = "exit"
+)
+
+// defaultCheckpointQueueSize is the number of checkpoints DurabilityAsync queues
+// before the run waits for the store
+const defaultCheckpointQueueSize = 64
+
+// checkpointWrite is a checkpoint queued for saving, or a flush marker when
+// checkpoint is nil
+type checkpointWrite struct {
+	ctx        context.Context
+	checkpoint *Checkpoint
+	done       chan struct{}
+}
+
+// checkpointWriter saves checkpoints in the background, in the order they are queued
+type checkpointWriter struct {
+	store CheckpointStore
+	queue chan checkpointWrite
+
+	// sendMutex guards sends on queue against its closing
+	sendMutex sync.Mutex
+	closed    bool
+
+	errMutex sync.Mutex
+	errs     []error
+}
+
+// newCheckpointWriter starts a writer queuing up to size checkpoints
+func newCheckpointWriter(store CheckpointStore, size int) *checkpointWriter {
+	if size <= 0 {
+		size = defaultCheckpointQueueSize
+	}
+	w := &checkpointWriter{
+		store: store,
+		queue: make(chan checkpointWrite, size),
+	}
+	go w.run()
+	return w
+}
+
+func (w *checkpointWriter) run() {
+	for write := range w.queue {
+		if write.checkpoint == nil {
+			close(write.done)
+			continue
+		}
+		if err := saveCheckpoint(write.ctx, w.store, write.checkpoint); err != nil {
+			w.errMutex.Lock()
+			w.errs = append(w.errs, err)
+			w.errMutex.Unlock()
+		}
+	}
+}
+
+// enqueue queues a checkpoint, waiting while the queue is full. The checkpoint is
+// saved even if ctx is cancelled.
+func (w *checkpointWriter) enqueue(ctx context.Context, checkpoint *Checkpoint) error {
+	w.sendMutex.Lock()
+	defer w.sendMutex.Unlock()
+
+	if w.closed {
+		return errors.New("checkpoint writer is closed")
+	}
+	w.queue <- checkpointWrite{ctx: context.WithoutCancel(ctx), checkpoint: checkpoint}
+	return nil
+}
+
+// flush waits until the checkpoints queued so far are saved, and returns the errors
+// of the saves that failed since the previous flush
+func (w *checkpointWriter) flush(ctx context.Context) error {
+	done := make(chan struct{})
+
+	w.sendMutex.Lock()
+	if w.closed {
+		w.sendMutex.Unlock()
+		return nil
+	}
+	w.queue <- checkpointWrite{done: done}
+	w.sendMutex.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	w.errMutex.Lock()
+	defer w.errMutex.Unlock()
+	err := errors.Join(w.errs...)
+	w.errs = nil
+	return err
+}
+
+// close flushes the writer and stops it
+func (w *checkpointWriter) close(ctx context.Context) error {
+	err := w.flush(ctx)
+
+	w.sendMutex.Lock()
+	defer w.sendMutex.Unlock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	return err
+}
+
+// saveCheckpoint numbers checkpoint after the last checkpoint of its execution and saves it
+func saveCheckpoint(ctx context.Context, store CheckpointStore, checkpoint *Checkpoint) error {
+	// Get current version from existing checkpoints
+	executionID, _ := checkpoint.Metadata["execution_id"].(string)
+	checkpoints, err := store.List(ctx, executionID)
+	checkpoint.Version = 1
+	if err == nil && len(checkpoints) > 0 {
+		// Get the latest version
+		latest := checkpoints[len(checkpoints)-1]
+		checkpoint.Version = latest.Version + 1
+	}
+
+	return store.Save(ctx, checkpoint)
+}
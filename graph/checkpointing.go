@@ -2,9 +2,8 @@ package graph
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"sort"
 	"sync"
 	"time"
@@ -18,6 +17,39 @@ type Checkpoint struct {
 	Metadata  map[string]interface{} `json:"metadata"`
 	Timestamp time.Time              `json:"timestamp"`
 	Version   int                    `json:"version"`
+
+	// Next lists the nodes to execute when resuming; empty once the run has finished
+	Next []string `json:"next,omitempty"`
+
+	// Step is the number of super-steps completed when the checkpoint was taken
+	Step int `json:"step"`
+
+	// ParentID is the ID of the previous checkpoint of the same execution
+	ParentID string `json:"parent_id,omitempty"`
+
+	// Interrupt is set when the run stopped on an interrupt that has not been resumed yet
+	Interrupt *PendingInterrupt `json:"interrupt,omitempty"`
+
+	// PendingWrites are the results of the tasks of the step in Next that completed
+	// before the step failed or was interrupted. When resuming, these tasks are not
+	// executed again and their results are merged with the results of the others.
+	PendingWrites []PendingWrite `json:"pending_writes,omitempty"`
+}
+
+// PendingInterrupt records the interrupt a checkpointed run is waiting on
+type PendingInterrupt struct {
+	Node  string      `json:"node"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// PendingWrite is the result of a task of a step that did not complete
+type PendingWrite struct {
+	// Task is the index of the task in the step, i.e. in Checkpoint.Next
+	Task int `json:"task"`
+	// Node is the node that ran the task
+	Node string `json:"node"`
+	// Value is the result returned by the node
+	Value interface{} `json:"value"`
 }
 
 // CheckpointStore defines the interface for checkpoint persistence
@@ -116,81 +148,6 @@ func (m *MemoryCheckpointStore) Clear(_ context.Context, executionID string) err
 	return nil
 }
 
-// FileCheckpointStore provides file-based checkpoint storage
-type FileCheckpointStore struct {
-	writer io.Writer
-	reader io.Reader
-	mutex  sync.RWMutex
-}
-
-// NewFileCheckpointStore creates a new file-based checkpoint store
-func NewFileCheckpointStore(writer io.Writer, reader io.Reader) *FileCheckpointStore {
-	return &FileCheckpointStore{
-		writer: writer,
-		reader: reader,
-	}
-}
-
-// Save implements CheckpointStore interface for file storage
-func (f *FileCheckpointStore) Save(_ context.Context, checkpoint *Checkpoint) error {
-	f.mutex.Lock()
-	defer f.mutex.Unlock()
-
-	data, err := json.Marshal(checkpoint)
-	if err != nil {
-		return fmt.Errorf("failed to marshal checkpoint: %w", err)
-	}
-
-	_, err = f.writer.Write(data)
-	if err != nil {
-		return fmt.Errorf("failed to write checkpoint: %w", err)
-	}
-
-	return nil
-}
-
-// Load implements CheckpointStore interface for file storage
-func (f *FileCheckpointStore) Load(_ context.Context, checkpointID string) (*Checkpoint, error) {
-	f.mutex.RLock()
-	defer f.mutex.RUnlock()
-
-	data, err := io.ReadAll(f.reader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
-	}
-
-	var checkpoint Checkpoint
-	err = json.Unmarshal(data, &checkpoint)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal checkpoint: %w", err)
-	}
-
-	if checkpoint.ID != checkpointID {
-		return nil, fmt.Errorf("checkpoint not found: %s", checkpointID)
-	}
-
-	return &checkpoint, nil
-}
-
-// List implements CheckpointStore interface for file storage
-func (f *FileCheckpointStore) List(_ context.Context, _ string) ([]*Checkpoint, error) {
-	// For file storage, this would typically involve reading from multiple files
-	// This is a simplified implementation
-	return nil, fmt.Errorf("list operation not implemented for file store")
-}
-
-// Delete implements CheckpointStore interface for file storage
-func (f *FileCheckpointStore) Delete(_ context.Context, checkpointID string) error {
-	// For file storage, this would involve file system operations
-	return fmt.Errorf("delete operation not implemented for file store")
-}
-
-// Clear implements CheckpointStore interface for file storage
-func (f *FileCheckpointStore) Clear(_ context.Context, executionID string) error {
-	// For file storage, this would involve file system operations
-	return fmt.Errorf("clear operation not implemented for file store")
-}
-
 // CheckpointConfig configures checkpointing behavior
 type CheckpointConfig struct {
 	// Store is the checkpoint storage backend
@@ -204,6 +161,13 @@ type CheckpointConfig struct {
 
 	// MaxCheckpoints limits the number of checkpoints to keep
 	MaxCheckpoints int
+
+	// Durability controls when checkpoints are saved, DurabilitySync by default
+	Durability Durability
+
+	// AsyncQueueSize is the number of checkpoints DurabilityAsync queues before
+	// the run waits for the store. Defaults to 64.
+	AsyncQueueSize int
 }
 
 // DefaultCheckpointConfig returns a default checkpoint configuration
@@ -213,6 +177,7 @@ func DefaultCheckpointConfig() CheckpointConfig {
 		AutoSave:       true,
 		SaveInterval:   30 * time.Second,
 		MaxCheckpoints: 10,
+		Durability:     DurabilitySync,
 	}
 }
 
@@ -222,6 +187,14 @@ type CheckpointableRunnable struct {
 	config   CheckpointConfig
 
 	executionID string
+
+	// writers are the background writers of the running invocations
+	writersMutex sync.Mutex
+	writers      map[*checkpointWriter]struct{}
+
+	// threads serialize the runs of each thread
+	threadsMutex sync.Mutex
+	threads      map[string]*threadLock
 }
 
 // NewCheckpointableRunnable creates a new checkpointable runnable
@@ -238,31 +211,128 @@ func (cr *CheckpointableRunnable) Invoke(ctx context.Context, initialState inter
 	return cr.InvokeWithConfig(ctx, initialState, nil)
 }
 
-// InvokeWithConfig executes the graph with checkpointing and config
+// InvokeWithConfig executes the graph with checkpointing and config.
+//
+// When Config.Configurable["thread_id"] is set, the run continues the thread: its
+// checkpoints are saved under the thread ID, and it starts from the latest checkpoint
+// of the thread, or from Configurable["checkpoint_id"] when set. A nil initialState
+// resumes the pending work of that checkpoint, such as an interrupt; otherwise
+// initialState is merged into its state through the schema and the graph runs again
+// from its entry point. Runs of the same thread are executed one at a time, so a
+// single runnable can serve many conversations concurrently.
 func (cr *CheckpointableRunnable) InvokeWithConfig(ctx context.Context, initialState interface{}, config *Config) (interface{}, error) {
-	// Extract thread_id from config if present
-	var threadID string
-	if config != nil && config.Configurable != nil {
-		if tid, ok := config.Configurable["thread_id"].(string); ok {
-			threadID = tid
-		}
+	if threadID, checkpointID := threadConfig(config); threadID != "" {
+		return cr.invokeThread(ctx, initialState, config, threadID, checkpointID)
+	}
+	return cr.invoke(ctx, initialState, config, nil)
+}
+
+// invoke runs the graph with a checkpoint listener attached. When resuming, from is
+// the checkpoint the run continues from and new checkpoints are chained onto it.
+func (cr *CheckpointableRunnable) invoke(ctx context.Context, initialState interface{}, config *Config, from *Checkpoint) (interface{}, error) {
+	// The checkpoints of a thread are saved under its ID
+	threadID, _ := threadConfig(config)
+	executionID := cr.executionID
+	if threadID != "" {
+		executionID = threadID
 	}
 
 	// Create checkpointing listener
 	checkpointListener := &CheckpointListener{
 		store:       cr.config.Store,
-		executionID: cr.executionID,
+		executionID: executionID,
 		threadID:    threadID,
 		autoSave:    cr.config.AutoSave,
+		durability:  cr.config.Durability,
+	}
+	if cr.config.Durability == DurabilityAsync {
+		checkpointListener.writer = cr.startWriter()
+	}
+	if from != nil {
+		if execID, ok := from.Metadata["execution_id"].(string); ok && execID != "" {
+			checkpointListener.executionID = execID
+		}
+		if threadID == "" {
+			checkpointListener.threadID, _ = from.Metadata["thread_id"].(string)
+		}
+		checkpointListener.parentID = from.ID
+		checkpointListener.stepOffset = from.Step
+		checkpointListener.lastStep = from.Step
+	}
+
+	// Add checkpoint listener to a copy of the config callbacks
+	runConfig := &Config{}
+	if config != nil {
+		*runConfig = *config
+	}
+	runConfig.Callbacks = append(append([]CallbackHandler(nil), runConfig.Callbacks...), checkpointListener)
+
+	// Subgraphs executed by the nodes checkpoint under the namespace of their node
+	ctx = withCheckpointListener(ctx, checkpointListener)
+
+	// Tasks that completed before the resumed step failed are not executed again
+	if from != nil {
+		ctx = withPendingWrites(ctx, from.PendingWrites)
 	}
 
-	// Add checkpoint listener to config callbacks
-	if config == nil {
-		config = &Config{}
+	result, err := cr.runnable.InvokeWithConfig(ctx, initialState, runConfig)
+
+	// Record where the run has to continue from, and wait for its checkpoints to be
+	// saved so that it can be resumed as soon as it returns
+	saveErr := checkpointListener.finish(ctx, err)
+	if checkpointListener.writer != nil {
+		saveErr = errors.Join(saveErr, cr.stopWriter(ctx, checkpointListener.writer))
+	}
+	if saveErr != nil {
+		if err == nil {
+			return result, saveErr
+		}
+		return result, errors.Join(err, saveErr)
 	}
-	config.Callbacks = append(config.Callbacks, checkpointListener)
 
-	return cr.runnable.InvokeWithConfig(ctx, initialState, config)
+	return result, err
+}
+
+// startWriter starts the background writer of an invocation
+func (cr *CheckpointableRunnable) startWriter() *checkpointWriter {
+	writer := newCheckpointWriter(cr.config.Store, cr.config.AsyncQueueSize)
+
+	cr.writersMutex.Lock()
+	defer cr.writersMutex.Unlock()
+	if cr.writers == nil {
+		cr.writers = make(map[*checkpointWriter]struct{})
+	}
+	cr.writers[writer] = struct{}{}
+	return writer
+}
+
+// stopWriter flushes and stops the background writer of an invocation
+func (cr *CheckpointableRunnable) stopWriter(ctx context.Context, writer *checkpointWriter) error {
+	cr.writersMutex.Lock()
+	delete(cr.writers, writer)
+	cr.writersMutex.Unlock()
+
+	return writer.close(ctx)
+}
+
+// Flush waits until the checkpoints queued by the running invocations are saved, and
+// returns the errors of the saves that failed since the previous flush. Only
+// DurabilityAsync queues checkpoints; invocations also flush them before returning.
+func (cr *CheckpointableRunnable) Flush(ctx context.Context) error {
+	cr.writersMutex.Lock()
+	writers := make([]*checkpointWriter, 0, len(cr.writers))
+	for writer := range cr.writers {
+		writers = append(writers, writer)
+	}
+	cr.writersMutex.Unlock()
+
+	var errs []error
+	for _, writer := range writers {
+		if err := writer.flush(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // SaveCheckpoint manually saves a checkpoint
@@ -300,17 +370,37 @@ func (cr *CheckpointableRunnable) ListCheckpoints(ctx context.Context) ([]*Check
 	return cr.config.Store.List(ctx, cr.executionID)
 }
 
-// ResumeFromCheckpoint resumes execution from a specific checkpoint
+// ResumeFromCheckpoint resumes execution from a specific checkpoint.
+// The graph continues with the nodes recorded in Checkpoint.Next; a checkpoint
+// taken at the end of a run has nothing left to execute and its state is returned as is.
 func (cr *CheckpointableRunnable) ResumeFromCheckpoint(ctx context.Context, checkpointID string) (interface{}, error) {
+	return cr.ResumeFromCheckpointWithConfig(ctx, checkpointID, nil)
+}
+
+// ResumeFromCheckpointWithConfig resumes execution from a specific checkpoint with config.
+// Use Config.ResumeValue to answer a pending interrupt.
+func (cr *CheckpointableRunnable) ResumeFromCheckpointWithConfig(ctx context.Context, checkpointID string, config *Config) (interface{}, error) {
 	checkpoint, err := cr.LoadCheckpoint(ctx, checkpointID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load checkpoint: %w", err)
 	}
 
-	// Resume execution from the checkpointed state
-	// This would require the graph to support starting from a specific node
-	// For now, we'll return the checkpointed state
-	return checkpoint.State, nil
+	return cr.resume(ctx, checkpoint, config)
+}
+
+// resume continues the run of a checkpoint with the nodes recorded in Checkpoint.Next
+func (cr *CheckpointableRunnable) resume(ctx context.Context, checkpoint *Checkpoint, config *Config) (interface{}, error) {
+	if len(checkpoint.Next) == 0 {
+		return checkpoint.State, nil
+	}
+
+	resumeConfig := &Config{}
+	if config != nil {
+		*resumeConfig = *config
+	}
+	resumeConfig.ResumeFrom = checkpoint.Next
+
+	return cr.invoke(ctx, checkpoint.State, resumeConfig, checkpoint)
 }
 
 // ClearCheckpoints removes all checkpoints for this execution
@@ -324,52 +414,293 @@ type CheckpointListener struct {
 	executionID string
 	threadID    string
 	autoSave    bool
+
+	// parentID is the ID of the last checkpoint saved for this run
+	parentID string
+	// stepOffset is the number of steps completed before this run was resumed
+	stepOffset int
+	// lastStep is the number of steps completed so far
+	lastStep int
+
+	// namespace identifies the subgraph run being checkpointed, empty for the root run
+	namespace string
+
+	// failure is the step that failed or was interrupted, if any
+	failure *stepFailure
+
+	// durability controls when checkpoints are saved
+	durability Durability
+	// writer saves the checkpoints in the background with DurabilityAsync
+	writer *checkpointWriter
+	// last is the checkpoint of the last step, saved when the run ends with DurabilityExit
+	last *Checkpoint
+	// stepErr is the error of the last step checkpoint that failed to save
+	stepErr error
+
 	// Embed NoOpCallbackHandler to satisfy other CallbackHandler methods
 	NoOpCallbackHandler
 }
 
 // OnGraphStep implements GraphCallbackHandler
 func (cl *CheckpointListener) OnGraphStep(ctx context.Context, stepNode string, state interface{}) {
+	cl.lastStep = cl.stepOffset + GetStep(ctx)
+
 	if !cl.autoSave {
 		return
 	}
 
-	// Get current version from existing checkpoints
-	checkpoints, err := cl.store.List(ctx, cl.executionID)
-	version := 1
-	if err == nil && len(checkpoints) > 0 {
-		// Get the latest version
-		latest := checkpoints[len(checkpoints)-1]
-		version = latest.Version + 1
+	checkpoint := cl.newCheckpoint(stepNode, state, "step", GetNextNodes(ctx), nil, nil)
+	if cl.durability == DurabilityExit {
+		// Only the last step is saved, when the run ends
+		cl.last = checkpoint
+		return
+	}
+	if err := cl.persist(ctx, checkpoint); err != nil {
+		cl.stepErr = err
+	}
+}
+
+// stepError implements stepErrorReporter
+func (cl *CheckpointListener) stepError() error {
+	err := cl.stepErr
+	cl.stepErr = nil
+	return err
+}
+
+// finish records where a run that ended with err has to continue from: the interrupt
+// or the failed step it stopped in, or with DurabilityExit its last step. It returns
+// the error of the save, if any.
+func (cl *CheckpointListener) finish(ctx context.Context, err error) error {
+	var graphInterrupt *GraphInterrupt
+	if errors.As(err, &graphInterrupt) {
+		return cl.saveInterrupt(ctx, graphInterrupt)
+	}
+
+	// When no task of the failed step completed, the run resumes from the last step
+	if err != nil && cl.failure != nil && len(cl.failure.writes) > 0 {
+		return cl.saveFailure(ctx)
+	}
+
+	if cl.last != nil {
+		last := cl.last
+		cl.last = nil
+		return cl.persist(ctx, last)
+	}
+	return nil
+}
+
+// onStepFailure implements stepFailureHandler
+func (cl *CheckpointListener) onStepFailure(_ context.Context, failure *stepFailure) {
+	cl.failure = failure
+}
+
+// saveInterrupt records a checkpoint for a run that stopped on an interrupt
+func (cl *CheckpointListener) saveInterrupt(ctx context.Context, interrupt *GraphInterrupt) error {
+	next := withoutEnd(interrupt.NextNodes)
+	if interrupt.NextNodes == nil {
+		// Interrupted before the node ran, so it still has to be executed
+		next = []string{interrupt.Node}
 	}
 
+	// Interrupted by a node: the whole step runs again, except the tasks that completed
+	var writes []PendingWrite
+	if cl.failure != nil {
+		next = cl.failure.nodes
+		writes = cl.failure.writes
+	}
+
+	return cl.persist(ctx, cl.newCheckpoint(interrupt.Node, interrupt.State, "interrupt", next, &PendingInterrupt{
+		Node:  interrupt.Node,
+		Value: interrupt.InterruptValue,
+	}, writes))
+}
+
+// saveFailure records a checkpoint for a run whose last step failed after some of its
+// tasks completed, so that it can be resumed without executing them again
+func (cl *CheckpointListener) saveFailure(ctx context.Context) error {
+	return cl.persist(ctx, cl.newCheckpoint(cl.failure.failedNode(), cl.failure.state, "error", cl.failure.nodes, nil, cl.failure.writes))
+}
+
+// newCheckpoint creates a checkpoint of the run, numbered when it is saved
+func (cl *CheckpointListener) newCheckpoint(nodeName string, state interface{}, event string, next []string, interrupt *PendingInterrupt, writes []PendingWrite) *Checkpoint {
 	metadata := map[string]interface{}{
 		"execution_id": cl.executionID,
-		"event":        "step",
+		"event":        event,
 	}
 	if cl.threadID != "" {
 		metadata["thread_id"] = cl.threadID
 	}
+	if cl.namespace != "" {
+		metadata["checkpoint_ns"] = cl.namespace
+	}
 
-	checkpoint := &Checkpoint{
+	return &Checkpoint{
 		ID:        generateCheckpointID(),
-		NodeName:  stepNode,
+		NodeName:  nodeName,
 		State:     state,
 		Timestamp: time.Now(),
-		Version:   version,
 		Metadata:  metadata,
+		Next:      next,
+		Step:      cl.lastStep,
+		ParentID:  cl.parentID,
+		Interrupt: interrupt,
+
+		PendingWrites: writes,
 	}
+}
 
-	// Save checkpoint synchronously to avoid race conditions in tests
-	if saveErr := cl.store.Save(ctx, checkpoint); saveErr != nil {
-		_ = saveErr
+// persist saves checkpoint, or queues it with DurabilityAsync, and chains the next
+// checkpoints onto it
+func (cl *CheckpointListener) persist(ctx context.Context, checkpoint *Checkpoint) error {
+	if cl.writer != nil {
+		if err := cl.writer.enqueue(ctx, checkpoint); err != nil {
+			return err
+		}
+	} else if err := saveCheckpoint(ctx, cl.store, checkpoint); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
 	}
+	cl.parentID = checkpoint.ID
+	return nil
 }
 
 // OnNodeEvent is no longer used for saving state, but kept if needed for interface compatibility
 // or we can remove it if we don't use it as NodeListener anymore.
 // CheckpointableRunnable currently adds it as NodeListener. We should change that.
 
+// stepFailure describes a step that failed or was interrupted
+type stepFailure struct {
+	// state is the state before the step
+	state interface{}
+	// nodes are the nodes of the tasks of the step
+	nodes []string
+	// writes are the results of the tasks that completed
+	writes []PendingWrite
+}
+
+// failedNode returns the node of the first task that did not complete
+func (f *stepFailure) failedNode() string {
+	done := make(map[int]bool, len(f.writes))
+	for _, write := range f.writes {
+		done[write.Task] = true
+	}
+	for i, node := range f.nodes {
+		if !done[i] {
+			return node
+		}
+	}
+	return ""
+}
+
+// stepFailureHandler is implemented by callbacks that record the tasks completed in a
+// step that failed or was interrupted
+type stepFailureHandler interface {
+	onStepFailure(ctx context.Context, failure *stepFailure)
+}
+
+// stepErrorReporter is implemented by callbacks that stop the run when they fail to
+// handle a step, e.g. to save its checkpoint
+type stepErrorReporter interface {
+	stepError() error
+}
+
+type pendingWritesKey struct{}
+
+// withPendingWrites adds the pending writes of the step a run resumes to the context
+func withPendingWrites(ctx context.Context, writes []PendingWrite) context.Context {
+	return context.WithValue(ctx, pendingWritesKey{}, writes)
+}
+
+// takePendingWrites returns the pending writes of the context and a context without them,
+// so that they are not applied by nested runs
+func takePendingWrites(ctx context.Context) (context.Context, []PendingWrite) {
+	writes, _ := ctx.Value(pendingWritesKey{}).([]PendingWrite)
+	if writes == nil {
+		return ctx, nil
+	}
+	return withPendingWrites(ctx, nil), writes
+}
+
+// pendingWriteFor returns the pending write of the task at index running node
+func pendingWriteFor(writes []PendingWrite, index int, node string) (PendingWrite, bool) {
+	for _, write := range writes {
+		if write.Task == index && write.Node == node {
+			return write, true
+		}
+	}
+	return PendingWrite{}, false
+}
+
+type checkpointListenerKey struct{}
+
+// withCheckpointListener adds the listener checkpointing the current run to the context
+func withCheckpointListener(ctx context.Context, listener *CheckpointListener) context.Context {
+	return context.WithValue(ctx, checkpointListenerKey{}, listener)
+}
+
+// activeCheckpointListener returns the listener checkpointing the run the context belongs to
+func activeCheckpointListener(ctx context.Context) *CheckpointListener {
+	listener, _ := ctx.Value(checkpointListenerKey{}).(*CheckpointListener)
+	return listener
+}
+
+// nested returns the listener checkpointing a subgraph run started by the given node.
+// Its namespace is "node:step" prefixed by the namespace of cl and "|", where step is
+// the step of the node in the run of cl, counted across resumes.
+func (cl *CheckpointListener) nested(ctx context.Context, node string) *CheckpointListener {
+	namespace := fmt.Sprintf("%s:%d", node, cl.stepOffset+GetStep(ctx))
+	if cl.namespace != "" {
+		namespace = cl.namespace + "|" + namespace
+	}
+	return &CheckpointListener{
+		store:       cl.store,
+		executionID: cl.executionID,
+		threadID:    cl.threadID,
+		autoSave:    cl.autoSave,
+		durability:  cl.durability,
+		writer:      cl.writer,
+		namespace:   namespace,
+	}
+}
+
+// pendingInterrupt returns the last checkpoint of the namespace of cl when it records
+// an interrupt that has not been resumed yet, and nil otherwise.
+func (cl *CheckpointListener) pendingInterrupt(ctx context.Context) (*Checkpoint, error) {
+	checkpoints, err := cl.store.List(ctx, cl.executionID)
+	if err != nil {
+		return nil, err
+	}
+	latest := latestCheckpoint(checkpoints, cl.namespace)
+	if latest == nil || latest.Interrupt == nil {
+		return nil, nil
+	}
+	return latest, nil
+}
+
+// resumeFrom chains the checkpoints of cl onto the checkpoint the run resumes from
+func (cl *CheckpointListener) resumeFrom(checkpoint *Checkpoint) {
+	cl.parentID = checkpoint.ID
+	cl.stepOffset = checkpoint.Step
+	cl.lastStep = checkpoint.Step
+}
+
+// CheckpointNamespace returns the namespace of the subgraph run a checkpoint belongs to,
+// e.g. "research:2" or "research:2|search:1", or "" for checkpoints of the root run.
+func CheckpointNamespace(checkpoint *Checkpoint) string {
+	namespace, _ := checkpoint.Metadata["checkpoint_ns"].(string)
+	return namespace
+}
+
+// latestCheckpoint returns the last of the checkpoints (ordered by version) that
+// belongs to the given namespace
+func latestCheckpoint(checkpoints []*Checkpoint, namespace string) *Checkpoint {
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		if CheckpointNamespace(checkpoints[i]) == namespace {
+			return checkpoints[i]
+		}
+	}
+	return nil
+}
+
 // CheckpointableStateGraph extends ListenableStateGraph with checkpointing
 type CheckpointableStateGraph struct {
 	*ListenableStateGraph
@@ -459,11 +790,10 @@ func (cr *CheckpointableRunnable) GetState(ctx context.Context, config *Config)
 		// Get latest checkpoint for the thread
 		// Note: List returns all checkpoints. We need to find the latest one.
 		// This is inefficient for large histories. Real implementations should have GetLatest.
+		// Checkpoints of subgraph runs are skipped
 		checkpoints, err := cr.config.Store.List(ctx, threadID)
-		if err == nil && len(checkpoints) > 0 {
-			// Assuming List returns in some order, or we sort.
-			// For now, assume the last one is latest (based on implementation of MemoryStore)
-			checkpoint = checkpoints[len(checkpoints)-1]
+		if err == nil {
+			checkpoint = latestCheckpoint(checkpoints, "")
 		}
 	}
 
@@ -481,8 +811,10 @@ func (cr *CheckpointableRunnable) GetState(ctx context.Context, config *Config)
 	// Construct snapshot
 	snapshot := &StateSnapshot{
 		Values:    checkpoint.State,
+		Next:      checkpoint.Next,
 		CreatedAt: checkpoint.Timestamp,
 		Metadata:  checkpoint.Metadata,
+		ParentID:  checkpoint.ParentID,
 		Config: Config{
 			Configurable: map[string]interface{}{
 				"thread_id":     threadID,
@@ -491,11 +823,6 @@ func (cr *CheckpointableRunnable) GetState(ctx context.Context, config *Config)
 		},
 	}
 
-	// Determine "Next" nodes
-	// This is tricky without re-running the graph logic or storing it in the checkpoint.
-	// For now, we might leave it empty or try to infer it if we stored it.
-	// In the future, Checkpoint should store "Next" nodes.
-
 	return snapshot, nil
 }
 
@@ -512,61 +839,26 @@ func (cr *CheckpointableRunnable) UpdateState(ctx context.Context, config *Confi
 		threadID = cr.executionID
 	}
 
+	// Wait for the run of the thread in progress, if any
+	unlock := cr.lockThread(threadID)
+	defer unlock()
+
 	// 1. Get current state
 	// We need to find the latest checkpoint for this thread to merge against
 	checkpoints, err := cr.config.Store.List(ctx, threadID)
+	var latest *Checkpoint
+	if err == nil {
+		latest = latestCheckpoint(checkpoints, "")
+	}
 	var currentState interface{}
-	var currentVersion int
-
-	if err == nil && len(checkpoints) > 0 {
-		// Assume last is latest
-		latest := checkpoints[len(checkpoints)-1]
+	if latest != nil {
 		currentState = latest.State
-		currentVersion = latest.Version
-	} else {
-		// No existing state, initialize if schema exists
-		if cr.runnable.graph.Schema != nil {
-			currentState = cr.runnable.graph.Schema.Init()
-		}
 	}
 
 	// 2. Merge values
-	newState := values
-	if cr.runnable.graph.Schema != nil {
-		// If we have a current state, merge into it
-		if currentState != nil {
-			newState, err = cr.runnable.graph.Schema.Update(currentState, values)
-			if err != nil {
-				return nil, fmt.Errorf("failed to merge state: %w", err)
-			}
-		} else {
-			// If no current state, maybe Init + Update?
-			// Or just use values if it matches schema?
-			// Let's try Init + Update
-			initial := cr.runnable.graph.Schema.Init()
-			newState, err = cr.runnable.graph.Schema.Update(initial, values)
-			if err != nil {
-				return nil, fmt.Errorf("failed to initialize and merge state: %w", err)
-			}
-		}
-	} else if currentState != nil {
-		// No schema, but have current state.
-		// If map, try to merge? Or just overwrite?
-		// Without schema, we usually default to overwrite or simple merge if map.
-		// Let's assume overwrite if no schema, or maybe simple map merge if both are maps.
-		if curMap, ok := currentState.(map[string]interface{}); ok {
-			if valMap, ok := values.(map[string]interface{}); ok {
-				// Simple map merge
-				merged := make(map[string]interface{})
-				for k, v := range curMap {
-					merged[k] = v
-				}
-				for k, v := range valMap {
-					merged[k] = v
-				}
-				newState = merged
-			}
-		}
+	newState, err := cr.mergeState(currentState, values)
+	if err != nil {
+		return nil, err
 	}
 
 	// 3. Create new checkpoint
@@ -575,7 +867,7 @@ func (cr *CheckpointableRunnable) UpdateState(ctx context.Context, config *Confi
 		NodeName:  asNode, // The node that "made" this update
 		State:     newState,
 		Timestamp: time.Now(),
-		Version:   currentVersion + 1,
+		Version:   1,
 		Metadata: map[string]interface{}{
 			"execution_id": threadID,
 			"source":       "update_state",
@@ -583,6 +875,15 @@ func (cr *CheckpointableRunnable) UpdateState(ctx context.Context, config *Confi
 		},
 	}
 
+	// The update does not change where the run continues from
+	if latest != nil {
+		checkpoint.Version = latest.Version + 1
+		checkpoint.Next = latest.Next
+		checkpoint.Step = latest.Step
+		checkpoint.ParentID = latest.ID
+		checkpoint.Interrupt = latest.Interrupt
+	}
+
 	if err := cr.config.Store.Save(ctx, checkpoint); err != nil {
 		return nil, err
 	}
@@ -1,7 +1,6 @@
 package graph_test
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"strings"
@@ -231,8 +230,10 @@ func TestMemoryCheckpointStore_Clear(t *testing.T) {
 func TestFileCheckpointStore_SaveAndLoad(t *testing.T) {
 	t.Parallel()
 
-	var buf bytes.Buffer
-	store := graph.NewFileCheckpointStore(&buf, &buf)
+	store, err := graph.NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
 	ctx := context.Background()
 
 	checkpoint := &graph.Checkpoint{
@@ -243,7 +244,7 @@ func TestFileCheckpointStore_SaveAndLoad(t *testing.T) {
 	}
 
 	// Test Save
-	err := store.Save(ctx, checkpoint)
+	err = store.Save(ctx, checkpoint)
 	if err != nil {
 		t.Fatalf("Failed to save checkpoint: %v", err)
 	}
@@ -9,6 +9,7 @@ type Command struct {
 
 	// Goto specifies the next node(s) to execute.
 	// If set, it overrides the graph's edges.
-	// Can be a single string (node name) or []string.
+	// Can be a single string (node name), []string, a Send, []Send,
+	// or a []interface{} mixing node names and Sends.
 	Goto interface{}
 }
@@ -0,0 +1,93 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+)
+
+// conditionalEdge routes a node to the next nodes chosen at runtime from the state
+type conditionalEdge struct {
+	// route returns the labels of the next nodes
+	route func(ctx context.Context, state interface{}) ([]string, error)
+
+	// pathMap maps the labels returned by route to node names. When nil, the
+	// labels are the node names.
+	pathMap map[string]string
+
+	// destinations are the declared possible next nodes, nil when unknown
+	destinations []string
+}
+
+// AddConditionalEdges adds a conditional edge whose router can choose several next
+// nodes, which run in parallel in the next step, in the order returned by the router.
+// Returning no label ends this branch.
+//
+// When pathMap is not nil, the router returns labels that are mapped to node names,
+// e.g. {"search": "web_search", "done": END}; a label missing from the map fails the
+// run. The path map declares the possible destinations, so Compile can check them and
+// the Exporter draws every labeled branch.
+func (g *StateGraph) AddConditionalEdges(from string, router func(ctx context.Context, state interface{}) []string, pathMap map[string]string) {
+	g.addConditionalEdges(from, func(ctx context.Context, state interface{}) ([]string, error) {
+		return router(ctx, state), nil
+	}, pathMap)
+}
+
+func (g *StateGraph) addConditionalEdges(from string, route func(ctx context.Context, state interface{}) ([]string, error), pathMap map[string]string) {
+	edge := conditionalEdge{route: route}
+	if pathMap != nil {
+		edge.pathMap = pathMap
+		for _, label := range sortedKeys(pathMap) {
+			edge.destinations = appendUnique(edge.destinations, pathMap[label])
+		}
+	}
+	g.conditionalEdges[from] = edge
+}
+
+// next returns the nodes the edge leads to from the given state
+func (e conditionalEdge) next(ctx context.Context, from string, state interface{}) ([]string, error) {
+	labels, err := e.route(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if label == "" {
+			return nil, fmt.Errorf("conditional edge returned empty next node from %s", from)
+		}
+		if e.pathMap == nil {
+			nodes = append(nodes, label)
+			continue
+		}
+		node, ok := e.pathMap[label]
+		if !ok {
+			return nil, fmt.Errorf("conditional edge from %s returned unknown label %q", from, label)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// branches returns the labeled branches of the edge sorted by label,
+// or nil when the edge has no path map
+func (e conditionalEdge) branches() [][2]string {
+	if e.pathMap == nil {
+		return nil
+	}
+	labels := sortedKeys(e.pathMap)
+	branches := make([][2]string, len(labels))
+	for i, label := range labels {
+		branches[i] = [2]string{label, e.pathMap[label]}
+	}
+	return branches
+}
+
+// appendUnique appends node to nodes unless it is already present
+func appendUnique(nodes []string, node string) []string {
+	for _, n := range nodes {
+		if n == node {
+			return nodes
+		}
+	}
+	return append(nodes, node)
+}
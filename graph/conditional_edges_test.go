@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tmc/langchaingo/llms"
 )
 
@@ -323,3 +325,122 @@ func TestConditionalEdges_ChainedConditions(t *testing.T) {
 		t.Errorf("Expected result -10, got %v", result)
 	}
 }
+
+// newRouterGraph routes "plan" to the tools listed in the "use" key of the state
+func newRouterGraph(t *testing.T) *graph.StateGraph {
+	g := graph.NewStateGraph()
+	schema := graph.NewMapSchema()
+	schema.RegisterReducer("calls", graph.AppendReducer)
+	g.SetSchema(schema)
+
+	g.AddNode("plan", "plan", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return map[string]interface{}{}, nil
+	})
+	for _, name := range []string{"web_search", "calculator"} {
+		name := name
+		g.AddNode(name, name, func(ctx context.Context, state interface{}) (interface{}, error) {
+			return map[string]interface{}{"calls": []string{name}}, nil
+		})
+		g.AddEdge(name, graph.END)
+	}
+	g.SetEntryPoint("plan")
+	g.AddConditionalEdges("plan", func(ctx context.Context, state interface{}) []string {
+		use, _ := state.(map[string]interface{})["use"].([]string)
+		return use
+	}, map[string]string{
+		"search": "web_search",
+		"math":   "calculator",
+		"done":   graph.END,
+	})
+	return g
+}
+
+func TestConditionalEdges_MultipleTargets(t *testing.T) {
+	t.Parallel()
+
+	runnable, err := newRouterGraph(t).Compile()
+	require.NoError(t, err)
+
+	// Both branches run in the next step, merged in the order returned by the router
+	res, err := runnable.Invoke(context.Background(), map[string]interface{}{"use": []string{"math", "search"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"calculator", "web_search"}, res.(map[string]interface{})["calls"])
+
+	res, err = runnable.Invoke(context.Background(), map[string]interface{}{"use": []string{"done"}})
+	require.NoError(t, err)
+	assert.Nil(t, res.(map[string]interface{})["calls"])
+
+	// No label ends the branch
+	_, err = runnable.Invoke(context.Background(), map[string]interface{}{"use": []string{}})
+	require.NoError(t, err)
+
+	_, err = runnable.Invoke(context.Background(), map[string]interface{}{"use": []string{"translate"}})
+	assert.ErrorContains(t, err, `conditional edge from plan returned unknown label "translate"`)
+}
+
+func TestConditionalEdges_PathMapValidation(t *testing.T) {
+	t.Parallel()
+
+	g := newRouterGraph(t)
+	g.SetStrictValidation(true)
+	_, err := g.Compile()
+	require.NoError(t, err)
+
+	g.AddConditionalEdges("plan", func(ctx context.Context, state interface{}) []string {
+		return nil
+	}, map[string]string{"search": "missing"})
+	_, err = g.Compile()
+	assert.ErrorIs(t, err, graph.ErrNodeNotFound)
+	assert.ErrorContains(t, err, "conditional edge plan -> missing")
+}
+
+func TestConditionalEdges_WithoutPathMap(t *testing.T) {
+	t.Parallel()
+
+	g := graph.NewStateGraph()
+	g.AddNode("a", "a", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return state, nil
+	})
+	g.AddNode("b", "b", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return state.(int) + 1, nil
+	})
+	g.SetEntryPoint("a")
+	g.AddConditionalEdges("a", func(ctx context.Context, state interface{}) []string {
+		return []string{"b"}
+	}, nil)
+	g.AddEdge("b", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+	res, err := runnable.Invoke(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res)
+}
+
+type routedState struct {
+	Route string   `json:"route"`
+	Seen  []string `json:"seen" reducer:"append"`
+}
+
+func TestConditionalEdges_TypedStateGraph(t *testing.T) {
+	t.Parallel()
+
+	g := graph.NewTypedStateGraph[routedState]()
+	g.AddNode("start", "start", func(ctx context.Context, state routedState) (routedState, error) {
+		return routedState{}, nil
+	})
+	g.AddNode("left", "left", func(ctx context.Context, state routedState) (routedState, error) {
+		return routedState{Seen: []string{"left"}}, nil
+	})
+	g.SetEntryPoint("start")
+	g.AddConditionalEdges("start", func(ctx context.Context, state routedState) []string {
+		return []string{state.Route}
+	}, map[string]string{"l": "left", "stop": graph.END})
+	g.AddEdge("left", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+	res, err := runnable.Invoke(context.Background(), routedState{Route: "l"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"left"}, res.Seen)
+}
@@ -1,6 +1,12 @@
 package graph
 
-import "context"
+import (
+	"context"
+	"log/slog"
+	"reflect"
+
+	"github.com/smallnest/langgraphgo/log"
+)
 
 type resumeValueKey struct{}
 
@@ -14,3 +20,126 @@ func WithResumeValue(ctx context.Context, value interface{}) context.Context {
 func GetResumeValue(ctx context.Context) interface{} {
 	return ctx.Value(resumeValueKey{})
 }
+
+type stepKey struct{}
+
+func init() {
+	log.RegisterContextAttrs(logAttrs)
+}
+
+// logAttrs returns the run ID, thread ID, step and node of ctx, which are added to
+// the records logged with it.
+func logAttrs(ctx context.Context) []slog.Attr {
+	var attrs []slog.Attr
+	if runID := GetRunID(ctx); runID != "" {
+		attrs = append(attrs, slog.String("run_id", runID))
+	}
+	if threadID, _ := threadConfig(GetConfig(ctx)); threadID != "" {
+		attrs = append(attrs, slog.String("thread_id", threadID))
+	}
+	if step := GetStep(ctx); step != 0 {
+		attrs = append(attrs, slog.Int("step", step))
+	}
+	if name := GetNodeName(ctx); name != "" {
+		attrs = append(attrs, slog.String("node", name))
+	}
+	return attrs
+}
+
+// withStep adds the current super-step index to the context.
+func withStep(ctx context.Context, step int) context.Context {
+	return context.WithValue(ctx, stepKey{}, step)
+}
+
+// GetStep retrieves the index of the super-step currently being executed.
+// Steps are counted from 1; it returns 0 when called outside of a graph run.
+func GetStep(ctx context.Context) int {
+	if step, ok := ctx.Value(stepKey{}).(int); ok {
+		return step
+	}
+	return 0
+}
+
+type nextNodesKey struct{}
+
+// withNextNodes adds the nodes scheduled for the following super-step to the context.
+func withNextNodes(ctx context.Context, nodes []string) context.Context {
+	return context.WithValue(ctx, nextNodesKey{}, nodes)
+}
+
+// GetNextNodes retrieves the nodes scheduled to run after the current super-step.
+// It is available to GraphCallbackHandler.OnGraphStep; an empty result means the run is about to finish.
+func GetNextNodes(ctx context.Context) []string {
+	nodes, _ := ctx.Value(nextNodesKey{}).([]string)
+	return nodes
+}
+
+type nodeNameKey struct{}
+
+// withNodeName adds the name of the node being executed to the context.
+func withNodeName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, nodeNameKey{}, name)
+}
+
+// GetNodeName retrieves the name of the node currently being executed.
+// It returns an empty string when called outside of a node.
+func GetNodeName(ctx context.Context) string {
+	name, _ := ctx.Value(nodeNameKey{}).(string)
+	return name
+}
+
+type runIDKey struct{}
+
+// withRunID adds the ID of the current graph run to the context.
+func withRunID(ctx context.Context, runID string) context.Context {
+	return context.WithValue(ctx, runIDKey{}, runID)
+}
+
+// GetRunID retrieves the ID of the graph run currently being executed,
+// the same ID that is passed to the chain callbacks.
+func GetRunID(ctx context.Context) string {
+	runID, _ := ctx.Value(runIDKey{}).(string)
+	return runID
+}
+
+type inheritedCallbacksKey struct{}
+
+// withInheritedCallbacks adds the callbacks notified of LLM calls and custom
+// events to the context. Unlike the run config, they are inherited by nested
+// runs such as subgraphs and agents invoked from a node, so tokens and custom
+// payloads reach the outermost stream.
+func withInheritedCallbacks(ctx context.Context, callbacks []CallbackHandler) context.Context {
+	if len(callbacks) == 0 {
+		return ctx
+	}
+
+	inherited := inheritedCallbacks(ctx)
+	merged := make([]CallbackHandler, 0, len(inherited)+len(callbacks))
+	merged = append(merged, inherited...)
+	for _, cb := range callbacks {
+		if !containsCallback(merged, cb) {
+			merged = append(merged, cb)
+		}
+	}
+	return context.WithValue(ctx, inheritedCallbacksKey{}, merged)
+}
+
+// inheritedCallbacks returns the callbacks notified of LLM calls and custom
+// events made from the context.
+func inheritedCallbacks(ctx context.Context) []CallbackHandler {
+	callbacks, _ := ctx.Value(inheritedCallbacksKey{}).([]CallbackHandler)
+	return callbacks
+}
+
+// containsCallback reports whether the same handler is already registered.
+func containsCallback(callbacks []CallbackHandler, cb CallbackHandler) bool {
+	if !reflect.TypeOf(cb).Comparable() {
+		return false
+	}
+	for _, existing := range callbacks {
+		if reflect.TypeOf(existing) == reflect.TypeOf(cb) && existing == cb {
+			return true
+		}
+	}
+	return false
+}
@@ -110,7 +110,8 @@ func TestLargeGraph(t *testing.T) {
 	}
 
 	start := time.Now()
-	result, err := runnable.Invoke(context.Background(), 0)
+	// A linear chain needs one super-step per node
+	result, err := runnable.InvokeWithConfig(context.Background(), 0, &graph.Config{RecursionLimit: nodeCount})
 	duration := time.Since(start)
 
 	if err != nil {
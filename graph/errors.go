@@ -8,6 +8,8 @@ type NodeInterrupt struct {
 	Node string
 	// Value is the data/query provided by the interrupt
 	Value interface{}
+	// Subgraph is the interrupt of the nested run when Node is a subgraph
+	Subgraph *GraphInterrupt
 }
 
 func (e *NodeInterrupt) Error() string {
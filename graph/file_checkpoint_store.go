@@ -0,0 +1,523 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fileStoreLockName    = ".lock"
+	fileStoreIndexName   = "index" // never ends in .json, so it cannot clash with a checkpoint
+	fileStoreExecPrefix  = "exec-"
+	fileStoreIDsName     = "ids"
+	fileStoreCheckpoints = ".json"
+)
+
+// FileCheckpointStore provides durable checkpoint storage in a directory.
+//
+// Every execution gets its own sub-directory holding one JSON file per checkpoint
+// and an index that keeps the checkpoints in version order. A file per checkpoint in
+// the ids directory holds the execution it belongs to, so a checkpoint is found
+// without scanning the executions:
+//
+//	<dir>/exec-<execution_id>/index
+//	<dir>/exec-<execution_id>/<checkpoint_id>.json
+//	<dir>/ids/<checkpoint_id>
+//
+// Files are written to a temporary file and renamed into place, so readers never
+// observe partial writes. Access to an execution is serialized with a lock file in
+// its directory, which makes it safe to share the directory between goroutines and
+// between processes, while executions are written concurrently.
+type FileCheckpointStore struct {
+	dir     string
+	mutexes sync.Map // directory -> *sync.RWMutex
+}
+
+// fileIndexEntry is a checkpoint reference kept in an execution index
+type fileIndexEntry struct {
+	ID        string    `json:"id"`
+	Version   int       `json:"version"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NewFileCheckpointStore creates a file-based checkpoint store rooted at dir.
+// The directory is created if it does not exist.
+func NewFileCheckpointStore(dir string) (*FileCheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	store := &FileCheckpointStore{dir: dir}
+	if err := store.buildIDIndex(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// buildIDIndex creates the ids directory of a store written without it, from the
+// indexes of the executions
+func (f *FileCheckpointStore) buildIDIndex() error {
+	idsDir := filepath.Join(f.dir, fileStoreIDsName)
+	if _, err := os.Stat(idsDir); err == nil {
+		return nil
+	}
+
+	unlock, err := f.lock(f.dir, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Another process may have built it meanwhile
+	if _, err := os.Stat(idsDir); err == nil {
+		return nil
+	}
+
+	// Built aside and renamed into place, so a partial index is never used
+	tmpDir, err := os.MkdirTemp(f.dir, ".tmp-ids-*")
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint id index: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint id index: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), fileStoreExecPrefix) {
+			continue
+		}
+		executionID, err := url.PathUnescape(strings.TrimPrefix(entry.Name(), fileStoreExecPrefix))
+		if err != nil {
+			continue
+		}
+		index, err := readFileIndex(filepath.Join(f.dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		for _, checkpoint := range index {
+			if err := os.WriteFile(filepath.Join(tmpDir, url.PathEscape(checkpoint.ID)), []byte(executionID), 0o644); err != nil {
+				return fmt.Errorf("failed to create checkpoint id index: %w", err)
+			}
+		}
+	}
+
+	if err := os.Rename(tmpDir, idsDir); err != nil {
+		return fmt.Errorf("failed to create checkpoint id index: %w", err)
+	}
+	return nil
+}
+
+// Dir returns the directory the store writes to
+func (f *FileCheckpointStore) Dir() string {
+	return f.dir
+}
+
+// Save implements CheckpointStore interface for file storage
+func (f *FileCheckpointStore) Save(_ context.Context, checkpoint *Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	executionID := checkpointExecutionID(checkpoint)
+
+	// A checkpoint that moved to another execution must leave its old index
+	previous, ok, err := f.checkpointExecution(checkpoint.ID)
+	if err != nil {
+		return err
+	}
+	if ok && previous != executionID {
+		if err := f.deleteFromExecution(previous, checkpoint.ID); err != nil {
+			return err
+		}
+	}
+
+	execDir := f.executionDir(executionID)
+	unlock, err := f.lock(execDir, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := writeFileAtomic(filepath.Join(execDir, checkpointFileName(checkpoint.ID)), data); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	index, err := readFileIndex(execDir)
+	if err != nil {
+		return err
+	}
+
+	entry := fileIndexEntry{ID: checkpoint.ID, Version: checkpoint.Version, Timestamp: checkpoint.Timestamp}
+	replaced := false
+	for i := range index {
+		if index[i].ID == checkpoint.ID {
+			index[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		index = append(index, entry)
+	}
+
+	if err := writeFileIndex(execDir, index); err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(f.idPath(checkpoint.ID), []byte(executionID)); err != nil {
+		return fmt.Errorf("failed to write checkpoint id index: %w", err)
+	}
+	return nil
+}
+
+// Load implements CheckpointStore interface for file storage
+func (f *FileCheckpointStore) Load(_ context.Context, checkpointID string) (*Checkpoint, error) {
+	executionID, ok, err := f.checkpointExecution(checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("checkpoint not found: %s", checkpointID)
+	}
+
+	execDir := f.executionDir(executionID)
+	unlock, err := f.lock(execDir, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return readCheckpointFile(filepath.Join(execDir, checkpointFileName(checkpointID)))
+}
+
+// List implements CheckpointStore interface for file storage
+func (f *FileCheckpointStore) List(_ context.Context, executionID string) ([]*Checkpoint, error) {
+	execDir := f.executionDir(executionID)
+	unlock, err := f.lock(execDir, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	index, err := readFileIndex(execDir)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoints := make([]*Checkpoint, 0, len(index))
+	for _, entry := range index {
+		checkpoint, err := readCheckpointFile(filepath.Join(execDir, checkpointFileName(entry.ID)))
+		if err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+
+	return checkpoints, nil
+}
+
+// Delete implements CheckpointStore interface for file storage
+func (f *FileCheckpointStore) Delete(_ context.Context, checkpointID string) error {
+	executionID, ok, err := f.checkpointExecution(checkpointID)
+	if err != nil || !ok {
+		// Deleting a missing checkpoint is not an error
+		return err
+	}
+
+	return f.deleteFromExecution(executionID, checkpointID)
+}
+
+// Clear implements CheckpointStore interface for file storage
+func (f *FileCheckpointStore) Clear(_ context.Context, executionID string) error {
+	execDir := f.executionDir(executionID)
+	if _, err := os.Stat(execDir); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	unlock, err := f.lock(execDir, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	index, err := readFileIndex(execDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range index {
+		if err := f.removeID(executionID, entry.ID); err != nil {
+			return err
+		}
+	}
+
+	// The lock file stays, as other stores may be waiting on it
+	files, err := os.ReadDir(execDir)
+	if err != nil {
+		return fmt.Errorf("failed to clear checkpoints: %w", err)
+	}
+	for _, file := range files {
+		if file.Name() == fileStoreLockName {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(execDir, file.Name())); err != nil {
+			return fmt.Errorf("failed to clear checkpoints: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// deleteFromExecution removes a checkpoint from an execution
+func (f *FileCheckpointStore) deleteFromExecution(executionID, checkpointID string) error {
+	execDir := f.executionDir(executionID)
+	unlock, err := f.lock(execDir, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := f.removeCheckpoint(execDir, checkpointID); err != nil {
+		return err
+	}
+	return f.removeID(executionID, checkpointID)
+}
+
+// lock acquires the in-process lock and the lock file of dir, an execution directory
+// or the store directory. Readers share the lock; writers hold it exclusively and
+// create the directory.
+func (f *FileCheckpointStore) lock(dir string, exclusive bool) (func(), error) {
+	value, _ := f.mutexes.LoadOrStore(dir, &sync.RWMutex{})
+	mutex := value.(*sync.RWMutex)
+	if exclusive {
+		mutex.Lock()
+	} else {
+		mutex.RLock()
+	}
+	unlockMutex := func() {
+		if exclusive {
+			mutex.Unlock()
+		} else {
+			mutex.RUnlock()
+		}
+	}
+
+	if exclusive {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			unlockMutex()
+			return nil, fmt.Errorf("failed to create execution directory: %w", err)
+		}
+	}
+
+	lockFile, err := os.OpenFile(filepath.Join(dir, fileStoreLockName), os.O_CREATE|os.O_RDWR, 0o644)
+	if !exclusive && errors.Is(err, fs.ErrNotExist) {
+		// Nothing to read in a missing directory
+		return unlockMutex, nil
+	}
+	if err != nil {
+		unlockMutex()
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := lockFileHandle(lockFile, exclusive); err != nil {
+		lockFile.Close()
+		unlockMutex()
+		return nil, fmt.Errorf("failed to lock checkpoint directory: %w", err)
+	}
+
+	return func() {
+		_ = unlockFileHandle(lockFile)
+		lockFile.Close()
+		unlockMutex()
+	}, nil
+}
+
+// executionDir returns the directory holding the checkpoints of an execution
+func (f *FileCheckpointStore) executionDir(executionID string) string {
+	return filepath.Join(f.dir, fileStoreExecPrefix+url.PathEscape(executionID))
+}
+
+// idPath returns the path of the file holding the execution of a checkpoint
+func (f *FileCheckpointStore) idPath(checkpointID string) string {
+	return filepath.Join(f.dir, fileStoreIDsName, url.PathEscape(checkpointID))
+}
+
+// checkpointExecution returns the execution a checkpoint belongs to, and false when
+// the checkpoint is not stored
+func (f *FileCheckpointStore) checkpointExecution(checkpointID string) (string, bool, error) {
+	data, err := os.ReadFile(f.idPath(checkpointID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to find checkpoint: %w", err)
+	}
+	return string(data), true, nil
+}
+
+// removeID removes the execution of a checkpoint from the id index, unless the
+// checkpoint was saved to another execution since
+func (f *FileCheckpointStore) removeID(executionID, checkpointID string) error {
+	current, ok, err := f.checkpointExecution(checkpointID)
+	if err != nil || !ok || current != executionID {
+		return err
+	}
+	if err := os.Remove(f.idPath(checkpointID)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+	return nil
+}
+
+// removeCheckpoint deletes a checkpoint file and its index entry
+func (f *FileCheckpointStore) removeCheckpoint(execDir, checkpointID string) error {
+	if err := os.Remove(filepath.Join(execDir, checkpointFileName(checkpointID))); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+
+	index, err := readFileIndex(execDir)
+	if err != nil {
+		return err
+	}
+
+	kept := index[:0]
+	for _, entry := range index {
+		if entry.ID != checkpointID {
+			kept = append(kept, entry)
+		}
+	}
+
+	return writeFileIndex(execDir, kept)
+}
+
+// checkpointExecutionID returns the execution a checkpoint belongs to
+func checkpointExecutionID(checkpoint *Checkpoint) string {
+	executionID, _ := checkpoint.Metadata["execution_id"].(string)
+	return executionID
+}
+
+func checkpointFileName(checkpointID string) string {
+	return url.PathEscape(checkpointID) + fileStoreCheckpoints
+}
+
+func readCheckpointFile(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			id, _ := url.PathUnescape(strings.TrimSuffix(filepath.Base(path), fileStoreCheckpoints))
+			return nil, fmt.Errorf("checkpoint not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal checkpoint: %w", err)
+	}
+
+	return &checkpoint, nil
+}
+
+// readFileIndex reads the index of an execution directory, rebuilding it from the
+// checkpoint files when it is missing
+func readFileIndex(execDir string) ([]fileIndexEntry, error) {
+	data, err := os.ReadFile(filepath.Join(execDir, fileStoreIndexName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return rebuildFileIndex(execDir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint index: %w", err)
+	}
+
+	var index []fileIndexEntry
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal checkpoint index: %w", err)
+	}
+
+	return index, nil
+}
+
+func rebuildFileIndex(execDir string) ([]fileIndexEntry, error) {
+	files, err := os.ReadDir(execDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild checkpoint index: %w", err)
+	}
+
+	var index []fileIndexEntry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), fileStoreCheckpoints) {
+			continue
+		}
+		checkpoint, err := readCheckpointFile(filepath.Join(execDir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		index = append(index, fileIndexEntry{ID: checkpoint.ID, Version: checkpoint.Version, Timestamp: checkpoint.Timestamp})
+	}
+
+	// Files are listed by name; restore version order
+	sort.SliceStable(index, func(i, j int) bool {
+		if index[i].Version != index[j].Version {
+			return index[i].Version < index[j].Version
+		}
+		return index[i].Timestamp.Before(index[j].Timestamp)
+	})
+
+	return index, nil
+}
+
+func writeFileIndex(execDir string, index []fileIndexEntry) error {
+	// Sort by version (ascending order) so latest is last
+	sort.SliceStable(index, func(i, j int) bool {
+		return index[i].Version < index[j].Version
+	})
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint index: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(execDir, fileStoreIndexName), data); err != nil {
+		return fmt.Errorf("failed to write checkpoint index: %w", err)
+	}
+
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,16 @@
+//go:build !unix && !windows
+
+package graph
+
+import "os"
+
+// lockFileHandle is a no-op on platforms without file locking;
+// FileCheckpointStore then only serializes access within the process.
+func lockFileHandle(_ *os.File, _ bool) error {
+	return nil
+}
+
+// unlockFileHandle is a no-op on platforms without file locking
+func unlockFileHandle(_ *os.File) error {
+	return nil
+}
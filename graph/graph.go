@@ -4,11 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 )
 
 // END is a special constant used to represent the end node in the graph.
 const END = "END"
 
+// DefaultRecursionLimit is the maximum number of super-steps a graph may execute
+// when Config.RecursionLimit is not set.
+const DefaultRecursionLimit = 25
+
 var (
 	// ErrEntryPointNotSet is returned when the entry point of the graph is not set.
 	ErrEntryPointNotSet = errors.New("entry point not set")
@@ -30,6 +35,9 @@ type GraphInterrupt struct {
 	NextNodes []string
 	// InterruptValue is the value provided by the dynamic interrupt (if any)
 	InterruptValue interface{}
+	// Subgraph is the interrupt raised inside Node when Node is a subgraph.
+	// It identifies the nested node that interrupted the run.
+	Subgraph *GraphInterrupt
 }
 
 func (e *GraphInterrupt) Error() string {
@@ -39,6 +47,46 @@ func (e *GraphInterrupt) Error() string {
 	return fmt.Sprintf("graph interrupted at node %s", e.Node)
 }
 
+// GraphRecursionError is returned when the graph reaches its recursion limit
+// before reaching END. It carries the last merged state and the pending nodes
+// so the caller can inspect the run or resume it with Config.ResumeFrom.
+type GraphRecursionError struct {
+	// Limit is the recursion limit that was reached
+	Limit int
+	// State at the time the limit was reached
+	State interface{}
+	// NextNodes that would have been executed if the limit had not been reached
+	NextNodes []string
+}
+
+func (e *GraphRecursionError) Error() string {
+	return fmt.Sprintf("recursion limit of %d reached without hitting a stop condition, pending nodes: %v", e.Limit, e.NextNodes)
+}
+
+// GraphTimeoutError is returned when the run exceeds Config.Timeout.
+// It carries the last merged state, the nodes that were running (or about to run)
+// when the deadline was reached and the step number, so the run can be resumed
+// with Config.ResumeFrom.
+type GraphTimeoutError struct {
+	// Timeout is the configured run timeout
+	Timeout time.Duration
+	// State is the last merged state before the deadline was reached
+	State interface{}
+	// RunningNodes are the nodes that were running when the deadline was reached
+	RunningNodes []string
+	// Step is the super-step during which the deadline was reached
+	Step int
+}
+
+func (e *GraphTimeoutError) Error() string {
+	return fmt.Sprintf("graph execution timed out after %v at step %d, running nodes: %v", e.Timeout, e.Step, e.RunningNodes)
+}
+
+// Unwrap allows errors.Is(err, context.DeadlineExceeded) to match a GraphTimeoutError
+func (e *GraphTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
 // Interrupt pauses execution and waits for input.
 // If resuming, it returns the value provided in the resume command.
 func Interrupt(ctx context.Context, value interface{}) (interface{}, error) {
@@ -59,6 +107,9 @@ type Node struct {
 	// Function is the function associated with the node.
 	// It takes a context and any state as input and returns the updated state and an error.
 	Function func(ctx context.Context, state interface{}) (interface{}, error)
+
+	// policy holds the execution policies set with NodeOptions, nil when there is none
+	policy *nodePolicy
 }
 
 // Edge represents an edge in the graph.
@@ -86,7 +137,7 @@ type Runnable = StateRunnable
 func NewMessageGraph() *StateGraph {
 	g := &StateGraph{
 		nodes:            make(map[string]Node),
-		conditionalEdges: make(map[string]func(ctx context.Context, state interface{}) string),
+		conditionalEdges: make(map[string]conditionalEdge),
 	}
 
 	// Initialize default schema for message handling
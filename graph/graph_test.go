@@ -116,7 +116,8 @@ func TestStateGraph(t *testing.T) {
 				g.SetEntryPoint("node1")
 				return g
 			},
-			expectedError: fmt.Errorf("%w: node2", graph.ErrNodeNotFound),
+			// Reported by the validation done in Compile
+			expectedError: graph.ErrNodeNotFound,
 		},
 		{
 			name: "No outgoing edge",
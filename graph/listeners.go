@@ -2,7 +2,6 @@ package graph
 
 import (
 	"context"
-	"fmt"
 	"sync"
 	"time"
 )
@@ -23,6 +22,18 @@ const (
 	// NodeEventError indicates a node encountered an error
 	NodeEventError NodeEvent = "error"
 
+	// NodeEventRetry indicates a failed node attempt will be retried; the event
+	// carries the node input and the error of the attempt
+	NodeEventRetry NodeEvent = "retry"
+
+	// NodeEventCacheHit indicates the node result was read from its cache
+	// instead of running the node; the event carries the node input
+	NodeEventCacheHit NodeEvent = "cache_hit"
+
+	// NodeEventCacheMiss indicates the node result was not cached and the node runs;
+	// the event carries the node input
+	NodeEventCacheMiss NodeEvent = "cache_miss"
+
 	// EventChainStart indicates the graph execution has started
 	EventChainStart NodeEvent = "chain_start"
 
@@ -198,8 +209,9 @@ func NewListenableStateGraph() *ListenableStateGraph {
 	}
 }
 
-// AddNode adds a node with listener capabilities
-func (g *ListenableStateGraph) AddNode(name string, description string, fn func(ctx context.Context, state interface{}) (interface{}, error)) *ListenableNode {
+// AddNode adds a node with listener capabilities.
+// Options set execution policies of the node; see StateGraph.AddNode.
+func (g *ListenableStateGraph) AddNode(name string, description string, fn func(ctx context.Context, state interface{}) (interface{}, error), opts ...NodeOption) *ListenableNode {
 	node := Node{
 		Name:        name,
 		Description: description,
@@ -209,7 +221,7 @@ func (g *ListenableStateGraph) AddNode(name string, description string, fn func(
 	listenableNode := NewListenableNode(node)
 
 	// Add to both the base graph and our listenable nodes map
-	g.StateGraph.AddNode(name, description, fn)
+	g.StateGraph.AddNode(name, description, fn, opts...)
 	g.listenableNodes[name] = listenableNode
 
 	return listenableNode
@@ -238,18 +250,31 @@ func (g *ListenableStateGraph) RemoveGlobalListener(listener NodeListener) {
 type ListenableRunnable struct {
 	graph           *ListenableStateGraph
 	listenableNodes map[string]*ListenableNode
+	runnable        *StateRunnable
 }
 
 // CompileListenable creates a runnable with listener support
 func (g *ListenableStateGraph) CompileListenable() (*ListenableRunnable, error) {
-	if g.entryPoint == "" {
-		return nil, ErrEntryPointNotSet
+	if err := g.validateForCompile(); err != nil {
+		return nil, err
 	}
 
-	return &ListenableRunnable{
+	lr := &ListenableRunnable{
 		graph:           g,
 		listenableNodes: g.listenableNodes,
-	}, nil
+	}
+
+	// Execute on the StateRunnable engine, notifying listeners around each node. The
+	// engine replaces the former ListenableRunnable loop and handles the run: edges,
+	// interrupts, checkpoints and the Config.Timeout deadline, which stops waiting for
+	// a node that ignores ctx
+	lr.runnable = &StateRunnable{
+		graph:         g.StateGraph,
+		nodeExecutor:  lr.executeNode,
+		eventNotifier: lr.notifyPolicyEvent,
+	}
+
+	return lr, nil
 }
 
 // Invoke executes the graph with listener notifications
@@ -259,79 +284,28 @@ func (lr *ListenableRunnable) Invoke(ctx context.Context, initialState interface
 
 // InvokeWithConfig executes the graph with listener notifications and config
 func (lr *ListenableRunnable) InvokeWithConfig(ctx context.Context, initialState interface{}, config *Config) (interface{}, error) {
-	if config != nil {
-		ctx = WithConfig(ctx, config)
-	}
+	return lr.runnable.InvokeWithConfig(ctx, initialState, config)
+}
 
-	state := initialState
-	currentNode := lr.graph.entryPoint
+// SetTracer sets a tracer for observability
+func (lr *ListenableRunnable) SetTracer(tracer *Tracer) {
+	lr.runnable.SetTracer(tracer)
+}
 
-	// Check if we should resume from a specific node
-	if config != nil && len(config.ResumeFrom) > 0 {
-		// For now, we only support resuming from a single node (the first one in the list)
-		currentNode = config.ResumeFrom[0]
+// executeNode runs a node through its ListenableNode so that listeners are notified.
+// It only runs the node: StateRunnable applies its policies and the run timeout.
+func (lr *ListenableRunnable) executeNode(ctx context.Context, node Node, state interface{}) (interface{}, error) {
+	if listenableNode, ok := lr.listenableNodes[node.Name]; ok {
+		return listenableNode.Execute(ctx, state)
 	}
+	return node.Function(ctx, state)
+}
 
-	for {
-		if currentNode == END {
-			break
-		}
-
-		listenableNode, ok := lr.listenableNodes[currentNode]
-		if !ok {
-			return nil, ErrNodeNotFound
-		}
-
-		// Execute the node function
-		result, err := listenableNode.Execute(ctx, state)
-		if err != nil {
-			return nil, err
-		}
-
-		// Update state using Schema if available
-		if lr.graph.Schema != nil {
-			state, err = lr.graph.Schema.Update(state, result)
-			if err != nil {
-				return nil, fmt.Errorf("schema update failed: %w", err)
-			}
-		} else {
-			// Default behavior: replace state
-			state = result
-		}
-
-		// Find next node
-		var nextNode string
-		foundNext := false
-		for _, edge := range lr.graph.edges {
-			if edge.From == currentNode {
-				nextNode = edge.To
-				foundNext = true
-				break
-			}
-		}
-
-		if !foundNext {
-			return nil, ErrNoOutgoingEdge
-		}
-
-		// Cleanup ephemeral state if supported
-		if cleaningSchema, ok := lr.graph.Schema.(CleaningStateSchema); ok {
-			state = cleaningSchema.Cleanup(state)
-		}
-
-		// Notify callbacks of step completion
-		if config != nil && len(config.Callbacks) > 0 {
-			for _, cb := range config.Callbacks {
-				if gcb, ok := cb.(GraphCallbackHandler); ok {
-					gcb.OnGraphStep(ctx, currentNode, state)
-				}
-			}
-		}
-
-		currentNode = nextNode
+// notifyPolicyEvent notifies the listeners of a node of an event raised by its policies
+func (lr *ListenableRunnable) notifyPolicyEvent(ctx context.Context, name string, event NodeEvent, state interface{}, err error) {
+	if listenableNode, ok := lr.listenableNodes[name]; ok {
+		listenableNode.NotifyListeners(ctx, event, state, err)
 	}
-
-	return state, nil
 }
 
 // GetGraph returns a Exporter for visualization
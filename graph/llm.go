@@ -0,0 +1,186 @@
+package graph
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// LLMTokenHandler is implemented by callback handlers that receive the tokens of
+// LLM responses while they are generated, e.g. StreamingListener.
+type LLMTokenHandler interface {
+	// OnLLMToken is called for each chunk of a response. runID is the ID of the
+	// graph run and messageID identifies the LLM call producing the message.
+	OnLLMToken(ctx context.Context, chunk []byte, runID string, messageID string)
+}
+
+// GenerateContent calls model.GenerateContent from a node and reports the call to the
+// callbacks of the current run (and of the runs it is nested in).
+//
+// OnLLMStart and OnLLMEnd or OnLLMError are called around the model call. When one of the
+// callbacks implements LLMTokenHandler, the response is requested with llms.WithStreamingFunc
+// and every chunk is forwarded to it, tagged with the run ID and a message ID; the node name
+// is available through GetNodeName. A streaming function passed in options still receives
+// the chunks. Outside of a graph run it behaves like model.GenerateContent.
+//
+// When the run has a tracer, the call is traced in a span nested in the span of the node,
+// with the token usage reported by the model.
+func GenerateContent(ctx context.Context, model llms.Model, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	tracer := activeTracer(ctx)
+	if tracer == nil {
+		return generateContent(ctx, model, messages, options...)
+	}
+
+	span := tracer.StartSpan(ctx, TraceEventLLMStart, GetNodeName(ctx))
+	ctx = tracer.contextWithSpan(ctx, span)
+
+	resp, err := generateContent(ctx, model, messages, options...)
+	for key, tokens := range tokenUsage(resp) {
+		span.Metadata[key] = tokens
+	}
+	tracer.EndSpan(ctx, span, nil, err)
+	return resp, err
+}
+
+// generateContent calls the model, reporting the call to the callbacks of the run
+func generateContent(ctx context.Context, model llms.Model, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	callbacks := inheritedCallbacks(ctx)
+	if len(callbacks) == 0 {
+		return model.GenerateContent(ctx, messages, options...)
+	}
+
+	runID := GetRunID(ctx)
+	messageID := generateRunID()
+
+	var tokenHandlers []LLMTokenHandler
+	for _, cb := range callbacks {
+		if th, ok := cb.(LLMTokenHandler); ok {
+			tokenHandlers = append(tokenHandlers, th)
+		}
+	}
+
+	if len(tokenHandlers) > 0 {
+		var callOpts llms.CallOptions
+		for _, opt := range options {
+			opt(&callOpts)
+		}
+		userStreamingFunc := callOpts.StreamingFunc
+
+		options = append(options, llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+			for _, th := range tokenHandlers {
+				th.OnLLMToken(ctx, chunk, runID, messageID)
+			}
+			if userStreamingFunc != nil {
+				return userStreamingFunc(ctx, chunk)
+			}
+			return nil
+		}))
+	}
+
+	var tags []string
+	if config := GetConfig(ctx); config != nil {
+		tags = config.Tags
+	}
+	serialized := map[string]interface{}{
+		"name": GetNodeName(ctx),
+		"type": "llm",
+	}
+	metadata := map[string]interface{}{
+		"node":       GetNodeName(ctx),
+		"run_id":     runID,
+		"message_id": messageID,
+	}
+	prompts := messagePrompts(messages)
+
+	for _, cb := range callbacks {
+		cb.OnLLMStart(ctx, serialized, prompts, messageID, &runID, tags, metadata)
+	}
+
+	resp, err := model.GenerateContent(ctx, messages, options...)
+
+	for _, cb := range callbacks {
+		if err != nil {
+			cb.OnLLMError(ctx, err, messageID)
+		} else {
+			cb.OnLLMEnd(ctx, resp, messageID)
+		}
+	}
+
+	return resp, err
+}
+
+// messagePrompts converts messages to the prompt strings passed to OnLLMStart
+func messagePrompts(messages []llms.MessageContent) []string {
+	prompts := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		var text strings.Builder
+		for _, part := range msg.Parts {
+			if tc, ok := part.(llms.TextContent); ok {
+				text.WriteString(tc.Text)
+			}
+		}
+		prompts = append(prompts, string(msg.Role)+": "+text.String())
+	}
+	return prompts
+}
+
+// tokenUsageKeys maps the generation info keys used by the providers for token counts
+// to the span metadata keys
+var tokenUsageKeys = map[string]string{
+	"PromptTokens":      "prompt_tokens",
+	"prompt_tokens":     "prompt_tokens",
+	"InputTokens":       "prompt_tokens",
+	"input_tokens":      "prompt_tokens",
+	"CompletionTokens":  "completion_tokens",
+	"completion_tokens": "completion_tokens",
+	"OutputTokens":      "completion_tokens",
+	"output_tokens":     "completion_tokens",
+	"TotalTokens":       "total_tokens",
+	"total_tokens":      "total_tokens",
+}
+
+// tokenUsage returns the token counts reported in the generation info of the
+// response. Providers report the usage of the whole response on each choice, so the
+// first choice reporting it is used.
+func tokenUsage(resp *llms.ContentResponse) map[string]int {
+	if resp == nil {
+		return nil
+	}
+	for _, choice := range resp.Choices {
+		usage := make(map[string]int)
+		for key, value := range choice.GenerationInfo {
+			name, ok := tokenUsageKeys[key]
+			if !ok {
+				continue
+			}
+			if tokens, ok := tokenCount(value); ok {
+				usage[name] = tokens
+			}
+		}
+		if len(usage) == 0 {
+			continue
+		}
+		if _, ok := usage["total_tokens"]; !ok {
+			usage["total_tokens"] = usage["prompt_tokens"] + usage["completion_tokens"]
+		}
+		return usage
+	}
+	return nil
+}
+
+// tokenCount converts a token count of the generation info to an int
+func tokenCount(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,208 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// streamingLLM streams its response in fixed chunks when a streaming function is set
+type streamingLLM struct {
+	chunks    []string
+	err       error
+	streaming bool
+}
+
+func (m *streamingLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	m.streaming = opts.StreamingFunc != nil
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	content := ""
+	for _, chunk := range m.chunks {
+		content += chunk
+		if opts.StreamingFunc != nil {
+			if err := opts.StreamingFunc(ctx, []byte(chunk)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: content}}}, nil
+}
+
+func (m *streamingLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return "", nil
+}
+
+func collectEvents(ch chan graph.StreamEvent) []graph.StreamEvent {
+	close(ch)
+	var events []graph.StreamEvent
+	for event := range ch {
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestGenerateContent_StreamsTokens(t *testing.T) {
+	model := &streamingLLM{chunks: []string{"Hel", "lo", "!"}}
+
+	g := graph.NewStateGraph()
+	g.AddNode("chat", "chat", func(ctx context.Context, state interface{}) (interface{}, error) {
+		resp, err := graph.GenerateContent(ctx, model, []llms.MessageContent{
+			llms.TextParts(llms.ChatMessageTypeHuman, state.(string)),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return resp.Choices[0].Content, nil
+	})
+	g.SetEntryPoint("chat")
+	g.AddEdge("chat", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	events := make(chan graph.StreamEvent, 100)
+	listener := graph.NewStreamingListener(events, graph.StreamConfig{Mode: graph.StreamModeMessages})
+
+	res, err := runnable.InvokeWithConfig(context.Background(), "hi", &graph.Config{
+		Callbacks: []graph.CallbackHandler{listener},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello!", res)
+	assert.True(t, model.streaming)
+
+	collected := collectEvents(events)
+	require.Len(t, collected, 5)
+	assert.Equal(t, graph.EventLLMStart, collected[0].Event)
+	assert.Equal(t, graph.EventLLMEnd, collected[4].Event)
+
+	var tokens string
+	messageID := collected[1].Metadata["message_id"]
+	assert.NotEmpty(t, messageID)
+	for _, event := range collected[1:4] {
+		assert.Equal(t, graph.EventToken, event.Event)
+		assert.Equal(t, "chat", event.NodeName)
+		assert.NotEmpty(t, event.Metadata["run_id"])
+		assert.Equal(t, messageID, event.Metadata["message_id"])
+		tokens += event.State.(string)
+	}
+	assert.Equal(t, "Hello!", tokens)
+	assert.Equal(t, messageID, collected[4].Metadata["message_id"])
+}
+
+func TestGenerateContent_NestedRunsInheritCallbacks(t *testing.T) {
+	model := &streamingLLM{chunks: []string{"a", "b"}}
+
+	inner := graph.NewStateGraph()
+	inner.AddNode("inner_llm", "inner", func(ctx context.Context, state interface{}) (interface{}, error) {
+		_, err := graph.GenerateContent(ctx, model, nil)
+		return state, err
+	})
+	inner.SetEntryPoint("inner_llm")
+	inner.AddEdge("inner_llm", graph.END)
+	innerRunnable, err := inner.Compile()
+	require.NoError(t, err)
+
+	events := make(chan graph.StreamEvent, 100)
+	listener := graph.NewStreamingListener(events, graph.StreamConfig{Mode: graph.StreamModeMessages})
+
+	var innerConfigs []*graph.Config
+	outer := graph.NewStateGraph()
+	outer.AddNode("call_inner", "outer", func(ctx context.Context, state interface{}) (interface{}, error) {
+		// The first nested run has its own config without callbacks,
+		// the second one passes the parent callbacks again
+		for _, config := range innerConfigs {
+			if _, err := innerRunnable.InvokeWithConfig(ctx, state, config); err != nil {
+				return nil, err
+			}
+		}
+		return state, nil
+	})
+	outer.SetEntryPoint("call_inner")
+	outer.AddEdge("call_inner", graph.END)
+	outerRunnable, err := outer.Compile()
+	require.NoError(t, err)
+
+	innerConfigs = []*graph.Config{
+		{RecursionLimit: 5},
+		{Callbacks: []graph.CallbackHandler{listener}},
+	}
+	_, err = outerRunnable.InvokeWithConfig(context.Background(), "x", &graph.Config{
+		Callbacks: []graph.CallbackHandler{listener},
+	})
+	require.NoError(t, err)
+
+	var tokens []string
+	for _, event := range collectEvents(events) {
+		if event.Event == graph.EventToken {
+			assert.Equal(t, "inner_llm", event.NodeName)
+			tokens = append(tokens, event.State.(string))
+		}
+	}
+	// Handlers inherited from the parent run are not notified twice
+	assert.Equal(t, []string{"a", "b", "a", "b"}, tokens)
+}
+
+func TestGenerateContent_WithoutStreaming(t *testing.T) {
+	model := &streamingLLM{chunks: []string{"ok"}}
+
+	// Outside of a graph run the model is called as-is
+	resp, err := graph.GenerateContent(context.Background(), model, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Choices[0].Content)
+	assert.False(t, model.streaming)
+
+	// A user streaming function still receives the chunks
+	var mu sync.Mutex
+	var chunks []string
+	_, err = graph.GenerateContent(context.Background(), model, nil, llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		chunks = append(chunks, string(chunk))
+		return nil
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ok"}, chunks)
+}
+
+func TestGenerateContent_ReportsErrors(t *testing.T) {
+	model := &streamingLLM{err: errors.New("rate limited")}
+
+	g := graph.NewStateGraph()
+	g.AddNode("chat", "chat", func(ctx context.Context, state interface{}) (interface{}, error) {
+		_, err := graph.GenerateContent(ctx, model, nil)
+		return nil, err
+	})
+	g.SetEntryPoint("chat")
+	g.AddEdge("chat", graph.END)
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	events := make(chan graph.StreamEvent, 100)
+	listener := graph.NewStreamingListener(events, graph.StreamConfig{Mode: graph.StreamModeDebug})
+	_, err = runnable.InvokeWithConfig(context.Background(), nil, &graph.Config{
+		Callbacks: []graph.CallbackHandler{listener},
+	})
+	require.Error(t, err)
+
+	var llmErrors int
+	for _, event := range collectEvents(events) {
+		if event.Event == graph.NodeEventError && event.Error != nil && event.Error.Error() == "rate limited" {
+			llmErrors++
+		}
+	}
+	assert.Equal(t, 1, llmErrors)
+}
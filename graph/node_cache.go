@@ -0,0 +1,254 @@
+package graph
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// NodeCache stores node results by key, see CachePolicy
+type NodeCache interface {
+	// Get returns the value stored for key, and false when there is none or it expired
+	Get(ctx context.Context, key string) (interface{}, bool, error)
+
+	// Set stores value for key; a zero ttl never expires
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+
+	// Clear removes all the values
+	Clear(ctx context.Context) error
+}
+
+// CachePolicy caches the results of a node keyed on its input state, so that a
+// deterministic node does not run again for an input it has already seen, including
+// when a run is resumed. Errors are not cached.
+type CachePolicy struct {
+	// Cache stores the results
+	Cache NodeCache
+
+	// KeyFunc computes the cache key of an input state; the node name is added to it,
+	// so a cache can be shared by several nodes. Defaults to DefaultCacheKey.
+	KeyFunc func(state interface{}) (string, error)
+
+	// TTL is how long results are kept; zero keeps them until they are evicted
+	TTL time.Duration
+
+	// Decode converts a cached value back to the type the node returns. Caches that
+	// serialize values, like the SQLite cache, return generic JSON values, so a
+	// struct state comes back as a map without it; see DecodeAs.
+	Decode func(value interface{}) (interface{}, error)
+}
+
+// DecodeAs returns a CachePolicy.Decode function converting cached values to S
+func DecodeAs[S any]() func(value interface{}) (interface{}, error) {
+	return func(value interface{}) (interface{}, error) {
+		return AsState[S](value)
+	}
+}
+
+// WithCache caches the results of the node as configured by policy.
+// A cached result is returned without applying the other policies of the node.
+func WithCache(policy CachePolicy) NodeOption {
+	return func(p *nodePolicy) {
+		p.cache = &policy
+	}
+}
+
+// DefaultCacheKey returns the SHA-256 of the JSON encoding of state
+func DefaultCacheKey(state interface{}) (string, error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal state for cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cached returns the cached result of the node for state, or calls fn and caches its result
+func (c *CachePolicy) cached(ctx context.Context, name string, state interface{}, fn func(context.Context, interface{}) (interface{}, error)) (interface{}, error) {
+	keyFunc := c.KeyFunc
+	if keyFunc == nil {
+		keyFunc = DefaultCacheKey
+	}
+	key, err := keyFunc(state)
+	if err != nil {
+		return nil, fmt.Errorf("cache key of node %s: %w", name, err)
+	}
+	key = name + ":" + key
+	metadata := map[string]interface{}{"key": key}
+
+	value, ok, err := c.Cache.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("cache lookup for node %s: %w", name, err)
+	}
+	if ok {
+		if c.Decode != nil {
+			if value, err = c.Decode(value); err != nil {
+				return nil, fmt.Errorf("cached value of node %s: %w", name, err)
+			}
+		}
+		reportNodeEvent(ctx, NodeEventCacheHit, nil, metadata)
+		return value, nil
+	}
+	reportNodeEvent(ctx, NodeEventCacheMiss, nil, metadata)
+
+	result, err := fn(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Cache.Set(ctx, key, result, c.TTL); err != nil {
+		return nil, fmt.Errorf("cache store for node %s: %w", name, err)
+	}
+	return result, nil
+}
+
+// MemoryNodeCache is an in-memory NodeCache that evicts the least recently used
+// values when full. It is safe for concurrent use.
+//
+// Values are copied when stored and when returned, so nodes that modify the state in
+// place do not change the cached values: maps, slices, arrays and struct fields are
+// copied, the values pointers refer to are shared.
+type MemoryNodeCache struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // most recently used first
+}
+
+type memoryCacheEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// NewMemoryNodeCache creates an in-memory cache holding up to capacity values;
+// a capacity of zero or less is unbounded
+func NewMemoryNodeCache(capacity int) *MemoryNodeCache {
+	return &MemoryNodeCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get implements NodeCache
+func (c *MemoryNodeCache) Get(_ context.Context, key string) (interface{}, bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*memoryCacheEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(element)
+	return cloneValue(entry.value), true, nil
+}
+
+// Set implements NodeCache
+func (c *MemoryNodeCache) Set(_ context.Context, key string, value interface{}, ttl time.Duration) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry := &memoryCacheEntry{key: key, value: cloneValue(value)}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	if c.capacity > 0 && c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+	return nil
+}
+
+// Clear implements NodeCache
+func (c *MemoryNodeCache) Clear(_ context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	return nil
+}
+
+// Len returns the number of values in the cache, expired ones included
+func (c *MemoryNodeCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
+
+// cloneValue returns a copy of v with its own maps, slices and arrays
+func cloneValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return cloneReflect(reflect.ValueOf(v)).Interface()
+}
+
+func cloneReflect(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		clone := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			clone.SetMapIndex(iter.Key(), cloneReflect(iter.Value()))
+		}
+		return clone
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		clone := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			clone.Index(i).Set(cloneReflect(v.Index(i)))
+		}
+		return clone
+	case reflect.Array:
+		clone := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			clone.Index(i).Set(cloneReflect(v.Index(i)))
+		}
+		return clone
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		clone := reflect.New(v.Type()).Elem()
+		clone.Set(cloneReflect(v.Elem()))
+		return clone
+	case reflect.Struct:
+		// Unexported fields keep their value
+		clone := reflect.New(v.Type()).Elem()
+		clone.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := clone.Field(i); field.CanSet() {
+				field.Set(cloneReflect(v.Field(i)))
+			}
+		}
+		return clone
+	}
+	return v
+}
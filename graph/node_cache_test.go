@@ -0,0 +1,148 @@
+package graph_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryNodeCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("EvictsLeastRecentlyUsed", func(t *testing.T) {
+		cache := graph.NewMemoryNodeCache(2)
+		require.NoError(t, cache.Set(ctx, "a", 1, 0))
+		require.NoError(t, cache.Set(ctx, "b", 2, 0))
+
+		// Reading "a" makes "b" the least recently used
+		_, ok, err := cache.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		require.NoError(t, cache.Set(ctx, "c", 3, 0))
+		assert.Equal(t, 2, cache.Len())
+
+		_, ok, _ = cache.Get(ctx, "b")
+		assert.False(t, ok)
+		value, ok, _ := cache.Get(ctx, "a")
+		assert.True(t, ok)
+		assert.Equal(t, 1, value)
+	})
+
+	t.Run("ExpiresAfterTTL", func(t *testing.T) {
+		cache := graph.NewMemoryNodeCache(0)
+		require.NoError(t, cache.Set(ctx, "short", "x", 10*time.Millisecond))
+		require.NoError(t, cache.Set(ctx, "forever", "y", 0))
+
+		time.Sleep(20 * time.Millisecond)
+		_, ok, _ := cache.Get(ctx, "short")
+		assert.False(t, ok)
+		_, ok, _ = cache.Get(ctx, "forever")
+		assert.True(t, ok)
+
+		require.NoError(t, cache.Clear(ctx))
+		assert.Equal(t, 0, cache.Len())
+	})
+}
+
+func TestNodeCache_SkipsCachedInputs(t *testing.T) {
+	calls := 0
+	cache := graph.NewMemoryNodeCache(10)
+
+	g := graph.NewListenableStateGraph()
+	g.AddNode("embed", "embed", func(ctx context.Context, state interface{}) (interface{}, error) {
+		calls++
+		return map[string]interface{}{"text": state.(map[string]interface{})["text"], "embedded": true}, nil
+	}, graph.WithCache(graph.CachePolicy{
+		Cache: cache,
+		// Ignore the request id, which changes on every call
+		KeyFunc: func(state interface{}) (string, error) {
+			return state.(map[string]interface{})["text"].(string), nil
+		},
+	}))
+	g.SetEntryPoint("embed")
+	g.AddEdge("embed", graph.END)
+
+	metrics := graph.NewMetricsListener()
+	g.AddGlobalListener(metrics)
+
+	var traced []graph.TraceEvent
+	tracer := graph.NewTracer()
+	tracer.AddHook(graph.TraceHookFunc(func(ctx context.Context, span *graph.TraceSpan) {
+		if (span.Event == graph.TraceEventCacheHit || span.Event == graph.TraceEventCacheMiss) && !span.EndTime.IsZero() {
+			assert.Equal(t, "embed:"+span.State.(map[string]interface{})["text"].(string), span.Metadata["key"])
+			traced = append(traced, span.Event)
+		}
+	}))
+
+	runnable, err := g.CompileListenable()
+	require.NoError(t, err)
+	runnable.SetTracer(tracer)
+
+	ctx := context.Background()
+	for i, text := range []string{"hello", "hello", "world"} {
+		res, err := runnable.Invoke(ctx, map[string]interface{}{"text": text, "request_id": i})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"text": text, "embedded": true}, res)
+	}
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, map[string]int{"embed": 1}, metrics.GetCacheHits())
+	assert.Equal(t, map[string]int{"embed": 2}, metrics.GetCacheMisses())
+	assert.Equal(t, []graph.TraceEvent{graph.TraceEventCacheMiss, graph.TraceEventCacheHit, graph.TraceEventCacheMiss}, traced)
+}
+
+func TestNodeCache_DoesNotCacheErrors(t *testing.T) {
+	calls := 0
+	runnable := newPolicyGraph(t, func(ctx context.Context, state interface{}) (interface{}, error) {
+		calls++
+		if calls == 1 {
+			return nil, errTransient
+		}
+		return "ok", nil
+	}, graph.WithCache(graph.CachePolicy{Cache: graph.NewMemoryNodeCache(10), TTL: time.Minute}))
+
+	_, err := runnable.Invoke(context.Background(), "input")
+	require.ErrorIs(t, err, errTransient)
+
+	for i := 0; i < 2; i++ {
+		res, err := runnable.Invoke(context.Background(), "input")
+		require.NoError(t, err)
+		assert.Equal(t, "ok", res)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestNodeCache_CachedValuesAreNotModified(t *testing.T) {
+	calls := 0
+	g := graph.NewStateGraph()
+	g.AddNode("load", "load", func(ctx context.Context, state interface{}) (interface{}, error) {
+		calls++
+		return map[string]interface{}{"doc": "original", "tags": []string{"loaded"}}, nil
+	}, graph.WithCache(graph.CachePolicy{Cache: graph.NewMemoryNodeCache(10)}))
+	// edit modifies the state in place
+	g.AddNode("edit", "edit", func(ctx context.Context, state interface{}) (interface{}, error) {
+		m := state.(map[string]interface{})
+		m["doc"] = m["doc"].(string) + "+edited"
+		tags := m["tags"].([]string)
+		tags[0] = "edited"
+		return m, nil
+	})
+	g.SetEntryPoint("load")
+	g.AddEdge("load", "edit")
+	g.AddEdge("edit", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		res, err := runnable.Invoke(context.Background(), map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"doc": "original+edited", "tags": []string{"edited"}}, res)
+	}
+	assert.Equal(t, 1, calls)
+}
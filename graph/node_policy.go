@@ -0,0 +1,142 @@
+package graph
+
+import (
+	"context"
+	"time"
+)
+
+// NodeOption configures the execution policies of a node, see AddNode
+type NodeOption func(*nodePolicy)
+
+// WithRetry retries the node when it fails, as configured; a nil config uses
+// DefaultRetryConfig. It replaces the graph retry policy for this node.
+func WithRetry(config *RetryConfig) NodeOption {
+	return func(p *nodePolicy) {
+		if config == nil {
+			config = DefaultRetryConfig()
+		}
+		p.retry = config
+	}
+}
+
+// WithTimeout fails each attempt of the node with ErrNodeTimeout when it does not
+// complete within timeout.
+func WithTimeout(timeout time.Duration) NodeOption {
+	return func(p *nodePolicy) {
+		p.timeout = timeout
+	}
+}
+
+// WithCircuitBreaker fails the node with ErrCircuitOpen without calling it after
+// repeated failures, as configured. The breaker is shared by all runs of the graph.
+func WithCircuitBreaker(config CircuitBreakerConfig) NodeOption {
+	return func(p *nodePolicy) {
+		p.circuitBreakerConfig = &config
+	}
+}
+
+// WithRateLimit fails the node with ErrRateLimitExceeded when it is called more than
+// maxCalls times within window. The limit is shared by all runs of the graph.
+func WithRateLimit(maxCalls int, window time.Duration) NodeOption {
+	return func(p *nodePolicy) {
+		p.maxCalls = maxCalls
+		p.window = window
+	}
+}
+
+type nodeEventReporterKey struct{}
+
+// nodeEventReporter is notified of the events raised by the policies of the running node
+type nodeEventReporter func(ctx context.Context, event NodeEvent, err error, metadata map[string]interface{})
+
+// withNodeEventReporter adds the function notified of policy events to the context
+func withNodeEventReporter(ctx context.Context, reporter nodeEventReporter) context.Context {
+	return context.WithValue(ctx, nodeEventReporterKey{}, reporter)
+}
+
+// reportNodeEvent notifies the reporter of the context, if any, of a policy event
+func reportNodeEvent(ctx context.Context, event NodeEvent, err error, metadata map[string]interface{}) {
+	if reporter, ok := ctx.Value(nodeEventReporterKey{}).(nodeEventReporter); ok {
+		reporter(ctx, event, err, metadata)
+	}
+}
+
+// nodePolicy holds the execution policies of a node
+type nodePolicy struct {
+	retry                *RetryConfig
+	timeout              time.Duration
+	circuitBreakerConfig *CircuitBreakerConfig
+	maxCalls             int
+	window               time.Duration
+	cache                *CachePolicy
+
+	// Stateful policies, created once for the node
+	circuitBreaker *CircuitBreaker
+	rateLimiter    *RateLimiter
+}
+
+// newNodePolicy applies the options of the named node, or returns nil when there is none
+func newNodePolicy(name string, opts []NodeOption) *nodePolicy {
+	if len(opts) == 0 {
+		return nil
+	}
+
+	p := &nodePolicy{}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	node := Node{Name: name}
+	if p.circuitBreakerConfig != nil {
+		p.circuitBreaker = NewCircuitBreaker(node, *p.circuitBreakerConfig)
+	}
+	if p.maxCalls > 0 {
+		p.rateLimiter = NewRateLimiter(node, p.maxCalls, p.window)
+	}
+	return p
+}
+
+// execute calls fn with the policies applied, from the outermost:
+// cache, circuit breaker, retry, rate limit, timeout.
+//
+// A retried call is a single call for the circuit breaker, while each attempt
+// counts against the rate limit and has its own timeout.
+func (p *nodePolicy) execute(ctx context.Context, name string, state interface{}, fn func(context.Context, interface{}) (interface{}, error)) (interface{}, error) {
+	node := Node{Name: name}
+	call := fn
+
+	if p.timeout > 0 {
+		timeoutNode := NewTimeoutNode(node, p.timeout)
+		next := call
+		call = func(ctx context.Context, state interface{}) (interface{}, error) {
+			return timeoutNode.run(ctx, state, next)
+		}
+	}
+	if p.rateLimiter != nil {
+		next := call
+		call = func(ctx context.Context, state interface{}) (interface{}, error) {
+			return p.rateLimiter.run(ctx, state, next)
+		}
+	}
+	if p.retry != nil {
+		retryNode := NewRetryNode(node, p.retry)
+		next := call
+		call = func(ctx context.Context, state interface{}) (interface{}, error) {
+			return retryNode.run(ctx, state, next)
+		}
+	}
+	if p.circuitBreaker != nil {
+		next := call
+		call = func(ctx context.Context, state interface{}) (interface{}, error) {
+			return p.circuitBreaker.run(ctx, state, next)
+		}
+	}
+	if p.cache != nil && p.cache.Cache != nil {
+		next := call
+		call = func(ctx context.Context, state interface{}) (interface{}, error) {
+			return p.cache.cached(ctx, name, state, next)
+		}
+	}
+
+	return call(ctx, state)
+}
@@ -0,0 +1,226 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("status %d", e.code)
+}
+
+var errTransient = errors.New("transient")
+
+// newPolicyGraph compiles a graph with a single node "call" added with opts
+func newPolicyGraph(t *testing.T, fn func(ctx context.Context, state interface{}) (interface{}, error), opts ...graph.NodeOption) *graph.StateRunnable {
+	g := graph.NewStateGraph()
+	g.AddNode("call", "call", fn, opts...)
+	g.SetEntryPoint("call")
+	g.AddEdge("call", graph.END)
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+	return runnable
+}
+
+func TestNodePolicy_RetryWithTimeoutPerAttempt(t *testing.T) {
+	var calls atomic.Int32
+	runnable := newPolicyGraph(t, func(ctx context.Context, state interface{}) (interface{}, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return "done", nil
+	},
+		graph.WithTimeout(20*time.Millisecond),
+		graph.WithRetry(&graph.RetryConfig{
+			MaxAttempts:     3,
+			InitialDelay:    time.Millisecond,
+			BackoffFactor:   2,
+			Jitter:          0.5,
+			RetryableErrors: graph.RetryOn(graph.ErrNodeTimeout),
+		}),
+	)
+
+	res, err := runnable.Invoke(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "done", res)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestNodePolicy_TimeoutRecoversPanics(t *testing.T) {
+	runnable := newPolicyGraph(t, func(ctx context.Context, state interface{}) (interface{}, error) {
+		panic("boom")
+	}, graph.WithTimeout(time.Second))
+
+	_, err := runnable.Invoke(context.Background(), nil)
+	assert.EqualError(t, err, "error in node call: panic: boom")
+}
+
+func TestNodePolicy_RetryPredicates(t *testing.T) {
+	t.Run("RetryOn", func(t *testing.T) {
+		var calls atomic.Int32
+		runnable := newPolicyGraph(t, func(ctx context.Context, state interface{}) (interface{}, error) {
+			calls.Add(1)
+			return nil, errors.New("invalid input")
+		}, graph.WithRetry(&graph.RetryConfig{
+			MaxAttempts:     3,
+			RetryableErrors: graph.RetryOn(errTransient),
+		}))
+
+		_, err := runnable.Invoke(context.Background(), nil)
+		require.Error(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("RetryOnType", func(t *testing.T) {
+		var calls atomic.Int32
+		runnable := newPolicyGraph(t, func(ctx context.Context, state interface{}) (interface{}, error) {
+			if calls.Add(1) < 3 {
+				return nil, &statusError{code: 503}
+			}
+			return "ok", nil
+		}, graph.WithRetry(&graph.RetryConfig{
+			MaxAttempts:     3,
+			RetryableErrors: graph.RetryOnType[*statusError](),
+		}))
+
+		res, err := runnable.Invoke(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", res)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("GraphRetryPolicy", func(t *testing.T) {
+		var calls atomic.Int32
+		g := graph.NewStateGraph()
+		g.AddNode("call", "call", func(ctx context.Context, state interface{}) (interface{}, error) {
+			if calls.Add(1) == 1 {
+				return nil, errTransient
+			}
+			return "ok", nil
+		})
+		g.SetEntryPoint("call")
+		g.AddEdge("call", graph.END)
+		g.SetRetryPolicy(&graph.RetryPolicy{
+			MaxRetries:      1,
+			BackoffStrategy: graph.FixedBackoff,
+			RetryIf:         graph.RetryOn(errTransient),
+		})
+		runnable, err := g.Compile()
+		require.NoError(t, err)
+
+		// The fixed backoff waits one second
+		res, err := runnable.Invoke(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", res)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+}
+
+func TestNodePolicy_CircuitBreakerAroundRetry(t *testing.T) {
+	var calls atomic.Int32
+	runnable := newPolicyGraph(t, func(ctx context.Context, state interface{}) (interface{}, error) {
+		calls.Add(1)
+		return nil, errTransient
+	},
+		graph.WithRetry(&graph.RetryConfig{MaxAttempts: 2}),
+		graph.WithCircuitBreaker(graph.CircuitBreakerConfig{
+			FailureThreshold: 1,
+			SuccessThreshold: 1,
+			Timeout:          time.Hour,
+			HalfOpenMaxCalls: 1,
+		}),
+	)
+
+	// The retried call counts as a single failure and opens the circuit
+	_, err := runnable.Invoke(context.Background(), nil)
+	require.ErrorIs(t, err, errTransient)
+	assert.Equal(t, int32(2), calls.Load())
+
+	_, err = runnable.Invoke(context.Background(), nil)
+	require.ErrorIs(t, err, graph.ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestNodePolicy_RateLimit(t *testing.T) {
+	runnable := newPolicyGraph(t, func(ctx context.Context, state interface{}) (interface{}, error) {
+		return "ok", nil
+	}, graph.WithRateLimit(1, time.Hour))
+
+	_, err := runnable.Invoke(context.Background(), nil)
+	require.NoError(t, err)
+
+	_, err = runnable.Invoke(context.Background(), nil)
+	require.ErrorIs(t, err, graph.ErrRateLimitExceeded)
+}
+
+func TestNodePolicy_InterruptIsNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	runnable := newPolicyGraph(t, func(ctx context.Context, state interface{}) (interface{}, error) {
+		calls.Add(1)
+		return graph.Interrupt(ctx, "confirm?")
+	}, graph.WithRetry(&graph.RetryConfig{MaxAttempts: 3}))
+
+	_, err := runnable.Invoke(context.Background(), nil)
+	var interrupt *graph.GraphInterrupt
+	require.ErrorAs(t, err, &interrupt)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestNodePolicy_RetriesAreReported(t *testing.T) {
+	var calls atomic.Int32
+	g := graph.NewListenableStateGraph()
+	g.AddNode("call", "call", func(ctx context.Context, state interface{}) (interface{}, error) {
+		if calls.Add(1) < 3 {
+			return nil, errTransient
+		}
+		return "ok", nil
+	}, graph.WithRetry(&graph.RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond}))
+	g.SetEntryPoint("call")
+	g.AddEdge("call", graph.END)
+
+	var mu sync.Mutex
+	var events []graph.NodeEvent
+	g.AddGlobalListener(graph.NodeListenerFunc(func(ctx context.Context, event graph.NodeEvent, nodeName string, state interface{}, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}))
+
+	var attempts []interface{}
+	tracer := graph.NewTracer()
+	tracer.AddHook(graph.TraceHookFunc(func(ctx context.Context, span *graph.TraceSpan) {
+		if span.Event == graph.TraceEventNodeRetry && !span.EndTime.IsZero() {
+			assert.ErrorIs(t, span.Error, errTransient)
+			attempts = append(attempts, span.Metadata["attempt"])
+		}
+	}))
+
+	runnable, err := g.CompileListenable()
+	require.NoError(t, err)
+	runnable.SetTracer(tracer)
+
+	res, err := runnable.Invoke(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", res)
+
+	assert.Equal(t, []graph.NodeEvent{
+		graph.NodeEventStart, graph.NodeEventError, graph.NodeEventRetry,
+		graph.NodeEventStart, graph.NodeEventError, graph.NodeEventRetry,
+		graph.NodeEventStart, graph.NodeEventComplete,
+	}, events)
+	assert.Equal(t, []interface{}{1, 2}, attempts)
+}
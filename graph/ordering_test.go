@@ -0,0 +1,82 @@
+package graph_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFanOutGraph fans out from "start" to branches declared in a non-sorted order,
+// which finish in random order and append to a non-commutative reducer.
+func newFanOutGraph(t *testing.T) *graph.StateRunnable {
+	g := graph.NewStateGraph()
+	schema := graph.NewMapSchema()
+	schema.RegisterReducer("order", graph.AppendReducer)
+	g.SetSchema(schema)
+
+	branches := []string{"e", "b", "d", "a", "c"}
+	g.AddNode("start", "start", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return map[string]interface{}{"order": []string{"start"}}, nil
+	})
+	for _, name := range branches {
+		name := name
+		g.AddNode(name, name, func(ctx context.Context, state interface{}) (interface{}, error) {
+			time.Sleep(time.Duration(rand.Intn(3)) * time.Millisecond)
+			return map[string]interface{}{"order": []string{name}}, nil
+		})
+		g.AddEdge("start", name)
+		g.AddEdge(name, "join")
+	}
+	g.AddNode("join", "join", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return map[string]interface{}{"order": []string{"join"}}, nil
+	})
+	g.SetEntryPoint("start")
+	g.AddEdge("join", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+	return runnable
+}
+
+func TestDeterministicOrder_ParallelMerge(t *testing.T) {
+	runnable := newFanOutGraph(t)
+
+	expected := []string{"start", "e", "b", "d", "a", "c", "join"}
+	for i := 0; i < 20; i++ {
+		res, err := runnable.Invoke(context.Background(), map[string]interface{}{})
+		require.NoError(t, err)
+		// Branch results are merged in edge declaration order, not completion order
+		assert.Equal(t, expected, res.(map[string]interface{})["order"])
+	}
+}
+
+type stepRecorder struct {
+	graph.NoOpCallbackHandler
+	steps []string
+	next  [][]string
+}
+
+func (r *stepRecorder) OnGraphStep(ctx context.Context, stepNode string, state interface{}) {
+	r.steps = append(r.steps, stepNode)
+	r.next = append(r.next, graph.GetNextNodes(ctx))
+}
+
+func TestDeterministicOrder_Steps(t *testing.T) {
+	runnable := newFanOutGraph(t)
+
+	for i := 0; i < 10; i++ {
+		recorder := &stepRecorder{}
+		_, err := runnable.InvokeWithConfig(context.Background(), map[string]interface{}{}, &graph.Config{
+			Callbacks: []graph.CallbackHandler{recorder},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"start", "step:[e b d a c]", "join"}, recorder.steps)
+		assert.Equal(t, [][]string{{"e", "b", "d", "a", "c"}, {"join"}, {}}, recorder.next)
+	}
+}
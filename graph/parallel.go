@@ -83,13 +83,17 @@ func (pn *ParallelNode) Execute(ctx context.Context, state interface{}) (interfa
 }
 
 // AddParallelNodes adds a set of nodes that execute in parallel
+//
+// Deprecated: add the nodes separately and fan out with edges from the same node,
+// or use AddConditionalSendEdge to run a node once per item with its own input.
 func (g *StateGraph) AddParallelNodes(groupName string, nodes map[string]func(context.Context, interface{}) (interface{}, error)) {
 	// Create parallel node group
+	// Nodes are ordered by name so the results are collected in a stable order
 	parallelNodes := make([]Node, 0, len(nodes))
-	for name, fn := range nodes {
+	for _, name := range sortedKeys(nodes) {
 		parallelNodes = append(parallelNodes, Node{
 			Name:     name,
-			Function: fn,
+			Function: nodes[name],
 		})
 	}
 
@@ -99,6 +103,9 @@ func (g *StateGraph) AddParallelNodes(groupName string, nodes map[string]func(co
 }
 
 // MapReduceNode executes nodes in parallel and reduces results
+//
+// Deprecated: use Send to run a node once per item and a schema reducer to
+// merge the results; see AddConditionalSendEdge.
 type MapReduceNode struct {
 	name     string
 	mapNodes []Node
@@ -132,6 +139,8 @@ func (mr *MapReduceNode) Execute(ctx context.Context, state interface{}) (interf
 }
 
 // AddMapReduceNode adds a map-reduce pattern node
+//
+// Deprecated: use AddConditionalSendEdge and a schema reducer instead.
 func (g *StateGraph) AddMapReduceNode(
 	name string,
 	mapFunctions map[string]func(context.Context, interface{}) (interface{}, error),
@@ -139,10 +148,10 @@ func (g *StateGraph) AddMapReduceNode(
 ) {
 	// Create map nodes
 	mapNodes := make([]Node, 0, len(mapFunctions))
-	for nodeName, fn := range mapFunctions {
+	for _, nodeName := range sortedKeys(mapFunctions) {
 		mapNodes = append(mapNodes, Node{
 			Name:     nodeName,
-			Function: fn,
+			Function: mapFunctions[nodeName],
 		})
 	}
 
@@ -152,6 +161,9 @@ func (g *StateGraph) AddMapReduceNode(
 }
 
 // FanOutFanIn creates a fan-out/fan-in pattern
+//
+// Deprecated: use AddConditionalSendEdge to fan out and a schema reducer to
+// collect the results in the state.
 func (g *StateGraph) FanOutFanIn(
 	source string,
 	_ []string, // workers parameter kept for API compatibility
@@ -0,0 +1,138 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFanOutRunnable builds a -> (charge, notify) -> END where notify is implemented by notify
+func newFanOutRunnable(t *testing.T, runs map[string]int, notify func(ctx context.Context) error) *graph.CheckpointableRunnable {
+	g := graph.NewCheckpointableStateGraph()
+	schema := graph.NewMapSchema()
+	schema.RegisterReducer("steps", graph.AppendReducer)
+	g.SetSchema(schema)
+
+	// charge and notify run concurrently
+	var mu sync.Mutex
+	count := func(node string) {
+		mu.Lock()
+		defer mu.Unlock()
+		runs[node]++
+	}
+
+	g.AddNode("a", "a", func(ctx context.Context, state interface{}) (interface{}, error) {
+		count("a")
+		return map[string]interface{}{"steps": []string{"a"}}, nil
+	})
+	g.AddNode("charge", "charge", func(ctx context.Context, state interface{}) (interface{}, error) {
+		count("charge")
+		return map[string]interface{}{"steps": []string{"charge"}}, nil
+	})
+	g.AddNode("notify", "notify", func(ctx context.Context, state interface{}) (interface{}, error) {
+		count("notify")
+		if err := notify(ctx); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"steps": []string{"notify"}}, nil
+	})
+	g.SetEntryPoint("a")
+	g.AddEdge("a", "charge")
+	g.AddEdge("a", "notify")
+	g.AddEdge("charge", graph.END)
+	g.AddEdge("notify", graph.END)
+
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+	return runnable
+}
+
+func lastCheckpoint(t *testing.T, runnable *graph.CheckpointableRunnable) *graph.Checkpoint {
+	checkpoints, err := runnable.ListCheckpoints(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, checkpoints)
+	return checkpoints[len(checkpoints)-1]
+}
+
+func TestPendingWrites_ResumeFailedStep(t *testing.T) {
+	runs := map[string]int{}
+	failures := 1
+	runnable := newFanOutRunnable(t, runs, func(ctx context.Context) error {
+		if failures > 0 {
+			failures--
+			return errors.New("smtp unavailable")
+		}
+		return nil
+	})
+	ctx := context.Background()
+
+	_, err := runnable.Invoke(ctx, map[string]interface{}{})
+	require.ErrorContains(t, err, "smtp unavailable")
+
+	// The successful sibling is stored with the step that failed
+	failed := lastCheckpoint(t, runnable)
+	assert.Equal(t, "error", failed.Metadata["event"])
+	assert.Equal(t, "notify", failed.NodeName)
+	assert.Equal(t, []string{"charge", "notify"}, failed.Next)
+	assert.Equal(t, 1, failed.Step)
+	require.Len(t, failed.PendingWrites, 1)
+	assert.Equal(t, 0, failed.PendingWrites[0].Task)
+	assert.Equal(t, "charge", failed.PendingWrites[0].Node)
+
+	res, err := runnable.ResumeFromCheckpoint(ctx, failed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "charge", "notify"}, res.(map[string]interface{})["steps"])
+
+	// Only the failed task ran again
+	assert.Equal(t, map[string]int{"a": 1, "charge": 1, "notify": 2}, runs)
+}
+
+func TestPendingWrites_ResumeInterruptedStep(t *testing.T) {
+	runs := map[string]int{}
+	runnable := newFanOutRunnable(t, runs, func(ctx context.Context) error {
+		_, err := graph.Interrupt(ctx, "send the email?")
+		return err
+	})
+	ctx := context.Background()
+
+	_, err := runnable.Invoke(ctx, map[string]interface{}{})
+	var interrupt *graph.GraphInterrupt
+	require.ErrorAs(t, err, &interrupt)
+
+	pending := lastCheckpoint(t, runnable)
+	require.NotNil(t, pending.Interrupt)
+	assert.Equal(t, "notify", pending.Interrupt.Node)
+	assert.Equal(t, []string{"charge", "notify"}, pending.Next)
+	require.Len(t, pending.PendingWrites, 1)
+	assert.Equal(t, "charge", pending.PendingWrites[0].Node)
+
+	res, err := runnable.ResumeFromCheckpointWithConfig(ctx, pending.ID, &graph.Config{ResumeValue: "yes"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "charge", "notify"}, res.(map[string]interface{})["steps"])
+	assert.Equal(t, map[string]int{"a": 1, "charge": 1, "notify": 2}, runs)
+}
+
+func TestPendingWrites_NotSavedWithoutCompletedTasks(t *testing.T) {
+	runs := map[string]int{}
+	g := graph.NewCheckpointableStateGraph()
+	g.AddNode("fail", "fail", func(ctx context.Context, state interface{}) (interface{}, error) {
+		runs["fail"]++
+		return nil, errors.New("boom")
+	})
+	g.SetEntryPoint("fail")
+	g.AddEdge("fail", graph.END)
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+
+	_, err = runnable.Invoke(context.Background(), "input")
+	require.Error(t, err)
+
+	checkpoints, err := runnable.ListCheckpoints(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, checkpoints)
+}
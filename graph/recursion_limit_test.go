@@ -0,0 +1,106 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newLoopGraph(t *testing.T, steps *[]int) *StateRunnable {
+	t.Helper()
+
+	g := NewStateGraph()
+	g.AddNode("loop", "loop", func(ctx context.Context, state interface{}) (interface{}, error) {
+		*steps = append(*steps, GetStep(ctx))
+		return state.(int) + 1, nil
+	})
+	g.SetEntryPoint("loop")
+	g.AddConditionalEdge("loop", func(ctx context.Context, state interface{}) string {
+		if state.(int) >= 100 {
+			return END
+		}
+		return "loop"
+	})
+
+	runnable, err := g.Compile()
+	assert.NoError(t, err)
+	return runnable
+}
+
+func TestRecursionLimit(t *testing.T) {
+	t.Run("DefaultLimit", func(t *testing.T) {
+		var steps []int
+		runnable := newLoopGraph(t, &steps)
+
+		res, err := runnable.Invoke(context.Background(), 0)
+
+		var recursionErr *GraphRecursionError
+		assert.ErrorAs(t, err, &recursionErr)
+		assert.Equal(t, DefaultRecursionLimit, recursionErr.Limit)
+		assert.Equal(t, DefaultRecursionLimit, recursionErr.State)
+		assert.Equal(t, []string{"loop"}, recursionErr.NextNodes)
+		assert.Equal(t, DefaultRecursionLimit, res)
+		assert.Len(t, steps, DefaultRecursionLimit)
+	})
+
+	t.Run("CustomLimit", func(t *testing.T) {
+		var steps []int
+		runnable := newLoopGraph(t, &steps)
+
+		_, err := runnable.InvokeWithConfig(context.Background(), 0, &Config{RecursionLimit: 3})
+
+		var recursionErr *GraphRecursionError
+		assert.ErrorAs(t, err, &recursionErr)
+		assert.Equal(t, 3, recursionErr.Limit)
+		assert.Equal(t, 3, recursionErr.State)
+		assert.Equal(t, []int{1, 2, 3}, steps)
+	})
+
+	t.Run("ResumeAfterLimit", func(t *testing.T) {
+		var steps []int
+		runnable := newLoopGraph(t, &steps)
+
+		_, err := runnable.InvokeWithConfig(context.Background(), 90, &Config{RecursionLimit: 5})
+		var recursionErr *GraphRecursionError
+		assert.ErrorAs(t, err, &recursionErr)
+
+		res, err := runnable.InvokeWithConfig(context.Background(), recursionErr.State, &Config{
+			ResumeFrom: recursionErr.NextNodes,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 100, res)
+	})
+
+	t.Run("WithinLimit", func(t *testing.T) {
+		var steps []int
+		runnable := newLoopGraph(t, &steps)
+
+		res, err := runnable.InvokeWithConfig(context.Background(), 0, &Config{RecursionLimit: 100})
+		assert.NoError(t, err)
+		assert.Equal(t, 100, res)
+	})
+}
+
+func TestListenableRecursionLimit(t *testing.T) {
+	g := NewListenableStateGraph()
+	g.AddNode("A", "A", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return state.(int) + 1, nil
+	})
+	g.AddNode("B", "B", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return state.(int) + 1, nil
+	})
+	g.SetEntryPoint("A")
+	g.AddEdge("A", "B")
+	g.AddEdge("B", "A")
+
+	runnable, err := g.CompileListenable()
+	assert.NoError(t, err)
+
+	_, err = runnable.InvokeWithConfig(context.Background(), 0, &Config{RecursionLimit: 4})
+
+	var recursionErr *GraphRecursionError
+	assert.ErrorAs(t, err, &recursionErr)
+	assert.Equal(t, 4, recursionErr.State)
+	assert.Equal(t, []string{"A"}, recursionErr.NextNodes)
+}
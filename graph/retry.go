@@ -2,19 +2,77 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"sync"
 	"time"
 )
 
+var (
+	// ErrNodeTimeout is returned when a node does not complete within its timeout
+	ErrNodeTimeout = errors.New("node timed out")
+
+	// ErrCircuitOpen is returned when a node is called while its circuit breaker is open
+	ErrCircuitOpen = errors.New("circuit breaker open")
+
+	// ErrRateLimitExceeded is returned when a node is called more often than its rate limit allows
+	ErrRateLimitExceeded = errors.New("rate limit exceeded")
+)
+
 // RetryConfig configures retry behavior for nodes
 type RetryConfig struct {
 	MaxAttempts     int
 	InitialDelay    time.Duration
 	MaxDelay        time.Duration
 	BackoffFactor   float64
-	RetryableErrors func(error) bool // Determines if an error should trigger retry
+	RetryableErrors func(error) bool // Determines if an error should trigger retry, see RetryOn and RetryOnType
+	Jitter          float64          // Randomizes each delay by ±Jitter (a fraction of the delay, e.g. 0.25)
+}
+
+// RetryOn returns a RetryableErrors predicate matching errors that wrap one of targets, using errors.Is
+func RetryOn(targets ...error) func(error) bool {
+	return func(err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RetryOnType returns a RetryableErrors predicate matching errors that wrap an error of type T, using errors.As
+func RetryOnType[T error]() func(error) bool {
+	return func(err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}
+}
+
+// isInterrupt reports whether err is an interrupt, which must never be retried
+func isInterrupt(err error) bool {
+	var nodeInterrupt *NodeInterrupt
+	var graphInterrupt *GraphInterrupt
+	return errors.As(err, &nodeInterrupt) || errors.As(err, &graphInterrupt)
+}
+
+// withJitter randomizes delay by ±jitter
+func withJitter(delay time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 || delay <= 0 {
+		return delay
+	}
+	//nolint:gosec // Using weak RNG for jitter is acceptable, not security-critical
+	return delay + time.Duration(float64(delay)*jitter*(2*rand.Float64()-1))
+}
+
+// reportRetry reports that the failed attempt of the running node will be retried after delay
+func reportRetry(ctx context.Context, attempt int, err error, delay time.Duration) {
+	reportNodeEvent(ctx, NodeEventRetry, err, map[string]interface{}{
+		"attempt": attempt,
+		"delay":   delay,
+	})
 }
 
 // DefaultRetryConfig returns a default retry configuration
@@ -50,6 +108,11 @@ func NewRetryNode(node Node, config *RetryConfig) *RetryNode {
 
 // Execute runs the node with retry logic
 func (rn *RetryNode) Execute(ctx context.Context, state interface{}) (interface{}, error) {
+	return rn.run(ctx, state, rn.node.Function)
+}
+
+// run calls fn with retry logic
+func (rn *RetryNode) run(ctx context.Context, state interface{}, fn func(context.Context, interface{}) (interface{}, error)) (interface{}, error) {
 	var lastErr error
 	delay := rn.config.InitialDelay
 
@@ -62,11 +125,16 @@ func (rn *RetryNode) Execute(ctx context.Context, state interface{}) (interface{
 		}
 
 		// Execute the node
-		result, err := rn.node.Function(ctx, state)
+		result, err := fn(ctx, state)
 		if err == nil {
 			return result, nil
 		}
 
+		// Interrupts are not failures
+		if isInterrupt(err) {
+			return nil, err
+		}
+
 		lastErr = err
 
 		// Check if error is retryable
@@ -76,12 +144,15 @@ func (rn *RetryNode) Execute(ctx context.Context, state interface{}) (interface{
 
 		// Don't sleep after the last attempt
 		if attempt < rn.config.MaxAttempts {
+			wait := withJitter(delay, rn.config.Jitter)
+			reportRetry(ctx, attempt, err, wait)
+
 			// Sleep with exponential backoff
 			select {
-			case <-time.After(delay):
+			case <-time.After(wait):
 				// Calculate next delay with backoff
 				delay = time.Duration(float64(delay) * rn.config.BackoffFactor)
-				if delay > rn.config.MaxDelay {
+				if rn.config.MaxDelay > 0 && delay > rn.config.MaxDelay {
 					delay = rn.config.MaxDelay
 				}
 			case <-ctx.Done():
@@ -95,6 +166,8 @@ func (rn *RetryNode) Execute(ctx context.Context, state interface{}) (interface{
 }
 
 // AddNodeWithRetry adds a node with retry logic
+//
+// Deprecated: use AddNode with the WithRetry option, which can be combined with other policies.
 func (g *StateGraph) AddNodeWithRetry(
 	name string,
 	description string,
@@ -110,7 +183,11 @@ func (g *StateGraph) AddNodeWithRetry(
 	g.AddNode(name, description, retryNode.Execute)
 }
 
-// TimeoutNode wraps a node with timeout logic
+// TimeoutNode wraps a node with timeout logic.
+//
+// The node runs in its own goroutine and receives a context canceled at the timeout.
+// A node that ignores ctx keeps running after the timeout is reported, and its result
+// is discarded: nodes should return when ctx is done.
 type TimeoutNode struct {
 	node    Node
 	timeout time.Duration
@@ -126,6 +203,12 @@ func NewTimeoutNode(node Node, timeout time.Duration) *TimeoutNode {
 
 // Execute runs the node with timeout
 func (tn *TimeoutNode) Execute(ctx context.Context, state interface{}) (interface{}, error) {
+	return tn.run(ctx, state, tn.node.Function)
+}
+
+// run calls fn with timeout. On timeout, it returns without waiting for fn, which
+// is left to observe the cancellation of its context.
+func (tn *TimeoutNode) run(ctx context.Context, state interface{}, fn func(context.Context, interface{}) (interface{}, error)) (interface{}, error) {
 	// Create a timeout context
 	timeoutCtx, cancel := context.WithTimeout(ctx, tn.timeout)
 	defer cancel()
@@ -137,9 +220,14 @@ func (tn *TimeoutNode) Execute(ctx context.Context, state interface{}) (interfac
 	}
 	resultChan := make(chan result, 1)
 
-	// Execute in goroutine
+	// Execute in goroutine, reporting a panic as an error like the engine does
 	go func() {
-		value, err := tn.node.Function(timeoutCtx, state)
+		defer func() {
+			if r := recover(); r != nil {
+				resultChan <- result{err: fmt.Errorf("panic: %v", r)}
+			}
+		}()
+		value, err := fn(timeoutCtx, state)
 		resultChan <- result{value: value, err: err}
 	}()
 
@@ -148,11 +236,17 @@ func (tn *TimeoutNode) Execute(ctx context.Context, state interface{}) (interfac
 	case res := <-resultChan:
 		return res.value, res.err
 	case <-timeoutCtx.Done():
-		return nil, fmt.Errorf("node %s timed out after %v", tn.node.Name, tn.timeout)
+		// Cancellation of the parent context is not a timeout of the node
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %s after %v", ErrNodeTimeout, tn.node.Name, tn.timeout)
 	}
 }
 
 // AddNodeWithTimeout adds a node with timeout
+//
+// Deprecated: use AddNode with the WithTimeout option, which can be combined with other policies.
 func (g *StateGraph) AddNodeWithTimeout(
 	name string,
 	description string,
@@ -185,8 +279,10 @@ const (
 	CircuitHalfOpen
 )
 
-// CircuitBreaker implements the circuit breaker pattern
+// CircuitBreaker implements the circuit breaker pattern.
+// It is safe for concurrent use.
 type CircuitBreaker struct {
+	mutex           sync.Mutex
 	node            Node
 	config          CircuitBreakerConfig
 	state           CircuitBreakerState
@@ -207,6 +303,35 @@ func NewCircuitBreaker(node Node, config CircuitBreakerConfig) *CircuitBreaker {
 
 // Execute runs the node with circuit breaker logic
 func (cb *CircuitBreaker) Execute(ctx context.Context, state interface{}) (interface{}, error) {
+	return cb.run(ctx, state, cb.node.Function)
+}
+
+// run calls fn with circuit breaker logic
+func (cb *CircuitBreaker) run(ctx context.Context, state interface{}, fn func(context.Context, interface{}) (interface{}, error)) (interface{}, error) {
+	if err := cb.acquire(); err != nil {
+		return nil, err
+	}
+
+	// Execute the node
+	result, err := fn(ctx, state)
+
+	// Interrupts are not failures
+	if isInterrupt(err) {
+		return nil, err
+	}
+
+	cb.record(err)
+	if err != nil {
+		return nil, fmt.Errorf("circuit breaker error in %s: %w", cb.node.Name, err)
+	}
+	return result, nil
+}
+
+// acquire checks that the circuit allows a call
+func (cb *CircuitBreaker) acquire() error {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
 	// Check circuit state
 	switch cb.state {
 	case CircuitClosed:
@@ -217,19 +342,23 @@ func (cb *CircuitBreaker) Execute(ctx context.Context, state interface{}) (inter
 			cb.state = CircuitHalfOpen
 			cb.halfOpenCalls = 0
 		} else {
-			return nil, fmt.Errorf("circuit breaker open for %s", cb.node.Name)
+			return fmt.Errorf("%w for %s", ErrCircuitOpen, cb.node.Name)
 		}
 	case CircuitHalfOpen:
 		// Check if we've made too many calls in half-open state
 		if cb.halfOpenCalls >= cb.config.HalfOpenMaxCalls {
 			cb.state = CircuitOpen
-			return nil, fmt.Errorf("circuit breaker half-open limit reached for %s", cb.node.Name)
+			return fmt.Errorf("%w: half-open limit reached for %s", ErrCircuitOpen, cb.node.Name)
 		}
 		cb.halfOpenCalls++
 	}
+	return nil
+}
 
-	// Execute the node
-	result, err := cb.node.Function(ctx, state)
+// record updates the circuit state with the result of a call
+func (cb *CircuitBreaker) record(err error) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
 
 	// Update circuit breaker state based on result
 	if err != nil {
@@ -240,8 +369,7 @@ func (cb *CircuitBreaker) Execute(ctx context.Context, state interface{}) (inter
 		if cb.failures >= cb.config.FailureThreshold {
 			cb.state = CircuitOpen
 		}
-
-		return nil, fmt.Errorf("circuit breaker error in %s: %w", cb.node.Name, err)
+		return
 	}
 
 	// Success
@@ -251,11 +379,11 @@ func (cb *CircuitBreaker) Execute(ctx context.Context, state interface{}) (inter
 	if cb.state == CircuitHalfOpen && cb.successes >= cb.config.SuccessThreshold {
 		cb.state = CircuitClosed
 	}
-
-	return result, nil
 }
 
 // AddNodeWithCircuitBreaker adds a node with circuit breaker
+//
+// Deprecated: use AddNode with the WithCircuitBreaker option, which can be combined with other policies.
 func (g *StateGraph) AddNodeWithCircuitBreaker(
 	name string,
 	description string,
@@ -271,8 +399,10 @@ func (g *StateGraph) AddNodeWithCircuitBreaker(
 	g.AddNode(name, description, cb.Execute)
 }
 
-// RateLimiter implements rate limiting for nodes
+// RateLimiter implements rate limiting for nodes.
+// It is safe for concurrent use.
 type RateLimiter struct {
+	mutex    sync.Mutex
 	node     Node
 	maxCalls int
 	window   time.Duration
@@ -291,6 +421,24 @@ func NewRateLimiter(node Node, maxCalls int, window time.Duration) *RateLimiter
 
 // Execute runs the node with rate limiting
 func (rl *RateLimiter) Execute(ctx context.Context, state interface{}) (interface{}, error) {
+	return rl.run(ctx, state, rl.node.Function)
+}
+
+// run calls fn with rate limiting
+func (rl *RateLimiter) run(ctx context.Context, state interface{}, fn func(context.Context, interface{}) (interface{}, error)) (interface{}, error) {
+	if err := rl.acquire(); err != nil {
+		return nil, err
+	}
+
+	// Execute the node
+	return fn(ctx, state)
+}
+
+// acquire records a call, or fails when the limit is reached
+func (rl *RateLimiter) acquire() error {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
 	now := time.Now()
 
 	// Remove old calls outside the window
@@ -307,17 +455,17 @@ func (rl *RateLimiter) Execute(ctx context.Context, state interface{}) (interfac
 		// Calculate when we can make the next call
 		oldestCall := rl.calls[0]
 		waitTime := rl.window - now.Sub(oldestCall)
-		return nil, fmt.Errorf("rate limit exceeded for %s, retry after %v", rl.node.Name, waitTime)
+		return fmt.Errorf("%w for %s, retry after %v", ErrRateLimitExceeded, rl.node.Name, waitTime)
 	}
 
 	// Record this call
 	rl.calls = append(rl.calls, now)
-
-	// Execute the node
-	return rl.node.Function(ctx, state)
+	return nil
 }
 
 // AddNodeWithRateLimit adds a node with rate limiting
+//
+// Deprecated: use AddNode with the WithRateLimit option, which can be combined with other policies.
 func (g *StateGraph) AddNodeWithRateLimit(
 	name string,
 	description string,
@@ -355,9 +503,7 @@ func ExponentialBackoffRetry(
 		delay := baseDelay * time.Duration(math.Pow(2, float64(attempt)))
 
 		// Add jitter (±25%)
-		//nolint:gosec // Using weak RNG for jitter is acceptable, not security-critical
-		jitter := time.Duration(float64(delay) * 0.25 * (2*rand.Float64() - 1))
-		delay += jitter
+		delay = withJitter(delay, 0.25)
 
 		select {
 		case <-time.After(delay):
@@ -0,0 +1,118 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+)
+
+// Send schedules a node to run in the next step with its own input instead of the
+// shared graph state. It enables map-reduce patterns: a router can return one Send
+// per item to spawn several invocations of the same node in parallel, whose results
+// are merged into the state through the schema reducers.
+//
+// Sends can be returned by the router of AddConditionalSendEdge, or in Command.Goto
+// (alone, as []Send, or mixed with node names in a []interface{}).
+type Send struct {
+	// Node is the name of the node to run
+	Node string
+
+	// Arg is the input passed to the node instead of the graph state
+	Arg interface{}
+}
+
+// NewSend creates a Send to the given node with the given input
+func NewSend(node string, arg interface{}) Send {
+	return Send{Node: node, Arg: arg}
+}
+
+// AddConditionalSendEdge adds an edge whose router returns the Sends to execute after
+// the "from" node. Every Send runs as a separate task of the next step, in the order
+// returned by the router. Returning no Send ends this branch.
+func (g *StateGraph) AddConditionalSendEdge(from string, router func(ctx context.Context, state interface{}) []Send) {
+	g.addConditionalSendEdge(from, func(ctx context.Context, state interface{}) ([]Send, error) {
+		return router(ctx, state), nil
+	})
+}
+
+func (g *StateGraph) addConditionalSendEdge(from string, router func(ctx context.Context, state interface{}) ([]Send, error)) {
+	if g.sendEdges == nil {
+		g.sendEdges = make(map[string]func(ctx context.Context, state interface{}) ([]Send, error))
+	}
+	g.sendEdges[from] = router
+}
+
+// task is a node execution scheduled in a step. Tasks created from a Send
+// receive their own input instead of the graph state.
+type task struct {
+	node  string
+	input interface{}
+	send  bool
+}
+
+// newTasks returns the tasks of a step: the nodes run on the shared state,
+// followed by the Sends in the order they were created.
+func newTasks(nodes []string, sends []Send, state interface{}) []task {
+	tasks := make([]task, 0, len(nodes)+len(sends))
+	for _, node := range nodes {
+		tasks = append(tasks, task{node: node, input: state})
+	}
+	for _, s := range sends {
+		if s.Node == END {
+			continue
+		}
+		tasks = append(tasks, task{node: s.Node, input: s.Arg, send: true})
+	}
+	return tasks
+}
+
+// taskNodes returns the names of the nodes executed by the tasks
+func taskNodes(tasks []task) []string {
+	nodes := make([]string, len(tasks))
+	for i, t := range tasks {
+		nodes[i] = t.node
+	}
+	return nodes
+}
+
+// sendNodes returns the names of the nodes targeted by the Sends
+func sendNodes(sends []Send) []string {
+	var nodes []string
+	for _, s := range sends {
+		if s.Node != END {
+			nodes = append(nodes, s.Node)
+		}
+	}
+	return nodes
+}
+
+// gotoTargets splits Command.Goto into node names and Sends
+func gotoTargets(target interface{}) ([]string, []Send, error) {
+	switch g := target.(type) {
+	case nil:
+		return nil, nil, nil
+	case string:
+		return []string{g}, nil, nil
+	case []string:
+		return g, nil, nil
+	case Send:
+		return nil, []Send{g}, nil
+	case *Send:
+		return nil, []Send{*g}, nil
+	case []Send:
+		return nil, g, nil
+	case []interface{}:
+		var nodes []string
+		var sends []Send
+		for _, item := range g {
+			n, s, err := gotoTargets(item)
+			if err != nil {
+				return nil, nil, err
+			}
+			nodes = append(nodes, n...)
+			sends = append(sends, s...)
+		}
+		return nodes, sends, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported Command.Goto type %T", target)
+	}
+}
@@ -0,0 +1,194 @@
+package graph_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMapReduceGraph splits the documents of the state, summarizes each one with
+// its own Send and combines the summaries once all of them are merged.
+func newMapReduceGraph(t *testing.T, combined *int32) *graph.StateRunnable {
+	g := graph.NewStateGraph()
+	schema := graph.NewMapSchema()
+	schema.RegisterReducer("summaries", graph.AppendReducer)
+	g.SetSchema(schema)
+
+	g.AddNode("split", "split", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return map[string]interface{}{}, nil
+	})
+	g.AddNode("summarize", "summarize", func(ctx context.Context, state interface{}) (interface{}, error) {
+		// Each task receives its own document instead of the graph state
+		doc, ok := state.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected input %T", state)
+		}
+		return map[string]interface{}{"summaries": []string{strings.ToUpper(doc)}}, nil
+	})
+	g.AddNode("combine", "combine", func(ctx context.Context, state interface{}) (interface{}, error) {
+		atomic.AddInt32(combined, 1)
+		summaries := state.(map[string]interface{})["summaries"].([]string)
+		return map[string]interface{}{"result": strings.Join(summaries, ",")}, nil
+	})
+
+	g.SetEntryPoint("split")
+	g.AddConditionalSendEdge("split", func(ctx context.Context, state interface{}) []graph.Send {
+		var sends []graph.Send
+		for _, doc := range state.(map[string]interface{})["docs"].([]string) {
+			sends = append(sends, graph.NewSend("summarize", doc))
+		}
+		return sends
+	})
+	g.AddEdge("summarize", "combine")
+	g.AddEdge("combine", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+	return runnable
+}
+
+func TestSend_MapReduce(t *testing.T) {
+	var combined int32
+	runnable := newMapReduceGraph(t, &combined)
+
+	res, err := runnable.Invoke(context.Background(), map[string]interface{}{
+		"docs": []string{"a", "b", "c"},
+	})
+	require.NoError(t, err)
+
+	state := res.(map[string]interface{})
+	// Results are merged in the order of the Sends, whatever the completion order
+	assert.Equal(t, []string{"A", "B", "C"}, state["summaries"])
+	assert.Equal(t, "A,B,C", state["result"])
+	assert.Equal(t, int32(1), combined)
+}
+
+func TestSend_EmptyEndsBranch(t *testing.T) {
+	var combined int32
+	runnable := newMapReduceGraph(t, &combined)
+
+	res, err := runnable.Invoke(context.Background(), map[string]interface{}{
+		"docs": []string{},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, res.(map[string]interface{})["summaries"])
+	assert.Equal(t, int32(0), combined)
+}
+
+func TestSend_StreamsEachTask(t *testing.T) {
+	var combined int32
+	runnable := newMapReduceGraph(t, &combined)
+
+	chunks := drain(runnable.Stream(context.Background(), map[string]interface{}{
+		"docs": []string{"x", "y"},
+	}, nil, graph.StreamModeUpdates))
+
+	var nodes []string
+	for _, chunk := range chunks {
+		require.NoError(t, chunk.Err)
+		nodes = append(nodes, chunk.Node)
+	}
+	assert.ElementsMatch(t, []string{"split", "summarize", "summarize", "combine"}, nodes)
+}
+
+func TestSend_FromCommandGoto(t *testing.T) {
+	g := graph.NewStateGraph()
+	schema := graph.NewMapSchema()
+	schema.RegisterReducer("log", graph.AppendReducer)
+	g.SetSchema(schema)
+
+	g.AddNode("start", "start", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return &graph.Command{
+			Update: map[string]interface{}{"log": []string{"start"}},
+			Goto: []interface{}{
+				"audit",
+				graph.Send{Node: "worker", Arg: 1},
+				graph.Send{Node: "worker", Arg: 2},
+			},
+		}, nil
+	})
+	g.AddNode("audit", "audit", func(ctx context.Context, state interface{}) (interface{}, error) {
+		// Nodes named in Goto still receive the shared state
+		assert.Equal(t, []string{"start"}, state.(map[string]interface{})["log"])
+		return map[string]interface{}{"log": []string{"audit"}}, nil
+	})
+	g.AddNode("worker", "worker", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return map[string]interface{}{"log": []string{fmt.Sprintf("worker-%d", state.(int))}}, nil
+	})
+	g.SetEntryPoint("start")
+	g.AddEdge("audit", graph.END)
+	g.AddEdge("worker", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	res, err := runnable.Invoke(context.Background(), map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"start", "audit", "worker-1", "worker-2"}, res.(map[string]interface{})["log"])
+}
+
+func TestSend_UnknownNode(t *testing.T) {
+	g := graph.NewStateGraph()
+	g.AddNode("start", "start", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return &graph.Command{Goto: graph.NewSend("missing", nil)}, nil
+	})
+	g.SetEntryPoint("start")
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	_, err = runnable.Invoke(context.Background(), nil)
+	assert.ErrorIs(t, err, graph.ErrNodeNotFound)
+}
+
+func TestSend_InvalidGoto(t *testing.T) {
+	g := graph.NewStateGraph()
+	g.AddNode("start", "start", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return &graph.Command{Goto: 42}, nil
+	})
+	g.SetEntryPoint("start")
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	_, err = runnable.Invoke(context.Background(), nil)
+	assert.ErrorContains(t, err, "unsupported Command.Goto type int")
+}
+
+type fanOutState struct {
+	Items   []string `json:"items"`
+	Item    string   `json:"item"`
+	Results []string `json:"results" reducer:"append"`
+}
+
+func TestSend_TypedStateGraph(t *testing.T) {
+	g := graph.NewTypedStateGraph[fanOutState]()
+	g.AddNode("plan", "plan", func(ctx context.Context, state fanOutState) (fanOutState, error) {
+		return fanOutState{}, nil
+	})
+	g.AddNode("process", "process", func(ctx context.Context, state fanOutState) (fanOutState, error) {
+		return fanOutState{Results: []string{state.Item + "!"}}, nil
+	})
+	g.SetEntryPoint("plan")
+	g.AddConditionalSendEdge("plan", func(ctx context.Context, state fanOutState) []graph.Send {
+		sends := make([]graph.Send, len(state.Items))
+		for i, item := range state.Items {
+			sends[i] = graph.NewSend("process", fanOutState{Item: item})
+		}
+		return sends
+	})
+	g.AddEdge("process", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	res, err := runnable.Invoke(context.Background(), fanOutState{Items: []string{"go", "py"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go!", "py!"}, res.Results)
+}
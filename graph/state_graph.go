@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 )
@@ -16,8 +17,11 @@ type StateGraph struct {
 	// edges is a slice of Edge objects representing the connections between nodes
 	edges []Edge
 
-	// conditionalEdges contains a map between "From" node, while "To" node is derived based on the condition
-	conditionalEdges map[string]func(ctx context.Context, state interface{}) string
+	// conditionalEdges contains a map between "From" node, while "To" nodes are derived based on the condition
+	conditionalEdges map[string]conditionalEdge
+
+	// sendEdges contains the routers returning the Sends to execute after a node
+	sendEdges map[string]func(ctx context.Context, state interface{}) ([]Send, error)
 
 	// entryPoint is the name of the entry point node in the graph
 	entryPoint string
@@ -30,12 +34,24 @@ type StateGraph struct {
 
 	// Schema defines the state structure and update logic
 	Schema StateSchema
+
+	// strictValidation makes Compile fail on validation warnings
+	strictValidation bool
 }
 
-// RetryPolicy defines how to handle node failures
+// RetryPolicy defines how to handle node failures.
+// It applies to the nodes without a WithRetry option.
 type RetryPolicy struct {
 	MaxRetries      int
 	BackoffStrategy BackoffStrategy
+
+	// RetryIf determines if an error should trigger a retry, see RetryOn and RetryOnType.
+	// It takes precedence over RetryableErrors.
+	RetryIf func(error) bool
+
+	// RetryableErrors lists substrings of the error messages that trigger a retry.
+	//
+	// Deprecated: matching error messages is brittle, use RetryIf.
 	RetryableErrors []string
 }
 
@@ -53,16 +69,23 @@ const (
 func NewStateGraph() *StateGraph {
 	return &StateGraph{
 		nodes:            make(map[string]Node),
-		conditionalEdges: make(map[string]func(ctx context.Context, state interface{}) string),
+		conditionalEdges: make(map[string]conditionalEdge),
 	}
 }
 
-// AddNode adds a new node to the state graph with the given name, description and function
-func (g *StateGraph) AddNode(name string, description string, fn func(ctx context.Context, state interface{}) (interface{}, error)) {
+// AddNode adds a new node to the state graph with the given name, description and function.
+//
+// Options set execution policies of the node: WithCache, WithCircuitBreaker, WithRetry,
+// WithRateLimit and WithTimeout. They can be combined and are always applied in this
+// order, from the outermost, whatever the order of the options: a cached result skips
+// the other policies, a retried call counts once for the circuit breaker, and each
+// attempt counts against the rate limit and has its own timeout.
+func (g *StateGraph) AddNode(name string, description string, fn func(ctx context.Context, state interface{}) (interface{}, error), opts ...NodeOption) {
 	g.nodes[name] = Node{
 		Name:        name,
 		Description: description,
 		Function:    fn,
+		policy:      newNodePolicy(name, opts),
 	}
 }
 
@@ -74,9 +97,34 @@ func (g *StateGraph) AddEdge(from, to string) {
 	})
 }
 
-// AddConditionalEdge adds a conditional edge where the target node is determined at runtime
-func (g *StateGraph) AddConditionalEdge(from string, condition func(ctx context.Context, state interface{}) string) {
-	g.conditionalEdges[from] = condition
+// AddConditionalEdge adds a conditional edge where the target node is determined at runtime.
+// The nodes the condition can return may be declared as destinations, so that Compile
+// can check them and the Exporter can draw them.
+//
+// To route to several nodes or to map labels to node names, use AddConditionalEdges.
+func (g *StateGraph) AddConditionalEdge(from string, condition func(ctx context.Context, state interface{}) string, destinations ...string) {
+	g.addConditionalEdge(from, func(ctx context.Context, state interface{}) (string, error) {
+		return condition(ctx, state), nil
+	}, destinations...)
+}
+
+func (g *StateGraph) addConditionalEdge(from string, condition func(ctx context.Context, state interface{}) (string, error), destinations ...string) {
+	edge := conditionalEdge{
+		route: func(ctx context.Context, state interface{}) ([]string, error) {
+			next, err := condition(ctx, state)
+			if err != nil {
+				return nil, err
+			}
+			if next == "" {
+				return nil, fmt.Errorf("conditional edge returned empty next node from %s", from)
+			}
+			return []string{next}, nil
+		},
+	}
+	if len(destinations) > 0 {
+		edge.destinations = destinations
+	}
+	g.conditionalEdges[from] = edge
 }
 
 // SetEntryPoint sets the entry point node name for the state graph
@@ -103,12 +151,20 @@ func (g *StateGraph) SetSchema(schema StateSchema) {
 type StateRunnable struct {
 	graph  *StateGraph
 	tracer *Tracer
+
+	// nodeExecutor overrides how node functions are called, e.g. to notify node listeners
+	nodeExecutor func(ctx context.Context, node Node, state interface{}) (interface{}, error)
+
+	// eventNotifier is notified of the events raised by node policies, e.g. to notify node listeners
+	eventNotifier func(ctx context.Context, node string, event NodeEvent, state interface{}, err error)
 }
 
-// Compile compiles the state graph and returns a StateRunnable instance
+// Compile validates the state graph and returns a StateRunnable instance.
+// It returns a *GraphValidationError when the graph has validation errors, or
+// warnings in strict mode; see Validate.
 func (g *StateGraph) Compile() (*StateRunnable, error) {
-	if g.entryPoint == "" {
-		return nil, ErrEntryPointNotSet
+	if err := g.validateForCompile(); err != nil {
+		return nil, err
 	}
 
 	return &StateRunnable{
@@ -125,8 +181,10 @@ func (r *StateRunnable) SetTracer(tracer *Tracer) {
 // WithTracer returns a new StateRunnable with the given tracer
 func (r *StateRunnable) WithTracer(tracer *Tracer) *StateRunnable {
 	return &StateRunnable{
-		graph:  r.graph,
-		tracer: tracer,
+		graph:         r.graph,
+		tracer:        tracer,
+		nodeExecutor:  r.nodeExecutor,
+		eventNotifier: r.eventNotifier,
 	}
 }
 
@@ -135,10 +193,23 @@ func (r *StateRunnable) Invoke(ctx context.Context, initialState interface{}) (i
 	return r.InvokeWithConfig(ctx, initialState, nil)
 }
 
-// InvokeWithConfig executes the compiled state graph with the given input state and config
-func (r *StateRunnable) InvokeWithConfig(ctx context.Context, initialState interface{}, config *Config) (interface{}, error) {
+// InvokeWithConfig executes the compiled state graph with the given input state and config.
+//
+// The graph runs in super-steps whose order is deterministic. The nodes of a step are
+// ordered by the nodes of the previous step that led to them, then by the declaration
+// order of their edges (or the order of Command.Goto), followed by the Send tasks in
+// the order they were created. The nodes of a step run concurrently, but their results
+// are always merged into the state in that order, so non-commutative reducers produce
+// the same state on every run.
+func (r *StateRunnable) InvokeWithConfig(ctx context.Context, initialState interface{}, config *Config) (result interface{}, err error) {
+	// Runs started from a node of a streamed run are streamed with it. A config
+	// created only to carry the stream is not exposed through GetConfig.
+	hasConfig := config != nil
+	ctx, config = joinStream(ctx, config)
+
 	state := initialState
 	currentNodes := []string{r.graph.entryPoint}
+	var currentSends []Send
 
 	// Handle ResumeFrom
 	if config != nil && len(config.ResumeFrom) > 0 {
@@ -147,17 +218,23 @@ func (r *StateRunnable) InvokeWithConfig(ctx context.Context, initialState inter
 
 	// Generate run ID for callbacks
 	runID := generateRunID()
+	ctx = withRunID(ctx, runID)
 
 	// Notify callbacks of graph start
 	if config != nil {
 		// Inject config into context
-		ctx = WithConfig(ctx, config)
+		if hasConfig {
+			ctx = WithConfig(ctx, config)
+		}
 
 		// Inject ResumeValue
 		if config.ResumeValue != nil {
 			ctx = WithResumeValue(ctx, config.ResumeValue)
 		}
 
+		// Make the callbacks available to LLM calls made by nodes and nested runs
+		ctx = withInheritedCallbacks(ctx, config.Callbacks)
+
 		if len(config.Callbacks) > 0 {
 			serialized := map[string]interface{}{
 				"name": "graph",
@@ -171,25 +248,79 @@ func (r *StateRunnable) InvokeWithConfig(ctx context.Context, initialState inter
 		}
 	}
 
-	// Start graph tracing if tracer is set
-	var graphSpan *TraceSpan
+	// Apply the whole-run deadline if configured
+	ctx, cancel, timeoutCause := withRunTimeout(ctx, config)
+	defer cancel()
+
+	// Start graph tracing if tracer is set. The spans of the steps, nodes, LLM and tool
+	// calls of the run are nested in the graph span through the context.
+	threadID, _ := threadConfig(config)
+	var graphSpan, stepSpan *TraceSpan
 	if r.tracer != nil {
 		graphSpan = r.tracer.StartSpan(ctx, TraceEventGraphStart, "graph")
 		graphSpan.State = initialState
+		graphSpan.Metadata["run_id"] = runID
+		if threadID != "" {
+			graphSpan.Metadata["thread_id"] = threadID
+		}
+		ctx = r.tracer.contextWithSpan(ctx, graphSpan)
+		ctx = withTracer(ctx, r.tracer)
+
+		// End the spans on every return, with the error the run failed with
+		graphCtx := ctx
+		defer func() {
+			if stepSpan != nil {
+				r.tracer.EndSpan(graphCtx, stepSpan, state, err)
+			}
+			r.tracer.EndSpan(graphCtx, graphSpan, result, err)
+		}()
 	}
 
-	for len(currentNodes) > 0 {
+	// Results of the tasks that completed before the resumed step failed
+	var pendingWrites []PendingWrite
+	ctx, pendingWrites = takePendingWrites(ctx)
+
+	limit := recursionLimit(config)
+	step := 0
+	baseCtx := ctx
+
+	for len(currentNodes) > 0 || len(currentSends) > 0 {
 		// Filter out END nodes
-		activeNodes := make([]string, 0, len(currentNodes))
-		for _, node := range currentNodes {
-			if node != END {
-				activeNodes = append(activeNodes, node)
+		tasks := newTasks(withoutEnd(currentNodes), currentSends, state)
+		currentNodes = taskNodes(tasks)
+		currentSends = nil
+
+		if len(tasks) == 0 {
+			break
+		}
+
+		// Check recursion limit
+		step++
+		if step > limit {
+			err := &GraphRecursionError{
+				Limit:     limit,
+				State:     state,
+				NextNodes: currentNodes,
 			}
+			notifyChainError(ctx, config, err, runID)
+			return state, err
+		}
+		ctx = withStep(baseCtx, step)
+
+		// Trace the step, its nodes are traced inside it
+		if r.tracer != nil {
+			stepSpan = r.tracer.StartSpan(ctx, TraceEventStepStart, "")
+			stepSpan.State = state
+			stepSpan.Metadata["step"] = step
+			stepSpan.Metadata["nodes"] = append([]string(nil), currentNodes...)
+			ctx = r.tracer.contextWithSpan(ctx, stepSpan)
 		}
-		currentNodes = activeNodes
 
-		if len(currentNodes) == 0 {
-			break
+		// Check the run deadline before starting a new step
+		if isRunTimeout(ctx, timeoutCause) {
+			err := newGraphTimeoutError(config, state, currentNodes, step)
+			notifyChainError(ctx, config, err, runID)
+			return state, err
 		}
 
 		// Check InterruptBefore
@@ -205,17 +336,28 @@ func (r *StateRunnable) InvokeWithConfig(ctx context.Context, initialState inter
 
 		// Execute nodes in parallel
 		var wg sync.WaitGroup
-		results := make([]interface{}, len(currentNodes))
-		errorsList := make([]error, len(currentNodes))
+		results := make([]interface{}, len(tasks))
+		errorsList := make([]error, len(tasks))
 
-		for i, nodeName := range currentNodes {
-			node, ok := r.graph.nodes[nodeName]
+		// Track completed nodes so a timeout can report the ones still running
+		var completedMutex sync.Mutex
+		completed := make([]bool, len(tasks))
+
+		for i, t := range tasks {
+			node, ok := r.graph.nodes[t.node]
 			if !ok {
-				return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, nodeName)
+				return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, t.node)
+			}
+
+			// The task completed before the step failed, reuse its result
+			if write, ok := pendingWriteFor(pendingWrites, i, t.node); ok {
+				results[i] = write.Value
+				completed[i] = true
+				continue
 			}
 
 			wg.Add(1)
-			go func(index int, n Node, name string) {
+			go func(index int, n Node, name string, input interface{}) {
 				defer wg.Done()
 
 				// Recover from panics in node execution
@@ -227,16 +369,34 @@ func (r *StateRunnable) InvokeWithConfig(ctx context.Context, initialState inter
 
 				// Start node tracing
 				var nodeSpan *TraceSpan
+				nodeCtx := ctx
 				if r.tracer != nil {
 					nodeSpan = r.tracer.StartSpan(ctx, TraceEventNodeStart, name)
-					nodeSpan.State = state
+					nodeSpan.State = input
+					nodeSpan.Metadata["step"] = step
+					if threadID != "" {
+						nodeSpan.Metadata["thread_id"] = threadID
+					}
+					nodeCtx = r.tracer.contextWithSpan(nodeCtx, nodeSpan)
 				}
 
 				var err error
 				var res interface{}
 
+				nodeCtx = withNodeName(nodeCtx, name)
+				nodeCtx = withNodeEventReporter(nodeCtx, func(ctx context.Context, event NodeEvent, err error, metadata map[string]interface{}) {
+					r.notifyPolicyEvent(ctx, config, name, input, event, err, metadata)
+				})
+				notifyNodeEvent(nodeCtx, config, NodeEventStart, name, input, nil)
+
 				// Execute node with retry logic
-				res, err = r.executeNodeWithRetry(ctx, n, state)
+				res, err = r.executeNodeWithRetry(nodeCtx, n, input)
+
+				if err != nil {
+					notifyNodeEvent(nodeCtx, config, NodeEventError, name, input, err)
+				} else {
+					notifyNodeEvent(nodeCtx, config, NodeEventComplete, name, res, nil)
+				}
 
 				// End node tracing
 				if r.tracer != nil && nodeSpan != nil {
@@ -262,6 +422,9 @@ func (r *StateRunnable) InvokeWithConfig(ctx context.Context, initialState inter
 				}
 
 				results[index] = res
+				completedMutex.Lock()
+				completed[index] = true
+				completedMutex.Unlock()
 
 				// Notify callbacks of node execution (as tool)
 				if config != nil && len(config.Callbacks) > 0 {
@@ -275,14 +438,49 @@ func (r *StateRunnable) InvokeWithConfig(ctx context.Context, initialState inter
 						cb.OnToolEnd(ctx, convertStateToString(res), nodeRunID)
 					}
 				}
-			}(i, node, nodeName)
+			}(i, node, t.node, t.input)
+		}
+		pendingWrites = nil
+
+		// Wait for all nodes, or stop waiting when the run deadline is reached
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-timeoutDone(ctx, config):
+			if isRunTimeout(ctx, timeoutCause) {
+				completedMutex.Lock()
+				var running []string
+				for i, name := range currentNodes {
+					if !completed[i] {
+						running = append(running, name)
+					}
+				}
+				completedMutex.Unlock()
+				notifyStepFailure(ctx, config, state, currentNodes, results, completed, &completedMutex)
+				err := newGraphTimeoutError(config, state, running, step)
+				notifyChainError(ctx, config, err, runID)
+				return state, err
+			}
+			<-done
 		}
-
-		wg.Wait()
 
 		// Check for errors
 		for _, err := range errorsList {
 			if err != nil {
+				notifyStepFailure(ctx, config, state, currentNodes, results, completed, &completedMutex)
+
+				// Nodes that failed because the run deadline was reached
+				if isRunTimeout(ctx, timeoutCause) {
+					timeoutErr := newGraphTimeoutError(config, state, failedNodes(currentNodes, errorsList), step)
+					notifyChainError(ctx, config, timeoutErr, runID)
+					return state, timeoutErr
+				}
+
 				// Check for NodeInterrupt
 				var nodeInterrupt *NodeInterrupt
 				if errors.As(err, &nodeInterrupt) {
@@ -291,6 +489,7 @@ func (r *StateRunnable) InvokeWithConfig(ctx context.Context, initialState inter
 						State:          state,
 						InterruptValue: nodeInterrupt.Value,
 						NextNodes:      []string{nodeInterrupt.Node},
+						Subgraph:       nodeInterrupt.Subgraph,
 					}
 				}
 
@@ -304,8 +503,16 @@ func (r *StateRunnable) InvokeWithConfig(ctx context.Context, initialState inter
 			}
 		}
 
+		// Trace the edges followed to the next step
+		traceEdge := func(from, to string) {
+			if r.tracer != nil {
+				r.tracer.TraceEdgeTraversal(ctx, from, to)
+			}
+		}
+
 		// Process results and check for Commands
 		var nextNodesFromCommands []string
+		var sendsFromCommands []Send
 		processedResults := make([]interface{}, len(results))
 
 		for i, res := range results {
@@ -313,13 +520,17 @@ func (r *StateRunnable) InvokeWithConfig(ctx context.Context, initialState inter
 				// It's a Command
 				processedResults[i] = cmd.Update
 
-				if cmd.Goto != nil {
-					switch g := cmd.Goto.(type) {
-					case string:
-						nextNodesFromCommands = append(nextNodesFromCommands, g)
-					case []string:
-						nextNodesFromCommands = append(nextNodesFromCommands, g...)
-					}
+				nodes, sends, err := gotoTargets(cmd.Goto)
+				if err != nil {
+					return nil, fmt.Errorf("error in node %s: %w", currentNodes[i], err)
+				}
+				nextNodesFromCommands = append(nextNodesFromCommands, nodes...)
+				sendsFromCommands = append(sendsFromCommands, sends...)
+				for _, n := range nodes {
+					traceEdge(currentNodes[i], n)
+				}
+				for _, send := range sends {
+					traceEdge(currentNodes[i], send.Node)
 				}
 			} else {
 				// Regular result
@@ -352,7 +563,7 @@ func (r *StateRunnable) InvokeWithConfig(ctx context.Context, initialState inter
 		// Determine next nodes
 		var nextNodesList []string
 
-		if len(nextNodesFromCommands) > 0 {
+		if len(nextNodesFromCommands) > 0 || len(sendsFromCommands) > 0 {
 			// Command.Goto overrides static edges
 			// We deduplicate
 			seen := make(map[string]bool)
@@ -362,63 +573,75 @@ func (r *StateRunnable) InvokeWithConfig(ctx context.Context, initialState inter
 					nextNodesList = append(nextNodesList, n)
 				}
 			}
+			currentSends = sendsFromCommands
 		} else {
-			// Use static edges
+			// Use static edges. The next nodes are ordered by the nodes that ran, then by
+			// the declaration order of their edges, so the step order is reproducible.
 			nextNodesSet := make(map[string]bool)
+			addNext := func(from, node string) {
+				traceEdge(from, node)
+				if !nextNodesSet[node] {
+					nextNodesSet[node] = true
+					nextNodesList = append(nextNodesList, node)
+				}
+			}
+			routed := make(map[string]bool)
 
 			for _, nodeName := range currentNodes {
+				// Several Sends to the same node follow its edges once
+				if routed[nodeName] {
+					continue
+				}
+				routed[nodeName] = true
+
+				// Send edges schedule tasks with their own input
+				router, hasSend := r.graph.sendEdges[nodeName]
+				if hasSend {
+					sends, err := router(ctx, state)
+					if err != nil {
+						return nil, err
+					}
+					for _, send := range sends {
+						traceEdge(nodeName, send.Node)
+					}
+					currentSends = append(currentSends, sends...)
+				}
+
 				// First check for conditional edges
-				nextNodeFn, hasConditional := r.graph.conditionalEdges[nodeName]
+				conditional, hasConditional := r.graph.conditionalEdges[nodeName]
 				if hasConditional {
-					nextNode := nextNodeFn(ctx, state)
-					if nextNode == "" {
-						return nil, fmt.Errorf("conditional edge returned empty next node from %s", nodeName)
+					nextNodes, err := conditional.next(ctx, nodeName, state)
+					if err != nil {
+						return nil, err
+					}
+					for _, nextNode := range nextNodes {
+						addNext(nodeName, nextNode)
 					}
-					nextNodesSet[nextNode] = true
 				} else {
 					// Then check regular edges
 					foundNext := false
 					for _, edge := range r.graph.edges {
 						if edge.From == nodeName {
-							nextNodesSet[edge.To] = true
+							addNext(nodeName, edge.To)
 							foundNext = true
 							// Do NOT break here, to allow fan-out (multiple edges from same node)
 						}
 					}
 
-					if !foundNext {
+					if !foundNext && !hasSend {
 						return nil, fmt.Errorf("%w: %s", ErrNoOutgoingEdge, nodeName)
 					}
 				}
 			}
-
-			// Update nextNodesList from set
-			for node := range nextNodesSet {
-				nextNodesList = append(nextNodesList, node)
-			}
-		}
-
-		// Check InterruptAfter
-		if config != nil && len(config.InterruptAfter) > 0 {
-			for _, node := range currentNodes {
-				for _, interrupt := range config.InterruptAfter {
-					if node == interrupt {
-						return state, &GraphInterrupt{
-							Node:      node,
-							State:     state,
-							NextNodes: nextNodesList,
-						}
-					}
-				}
-			}
 		}
 
 		// Keep track of nodes that ran for callbacks
 		nodesRan := make([]string, len(currentNodes))
 		copy(nodesRan, currentNodes)
 
-		// Update currentNodes
+		// Update currentNodes; the pending Sends are reported with them
 		currentNodes = nextNodesList
+		nextNodesList = append(withoutEnd(nextNodesList), sendNodes(currentSends)...)
 
 		// Cleanup ephemeral state if supported
 		if cleaningSchema, ok := r.graph.Schema.(CleaningStateSchema); ok {
@@ -435,15 +658,36 @@ func (r *StateRunnable) InvokeWithConfig(ctx context.Context, initialState inter
 					} else {
 						nodeName = fmt.Sprintf("step:%v", nodesRan)
 					}
-					gcb.OnGraphStep(ctx, nodeName, state)
+					gcb.OnGraphStep(withNextNodes(ctx, nextNodesList), nodeName, state)
+				}
+				if reporter, ok := cb.(stepErrorReporter); ok {
+					if err := reporter.stepError(); err != nil {
+						notifyChainError(ctx, config, err, runID)
+						return state, err
+					}
 				}
 			}
 		}
-	}
 
-	// End graph tracing
-	if r.tracer != nil && graphSpan != nil {
-		r.tracer.EndSpan(ctx, graphSpan, state, nil)
+		if r.tracer != nil {
+			r.tracer.EndSpan(ctx, stepSpan, state, nil)
+			stepSpan = nil
+		}
+
+		// Check InterruptAfter once the step has been reported, so checkpoints include it
+		if config != nil && len(config.InterruptAfter) > 0 {
+			for _, node := range nodesRan {
+				for _, interrupt := range config.InterruptAfter {
+					if node == interrupt {
+						return state, &GraphInterrupt{
+							Node:      node,
+							State:     state,
+							NextNodes: nextNodesList,
+						}
+					}
+				}
+			}
+		}
 	}
 
 	// Notify callbacks of graph end
@@ -457,8 +701,145 @@ func (r *StateRunnable) InvokeWithConfig(ctx context.Context, initialState inter
 	return state, nil
 }
 
-// executeNodeWithRetry executes a node with retry logic based on the retry policy
+// withoutEnd returns the nodes that still have to be executed
+func withoutEnd(nodes []string) []string {
+	activeNodes := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		if node != END {
+			activeNodes = append(activeNodes, node)
+		}
+	}
+	return activeNodes
+}
+
+// recursionLimit returns the maximum number of super-steps allowed by the config
+func recursionLimit(config *Config) int {
+	if config != nil && config.RecursionLimit > 0 {
+		return config.RecursionLimit
+	}
+	return DefaultRecursionLimit
+}
+
+// withRunTimeout derives a context bounded by Config.Timeout. It returns the
+// cancellation cause of the deadline, or nil when no timeout is configured. Each run
+// has its own cause, so a nested run does not report the timeout of its parent.
+func withRunTimeout(ctx context.Context, config *Config) (context.Context, context.CancelFunc, error) {
+	if config == nil || config.Timeout == nil || *config.Timeout <= 0 {
+		return ctx, func() {}, nil
+	}
+	cause := errors.New("graph run timeout")
+	ctx, cancel := context.WithTimeoutCause(ctx, *config.Timeout, cause)
+	return ctx, cancel, cause
+}
+
+// isRunTimeout reports whether the context was cancelled by the deadline of the run
+// with the given timeout cause
+func isRunTimeout(ctx context.Context, cause error) bool {
+	return cause != nil && ctx.Err() != nil && context.Cause(ctx) == cause
+}
+
+// timeoutDone returns the channel closed when the run deadline is reached,
+// or nil (blocking forever) when no timeout is configured
+func timeoutDone(ctx context.Context, config *Config) <-chan struct{} {
+	if config == nil || config.Timeout == nil || *config.Timeout <= 0 {
+		return nil
+	}
+	return ctx.Done()
+}
+
+// failedNodes returns the names of the nodes that returned an error
+func failedNodes(nodes []string, errs []error) []string {
+	var failed []string
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, nodes[i])
+		}
+	}
+	return failed
+}
+
+// newGraphTimeoutError builds the error returned when the run deadline is reached
+func newGraphTimeoutError(config *Config, state interface{}, running []string, step int) *GraphTimeoutError {
+	return &GraphTimeoutError{
+		Timeout:      *config.Timeout,
+		State:        state,
+		RunningNodes: running,
+		Step:         step,
+	}
+}
+
+// notifyStepFailure notifies the config callbacks implementing stepFailureHandler that
+// a step failed or was interrupted, with the results of the tasks that completed
+func notifyStepFailure(ctx context.Context, config *Config, state interface{}, nodes []string, results []interface{}, completed []bool, completedMutex *sync.Mutex) {
+	if config == nil {
+		return
+	}
+
+	completedMutex.Lock()
+	var writes []PendingWrite
+	for i, done := range completed {
+		if done {
+			writes = append(writes, PendingWrite{Task: i, Node: nodes[i], Value: results[i]})
+		}
+	}
+	completedMutex.Unlock()
+
+	failure := &stepFailure{state: state, nodes: nodes, writes: writes}
+	for _, cb := range config.Callbacks {
+		if h, ok := cb.(stepFailureHandler); ok {
+			h.onStepFailure(ctx, failure)
+		}
+	}
+}
+
+// notifyChainError notifies the config callbacks that the run failed
+func notifyChainError(ctx context.Context, config *Config, err error, runID string) {
+	if config == nil {
+		return
+	}
+	for _, cb := range config.Callbacks {
+		cb.OnChainError(ctx, err, runID)
+	}
+}
+
+// policyTraceEvents maps the events raised by node policies to trace events
+var policyTraceEvents = map[NodeEvent]TraceEvent{
+	NodeEventRetry:     TraceEventNodeRetry,
+	NodeEventCacheHit:  TraceEventCacheHit,
+	NodeEventCacheMiss: TraceEventCacheMiss,
+}
+
+// notifyPolicyEvent reports an event raised by the policies of a node, such as a
+// retry or a cache hit, to the callbacks, node listeners and tracer
+func (r *StateRunnable) notifyPolicyEvent(ctx context.Context, config *Config, name string, state interface{}, event NodeEvent, err error, metadata map[string]interface{}) {
+	notifyNodeEvent(ctx, config, event, name, state, err)
+
+	if r.eventNotifier != nil {
+		r.eventNotifier(ctx, name, event, state, err)
+	}
+
+	if traceEvent, ok := policyTraceEvents[event]; ok && r.tracer != nil {
+		span := r.tracer.StartSpan(ctx, traceEvent, name)
+		for k, v := range metadata {
+			span.Metadata[k] = v
+		}
+		r.tracer.EndSpan(ctx, span, state, err)
+	}
+}
+
+// executeNodeWithRetry executes a node with its policies, or with retry logic based
+// on the retry policy of the graph when it has none
 func (r *StateRunnable) executeNodeWithRetry(ctx context.Context, node Node, state interface{}) (interface{}, error) {
+	execute := node.Function
+	if r.nodeExecutor != nil {
+		execute = func(ctx context.Context, state interface{}) (interface{}, error) {
+			return r.nodeExecutor(ctx, node, state)
+		}
+	}
+	if node.policy != nil {
+		return node.policy.execute(ctx, node.Name, state, execute)
+	}
+
 	var lastErr error
 
 	maxRetries := 1 // Default: no retries
@@ -467,7 +848,7 @@ func (r *StateRunnable) executeNodeWithRetry(ctx context.Context, node Node, sta
 	}
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
-		result, err := node.Function(ctx, state)
+		result, err := execute(ctx, state)
 		if err == nil {
 			return result, nil
 		}
@@ -479,6 +860,7 @@ func (r *StateRunnable) executeNodeWithRetry(ctx context.Context, node Node, sta
 			if r.isRetryableError(err) {
 				// Apply backoff strategy
 				delay := r.calculateBackoffDelay(attempt)
+				reportRetry(ctx, attempt+1, err, delay)
 				if delay > 0 {
 					select {
 					case <-time.After(delay):
@@ -501,13 +883,17 @@ func (r *StateRunnable) executeNodeWithRetry(ctx context.Context, node Node, sta
 
 // isRetryableError checks if an error is retryable based on the retry policy
 func (r *StateRunnable) isRetryableError(err error) bool {
-	if r.graph.retryPolicy == nil {
+	if r.graph.retryPolicy == nil || isInterrupt(err) {
 		return false
 	}
 
+	if r.graph.retryPolicy.RetryIf != nil {
+		return r.graph.retryPolicy.RetryIf(err)
+	}
+
 	errorStr := err.Error()
 	for _, retryablePattern := range r.graph.retryPolicy.RetryableErrors {
-		if contains(errorStr, retryablePattern) {
+		if strings.Contains(errorStr, retryablePattern) {
 			return true
 		}
 	}
@@ -515,24 +901,6 @@ func (r *StateRunnable) isRetryableError(err error) bool {
 	return false
 }
 
-// contains is a simple string contains check
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr ||
-		(len(substr) > 0 && len(s) > len(substr) &&
-			(s[:len(substr)] == substr || s[len(s)-len(substr):] == substr ||
-				findSubstring(s, substr))))
-}
-
-// findSubstring finds if substr exists in s
-func findSubstring(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
-	}
-	return false
-}
-
 // calculateBackoffDelay calculates the delay for retry based on the backoff strategy
 func (r *StateRunnable) calculateBackoffDelay(attempt int) time.Duration {
 	if r.graph.retryPolicy == nil {
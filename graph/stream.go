@@ -0,0 +1,277 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StreamChunk is an item produced by StateRunnable.Stream
+type StreamChunk struct {
+	// Mode is the stream mode that produced the chunk
+	Mode StreamMode
+
+	// Namespace identifies the nested run that produced the chunk: it lists the
+	// nodes of the parent runs that invoked it, outermost first. It is empty for
+	// chunks of the streamed graph itself.
+	Namespace []string
+
+	// Node is the node that produced the chunk. For StreamModeValues it is the
+	// node (or nodes, formatted as "step:[a b]") that ran during the step.
+	Node string
+
+	// Step is the super-step of the run identified by Namespace, counted from 1
+	Step int
+
+	// Data is the payload of the chunk:
+	//   - StreamModeValues: the full state after the step
+	//   - StreamModeUpdates: the update returned by the node
+	//   - StreamModeMessages: an LLM token (string)
+	//   - StreamModeCustom: the value written by the node
+	//   - StreamModeDebug: a StreamEvent describing the event
+	// For the final error chunk, it is the state reached before the error.
+	Data interface{}
+
+	// Metadata contains additional data, e.g. "run_id" and "message_id" for tokens
+	Metadata map[string]interface{}
+
+	// Err is set on the last chunk when the run fails
+	Err error
+}
+
+// Stream executes the graph and streams its progress as labeled chunks.
+//
+// Several modes can be subscribed to at once; when none is given, StreamModeValues
+// is used. Runs nested in a node, such as subgraphs and prebuilt agents invoked from
+// a node, are streamed as well, with their Namespace set.
+//
+// The channel is closed when the run finishes. When it fails, the last chunk has Err
+// set. The caller must consume the channel until it is closed, or cancel ctx.
+func (r *StateRunnable) Stream(ctx context.Context, input interface{}, config *Config, modes ...StreamMode) <-chan StreamChunk {
+	if len(modes) == 0 {
+		modes = []StreamMode{StreamModeValues}
+	}
+
+	out := make(chan StreamChunk, 100)
+	collector := newStreamCollector(ctx, out, modes)
+
+	runConfig := &Config{}
+	if config != nil {
+		*runConfig = *config
+	}
+	runConfig.Callbacks = append(append([]CallbackHandler{}, runConfig.Callbacks...), collector)
+
+	// The streamed run is the root of the namespace, even when called from a node
+	runCtx := withNodeName(withStreamCollector(ctx, collector), "")
+	runCtx = context.WithValue(runCtx, streamNamespaceKey{}, []string(nil))
+
+	go func() {
+		defer collector.close()
+
+		result, err := r.InvokeWithConfig(runCtx, input, runConfig)
+		if err != nil {
+			collector.send(ctx, StreamChunk{Data: result, Err: err})
+		}
+	}()
+
+	return out
+}
+
+// Stream executes the graph with listener notifications and streams its progress.
+// See StateRunnable.Stream.
+func (lr *ListenableRunnable) Stream(ctx context.Context, input interface{}, config *Config, modes ...StreamMode) <-chan StreamChunk {
+	return lr.runnable.Stream(ctx, input, config, modes...)
+}
+
+// nodeEventHandler is implemented by callbacks that observe node inputs and
+// results as they are, rather than serialized like the tool callbacks.
+type nodeEventHandler interface {
+	onNodeEvent(ctx context.Context, event NodeEvent, nodeName string, state interface{}, err error)
+}
+
+// notifyNodeEvent notifies the config callbacks implementing nodeEventHandler
+func notifyNodeEvent(ctx context.Context, config *Config, event NodeEvent, nodeName string, state interface{}, err error) {
+	if config == nil {
+		return
+	}
+	for _, cb := range config.Callbacks {
+		if h, ok := cb.(nodeEventHandler); ok {
+			h.onNodeEvent(ctx, event, nodeName, state, err)
+		}
+	}
+}
+
+type streamCollectorKey struct{}
+
+type streamNamespaceKey struct{}
+
+// withStreamCollector adds the collector of the active stream to the context
+func withStreamCollector(ctx context.Context, collector *streamCollector) context.Context {
+	return context.WithValue(ctx, streamCollectorKey{}, collector)
+}
+
+// activeStream returns the collector of the stream the context belongs to
+func activeStream(ctx context.Context) *streamCollector {
+	collector, _ := ctx.Value(streamCollectorKey{}).(*streamCollector)
+	return collector
+}
+
+// streamNamespace returns the namespace of the run the context belongs to
+func streamNamespace(ctx context.Context) []string {
+	namespace, _ := ctx.Value(streamNamespaceKey{}).([]string)
+	return namespace
+}
+
+// joinStream attaches a run started from a node of a streamed run to the stream.
+// It returns the context and config of the nested run; the config is only
+// replaced when the collector has to be added to its callbacks.
+func joinStream(ctx context.Context, config *Config) (context.Context, *Config) {
+	collector := activeStream(ctx)
+	parentNode := GetNodeName(ctx)
+	if collector == nil || parentNode == "" {
+		return ctx, config
+	}
+
+	parentNamespace := streamNamespace(ctx)
+	namespace := make([]string, 0, len(parentNamespace)+1)
+	namespace = append(namespace, parentNamespace...)
+	namespace = append(namespace, parentNode)
+	ctx = context.WithValue(ctx, streamNamespaceKey{}, namespace)
+
+	nested := &Config{}
+	if config != nil {
+		if containsCallback(config.Callbacks, collector) {
+			return ctx, config
+		}
+		*nested = *config
+	}
+	nested.Callbacks = append(append([]CallbackHandler{}, nested.Callbacks...), collector)
+	return ctx, nested
+}
+
+// streamCollector converts the callbacks of a run into stream chunks
+type streamCollector struct {
+	NoOpCallbackHandler
+
+	ctx   context.Context
+	out   chan StreamChunk
+	modes map[StreamMode]bool
+
+	mutex  sync.RWMutex
+	done   chan struct{}
+	closed bool
+}
+
+func newStreamCollector(ctx context.Context, out chan StreamChunk, modes []StreamMode) *streamCollector {
+	c := &streamCollector{
+		ctx:   ctx,
+		out:   out,
+		modes: make(map[StreamMode]bool),
+		done:  make(chan struct{}),
+	}
+	for _, mode := range modes {
+		c.modes[mode] = true
+	}
+	return c
+}
+
+// emit sends a chunk of the given mode labeled from the context
+func (c *streamCollector) emit(ctx context.Context, mode StreamMode, node string, data interface{}, metadata map[string]interface{}) {
+	if !c.modes[mode] {
+		return
+	}
+	c.send(ctx, StreamChunk{
+		Mode:      mode,
+		Namespace: streamNamespace(ctx),
+		Node:      node,
+		Step:      GetStep(ctx),
+		Data:      data,
+		Metadata:  metadata,
+	})
+}
+
+// send delivers a chunk, blocking until it is consumed, the stream is
+// cancelled or the run has finished.
+func (c *streamCollector) send(ctx context.Context, chunk StreamChunk) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if c.closed {
+		return
+	}
+
+	select {
+	case c.out <- chunk:
+	case <-c.ctx.Done():
+	case <-c.done:
+	}
+}
+
+// close closes the stream once the run has finished. Nodes still running after
+// a timeout are unblocked and their chunks dropped.
+func (c *streamCollector) close() {
+	close(c.done)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.closed = true
+	close(c.out)
+}
+
+func (c *streamCollector) debug(ctx context.Context, event NodeEvent, node string, state interface{}, err error, metadata map[string]interface{}) {
+	if !c.modes[StreamModeDebug] {
+		return
+	}
+	c.emit(ctx, StreamModeDebug, node, StreamEvent{
+		Timestamp: time.Now(),
+		NodeName:  node,
+		Event:     event,
+		State:     state,
+		Error:     err,
+		Metadata:  metadata,
+	}, nil)
+}
+
+func (c *streamCollector) onNodeEvent(ctx context.Context, event NodeEvent, nodeName string, state interface{}, err error) {
+	if event == NodeEventComplete {
+		update := state
+		if cmd, ok := state.(*Command); ok {
+			update = cmd.Update
+		}
+		c.emit(ctx, StreamModeUpdates, nodeName, update, nil)
+	}
+	c.debug(ctx, event, nodeName, state, err, nil)
+}
+
+// OnGraphStep implements GraphCallbackHandler
+func (c *streamCollector) OnGraphStep(ctx context.Context, stepNode string, state interface{}) {
+	c.emit(ctx, StreamModeValues, stepNode, state, nil)
+	c.debug(ctx, "graph_step", stepNode, state, nil, nil)
+}
+
+// OnLLMToken implements LLMTokenHandler
+func (c *streamCollector) OnLLMToken(ctx context.Context, chunk []byte, runID string, messageID string) {
+	c.emit(ctx, StreamModeMessages, GetNodeName(ctx), string(chunk), map[string]interface{}{
+		"run_id":     runID,
+		"message_id": messageID,
+	})
+}
+
+// OnLLMStart implements CallbackHandler
+func (c *streamCollector) OnLLMStart(ctx context.Context, serialized map[string]interface{}, prompts []string, runID string, parentRunID *string, tags []string, metadata map[string]interface{}) {
+	c.debug(ctx, EventLLMStart, GetNodeName(ctx), prompts, nil, metadata)
+}
+
+// OnLLMEnd implements CallbackHandler
+func (c *streamCollector) OnLLMEnd(ctx context.Context, response interface{}, runID string) {
+	c.debug(ctx, EventLLMEnd, GetNodeName(ctx), response, nil, map[string]interface{}{
+		"run_id":     GetRunID(ctx),
+		"message_id": runID,
+	})
+}
+
+// OnCustomEvent implements CustomEventHandler
+func (c *streamCollector) OnCustomEvent(ctx context.Context, data interface{}) {
+	node := GetNodeName(ctx)
+	c.emit(ctx, StreamModeCustom, node, data, nil)
+	c.debug(ctx, EventCustom, node, data, nil, nil)
+}
@@ -0,0 +1,199 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func newCounterGraph(t *testing.T) *graph.StateRunnable {
+	g := graph.NewStateGraph()
+	schema := graph.NewMapSchema()
+	schema.RegisterReducer("steps", graph.AppendReducer)
+	g.SetSchema(schema)
+
+	for _, name := range []string{"a", "b"} {
+		name := name
+		g.AddNode(name, name, func(ctx context.Context, state interface{}) (interface{}, error) {
+			return map[string]interface{}{"steps": []string{name}}, nil
+		})
+	}
+	g.SetEntryPoint("a")
+	g.AddEdge("a", "b")
+	g.AddEdge("b", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+	return runnable
+}
+
+func drain(ch <-chan graph.StreamChunk) []graph.StreamChunk {
+	var chunks []graph.StreamChunk
+	for chunk := range ch {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+func TestStateRunnableStream_MultipleModes(t *testing.T) {
+	runnable := newCounterGraph(t)
+
+	chunks := drain(runnable.Stream(context.Background(), map[string]interface{}{}, nil, graph.StreamModeUpdates, graph.StreamModeValues))
+	require.Len(t, chunks, 4)
+
+	expected := []struct {
+		mode graph.StreamMode
+		node string
+		step int
+	}{
+		{graph.StreamModeUpdates, "a", 1},
+		{graph.StreamModeValues, "a", 1},
+		{graph.StreamModeUpdates, "b", 2},
+		{graph.StreamModeValues, "b", 2},
+	}
+	for i, e := range expected {
+		assert.Equal(t, e.mode, chunks[i].Mode)
+		assert.Equal(t, e.node, chunks[i].Node)
+		assert.Equal(t, e.step, chunks[i].Step)
+		assert.Empty(t, chunks[i].Namespace)
+		assert.NoError(t, chunks[i].Err)
+	}
+
+	assert.Equal(t, map[string]interface{}{"steps": []string{"b"}}, chunks[2].Data)
+	final := chunks[3].Data.(map[string]interface{})
+	assert.Equal(t, []string{"a", "b"}, final["steps"])
+}
+
+func TestStateRunnableStream_DefaultsToValues(t *testing.T) {
+	runnable := newCounterGraph(t)
+
+	chunks := drain(runnable.Stream(context.Background(), map[string]interface{}{}, nil))
+	require.Len(t, chunks, 2)
+	for _, chunk := range chunks {
+		assert.Equal(t, graph.StreamModeValues, chunk.Mode)
+	}
+}
+
+func TestStateRunnableStream_Debug(t *testing.T) {
+	runnable := newCounterGraph(t)
+
+	chunks := drain(runnable.Stream(context.Background(), map[string]interface{}{}, nil, graph.StreamModeDebug))
+
+	var events []graph.NodeEvent
+	for _, chunk := range chunks {
+		event, ok := chunk.Data.(graph.StreamEvent)
+		require.True(t, ok)
+		events = append(events, event.Event)
+	}
+	assert.Equal(t, []graph.NodeEvent{
+		graph.NodeEventStart, graph.NodeEventComplete, "graph_step",
+		graph.NodeEventStart, graph.NodeEventComplete, "graph_step",
+	}, events)
+}
+
+func TestStateRunnableStream_NestedRunsAndMessages(t *testing.T) {
+	model := &streamingLLM{chunks: []string{"to", "ken"}}
+
+	child := graph.NewStateGraph()
+	child.AddNode("llm", "llm", func(ctx context.Context, state interface{}) (interface{}, error) {
+		resp, err := graph.GenerateContent(ctx, model, []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "hi")})
+		if err != nil {
+			return nil, err
+		}
+		return resp.Choices[0].Content, nil
+	})
+	child.SetEntryPoint("llm")
+	child.AddEdge("llm", graph.END)
+	childRunnable, err := child.Compile()
+	require.NoError(t, err)
+
+	parent := graph.NewStateGraph()
+	parent.AddNode("research", "research", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return childRunnable.Invoke(ctx, state)
+	})
+	parent.SetEntryPoint("research")
+	parent.AddEdge("research", graph.END)
+	parentRunnable, err := parent.Compile()
+	require.NoError(t, err)
+
+	chunks := drain(parentRunnable.Stream(context.Background(), "input", nil, graph.StreamModeMessages, graph.StreamModeUpdates))
+
+	var tokens []string
+	var updates []graph.StreamChunk
+	for _, chunk := range chunks {
+		switch chunk.Mode {
+		case graph.StreamModeMessages:
+			assert.Equal(t, []string{"research"}, chunk.Namespace)
+			assert.Equal(t, "llm", chunk.Node)
+			assert.Equal(t, 1, chunk.Step)
+			assert.NotEmpty(t, chunk.Metadata["message_id"])
+			tokens = append(tokens, chunk.Data.(string))
+		case graph.StreamModeUpdates:
+			updates = append(updates, chunk)
+		}
+	}
+	assert.Equal(t, []string{"to", "ken"}, tokens)
+
+	// The child update is labeled with its namespace, then the parent node completes
+	require.Len(t, updates, 2)
+	assert.Equal(t, []string{"research"}, updates[0].Namespace)
+	assert.Equal(t, "llm", updates[0].Node)
+	assert.Empty(t, updates[1].Namespace)
+	assert.Equal(t, "research", updates[1].Node)
+	assert.Equal(t, "token", updates[1].Data)
+}
+
+func TestStateRunnableStream_Error(t *testing.T) {
+	g := graph.NewStateGraph()
+	g.AddNode("ok", "ok", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return "partial", nil
+	})
+	g.AddNode("fail", "fail", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+	g.SetEntryPoint("ok")
+	g.AddEdge("ok", "fail")
+	g.AddEdge("fail", graph.END)
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	chunks := drain(runnable.Stream(context.Background(), "start", &graph.Config{RecursionLimit: 10}, graph.StreamModeValues))
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "partial", chunks[0].Data)
+	assert.ErrorContains(t, chunks[1].Err, "boom")
+}
+
+func TestStateRunnableStream_Cancel(t *testing.T) {
+	g := graph.NewStateGraph()
+	g.AddNode("loop", "loop", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return state, nil
+	})
+	g.SetEntryPoint("loop")
+	g.AddEdge("loop", "loop")
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := runnable.Stream(ctx, "x", &graph.Config{RecursionLimit: 1000}, graph.StreamModeValues)
+
+	// Stop consuming after the first chunk
+	<-stream
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		drain(stream)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream was not closed after cancellation")
+	}
+}
@@ -0,0 +1,39 @@
+package graph
+
+import "context"
+
+// StreamWriter pushes a custom payload into the active stream
+type StreamWriter func(data interface{})
+
+// CustomEventHandler is implemented by callback handlers that receive the custom
+// payloads written by nodes, e.g. StreamingListener.
+type CustomEventHandler interface {
+	// OnCustomEvent is called for each payload written with a StreamWriter.
+	// The node name is available through GetNodeName.
+	OnCustomEvent(ctx context.Context, data interface{})
+}
+
+// GetStreamWriter returns a writer that a node can use to report intermediate
+// progress, e.g. documents retrieved or code being executed.
+//
+// The payloads are emitted under StreamModeCustom by StateRunnable.Stream and by a
+// StreamingListener, including from runs nested in a node. When no callback of the
+// current run handles custom events, the returned writer does nothing, so nodes can
+// call it unconditionally.
+func GetStreamWriter(ctx context.Context) StreamWriter {
+	var handlers []CustomEventHandler
+	for _, cb := range inheritedCallbacks(ctx) {
+		if h, ok := cb.(CustomEventHandler); ok {
+			handlers = append(handlers, h)
+		}
+	}
+	if len(handlers) == 0 {
+		return func(interface{}) {}
+	}
+
+	return func(data interface{}) {
+		for _, h := range handlers {
+			h.OnCustomEvent(ctx, data)
+		}
+	}
+}
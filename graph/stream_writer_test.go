@@ -0,0 +1,91 @@
+package graph_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProgressGraph(t *testing.T) *graph.StateRunnable {
+	g := graph.NewStateGraph()
+	g.AddNode("work", "work", func(ctx context.Context, state interface{}) (interface{}, error) {
+		write := graph.GetStreamWriter(ctx)
+		for i := 1; i <= 2; i++ {
+			write(map[string]interface{}{"progress": i})
+		}
+		return "done", nil
+	})
+	g.SetEntryPoint("work")
+	g.AddEdge("work", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+	return runnable
+}
+
+func TestGetStreamWriter_Stream(t *testing.T) {
+	runnable := newProgressGraph(t)
+
+	chunks := drain(runnable.Stream(context.Background(), nil, nil, graph.StreamModeCustom))
+	require.Len(t, chunks, 2)
+	for i, chunk := range chunks {
+		assert.Equal(t, graph.StreamModeCustom, chunk.Mode)
+		assert.Equal(t, "work", chunk.Node)
+		assert.Equal(t, 1, chunk.Step)
+		assert.Equal(t, map[string]interface{}{"progress": i + 1}, chunk.Data)
+	}
+}
+
+func TestGetStreamWriter_NestedRun(t *testing.T) {
+	child := newProgressGraph(t)
+
+	parent := graph.NewStateGraph()
+	parent.AddNode("delegate", "delegate", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return child.Invoke(ctx, state)
+	})
+	parent.SetEntryPoint("delegate")
+	parent.AddEdge("delegate", graph.END)
+	runnable, err := parent.Compile()
+	require.NoError(t, err)
+
+	chunks := drain(runnable.Stream(context.Background(), nil, nil, graph.StreamModeCustom, graph.StreamModeValues))
+	require.Len(t, chunks, 4)
+	for _, chunk := range chunks[:2] {
+		assert.Equal(t, graph.StreamModeCustom, chunk.Mode)
+		assert.Equal(t, []string{"delegate"}, chunk.Namespace)
+		assert.Equal(t, "work", chunk.Node)
+	}
+}
+
+func TestGetStreamWriter_StreamingListener(t *testing.T) {
+	runnable := newProgressGraph(t)
+
+	events := make(chan graph.StreamEvent, 100)
+	listener := graph.NewStreamingListener(events, graph.StreamConfig{Mode: graph.StreamModeCustom})
+	_, err := runnable.InvokeWithConfig(context.Background(), nil, &graph.Config{
+		Callbacks: []graph.CallbackHandler{listener},
+	})
+	require.NoError(t, err)
+
+	collected := collectEvents(events)
+	require.Len(t, collected, 2)
+	for i, event := range collected {
+		assert.Equal(t, graph.EventCustom, event.Event)
+		assert.Equal(t, "work", event.NodeName)
+		assert.Equal(t, map[string]interface{}{"progress": i + 1}, event.State)
+	}
+}
+
+func TestGetStreamWriter_NoOpWithoutStream(t *testing.T) {
+	// Outside of a run, and in a run without handlers, writing is a no-op
+	assert.NotPanics(t, func() {
+		graph.GetStreamWriter(context.Background())("ignored")
+	})
+
+	res, err := newProgressGraph(t).Invoke(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "done", res)
+}
@@ -18,6 +18,8 @@ const (
 	StreamModeMessages StreamMode = "messages"
 	// StreamModeDebug emits all events (default)
 	StreamModeDebug StreamMode = "debug"
+	// StreamModeCustom emits the payloads written by nodes through GetStreamWriter
+	StreamModeCustom StreamMode = "custom"
 )
 
 // StreamConfig configures streaming behavior
@@ -125,8 +127,10 @@ func (sl *StreamingListener) shouldEmit(event StreamEvent) bool {
 		// Emit node outputs (ToolEnd, ChainEnd, NodeEventComplete)
 		return event.Event == EventToolEnd || event.Event == EventChainEnd || event.Event == NodeEventComplete
 	case StreamModeMessages:
-		// Emit LLM events
-		return event.Event == EventLLMEnd || event.Event == EventLLMStart
+		// Emit LLM events and tokens
+		return event.Event == EventLLMEnd || event.Event == EventLLMStart || event.Event == EventToken
+	case StreamModeCustom:
+		return event.Event == EventCustom
 	default:
 		return true
 	}
@@ -175,6 +179,7 @@ func (sl *StreamingListener) OnChainError(ctx context.Context, err error, runID
 func (sl *StreamingListener) OnLLMStart(ctx context.Context, serialized map[string]interface{}, prompts []string, runID string, parentRunID *string, tags []string, metadata map[string]interface{}) {
 	sl.emitEvent(StreamEvent{
 		Timestamp: time.Now(),
+		NodeName:  GetNodeName(ctx),
 		Event:     EventLLMStart,
 		Metadata:  metadata,
 		State:     prompts,
@@ -184,8 +189,37 @@ func (sl *StreamingListener) OnLLMStart(ctx context.Context, serialized map[stri
 func (sl *StreamingListener) OnLLMEnd(ctx context.Context, response interface{}, runID string) {
 	sl.emitEvent(StreamEvent{
 		Timestamp: time.Now(),
+		NodeName:  GetNodeName(ctx),
 		Event:     EventLLMEnd,
 		State:     response,
+		Metadata: map[string]interface{}{
+			"run_id":     GetRunID(ctx),
+			"message_id": runID,
+		},
+	})
+}
+
+// OnCustomEvent implements CustomEventHandler, emitting each payload as an EventCustom event
+func (sl *StreamingListener) OnCustomEvent(ctx context.Context, data interface{}) {
+	sl.emitEvent(StreamEvent{
+		Timestamp: time.Now(),
+		NodeName:  GetNodeName(ctx),
+		Event:     EventCustom,
+		State:     data,
+	})
+}
+
+// OnLLMToken implements LLMTokenHandler, emitting each chunk as an EventToken event
+func (sl *StreamingListener) OnLLMToken(ctx context.Context, chunk []byte, runID string, messageID string) {
+	sl.emitEvent(StreamEvent{
+		Timestamp: time.Now(),
+		NodeName:  GetNodeName(ctx),
+		Event:     EventToken,
+		State:     string(chunk),
+		Metadata: map[string]interface{}{
+			"run_id":     runID,
+			"message_id": messageID,
+		},
 	})
 }
 
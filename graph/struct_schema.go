@@ -0,0 +1,282 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// structReducers maps the values accepted by the `reducer` struct tag to reducers.
+var structReducers = map[string]Reducer{
+	"overwrite":    OverwriteReducer,
+	"append":       AppendReducer,
+	"add_messages": AddMessages,
+}
+
+// StructSchema implements StateSchema for struct states.
+//
+// A node returns an update of type S: every field left at its zero value is kept
+// from the current state, every other field is merged with the field's reducer, or
+// overwrites the current value when the field has no reducer. To set a field to its
+// zero value, embed Changed in S and mark the field with MarkChanged.
+//
+// An update is not a full state: a node returning the state it received appends
+// every `append` and `add_messages` field to itself again. Return only the fields
+// the node changes.
+//
+// Reducers are declared with struct tags or registered by field name:
+//
+//	type State struct {
+//		Messages []llms.MessageContent `reducer:"add_messages"`
+//		Steps    []string              `reducer:"append"`
+//		Scratch  string                `ephemeral:"true"`
+//		Count    int
+//	}
+//
+// Fields tagged `ephemeral:"true"` are reset to their zero value after each step.
+type StructSchema[S any] struct {
+	reducers  map[string]Reducer
+	ephemeral map[string]bool
+	fields    map[string]reflect.StructField
+}
+
+// Changed is embedded in a struct state to mark the fields a node update sets, so
+// they are applied even when they hold their zero value:
+//
+//	type State struct {
+//		graph.Changed
+//		Count int
+//		Done  bool
+//	}
+//
+//	func reset(ctx context.Context, state State) (State, error) {
+//		var update State
+//		update.MarkChanged("Count", "Done")
+//		return update, nil
+//	}
+//
+// Marked fields with a reducer are still merged with it. The marks are not part of
+// the state: they are cleared by the update and not serialized.
+type Changed struct {
+	fields map[string]bool
+}
+
+// MarkChanged marks fields as set by the update
+func (c *Changed) MarkChanged(fields ...string) {
+	if c.fields == nil {
+		c.fields = make(map[string]bool, len(fields))
+	}
+	for _, field := range fields {
+		c.fields[field] = true
+	}
+}
+
+// IsChanged reports whether field is marked as set by the update
+func (c Changed) IsChanged(field string) bool {
+	return c.fields[field]
+}
+
+// clearChanged removes the marks
+func (c *Changed) clearChanged() {
+	c.fields = nil
+}
+
+// changedMarker is implemented by states embedding Changed
+type changedMarker interface {
+	IsChanged(field string) bool
+}
+
+var changedType = reflect.TypeOf(Changed{})
+
+// NewStructSchema creates a schema for the struct type S from its struct tags.
+func NewStructSchema[S any]() (*StructSchema[S], error) {
+	stateType := reflect.TypeOf((*S)(nil)).Elem()
+	if stateType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("struct schema requires a struct state, got %s", stateType)
+	}
+
+	schema := &StructSchema[S]{
+		reducers:  make(map[string]Reducer),
+		ephemeral: make(map[string]bool),
+		fields:    make(map[string]reflect.StructField),
+	}
+
+	for i := 0; i < stateType.NumField(); i++ {
+		field := stateType.Field(i)
+		if !field.IsExported() || (field.Anonymous && field.Type == changedType) {
+			continue
+		}
+		schema.fields[field.Name] = field
+
+		if tag, ok := field.Tag.Lookup("reducer"); ok {
+			reducer, known := structReducers[tag]
+			if !known {
+				return nil, fmt.Errorf("field %s: unknown reducer %q", field.Name, tag)
+			}
+			schema.reducers[field.Name] = reducer
+		}
+
+		if field.Tag.Get("ephemeral") == "true" {
+			schema.ephemeral[field.Name] = true
+		}
+	}
+
+	return schema, nil
+}
+
+// RegisterReducer sets the reducer of a field, overriding its struct tag.
+func (s *StructSchema[S]) RegisterReducer(field string, reducer Reducer) error {
+	if _, ok := s.fields[field]; !ok {
+		return fmt.Errorf("state %T has no exported field %s", *new(S), field)
+	}
+	s.reducers[field] = reducer
+	return nil
+}
+
+// RegisterFieldReducer registers a typed reducer for a field of S.
+// It returns an error when the field does not exist or its type is not F.
+func RegisterFieldReducer[S, F any](schema *StructSchema[S], field string, reducer func(current, update F) (F, error)) error {
+	structField, ok := schema.fields[field]
+	if !ok {
+		return fmt.Errorf("state %T has no exported field %s", *new(S), field)
+	}
+	if fieldType := reflect.TypeOf((*F)(nil)).Elem(); structField.Type != fieldType {
+		return fmt.Errorf("field %s has type %s, reducer expects %s", field, structField.Type, fieldType)
+	}
+
+	schema.reducers[field] = func(current, update interface{}) (interface{}, error) {
+		currentValue, _ := current.(F)
+		updateValue, _ := update.(F)
+		return reducer(currentValue, updateValue)
+	}
+	return nil
+}
+
+// Init returns the zero value of S.
+func (s *StructSchema[S]) Init() interface{} {
+	var state S
+	return state
+}
+
+// Update merges the non-zero fields of new into current.
+func (s *StructSchema[S]) Update(current, new interface{}) (interface{}, error) {
+	currentState, err := AsState[S](current)
+	if err != nil {
+		return nil, fmt.Errorf("current state: %w", err)
+	}
+	update, err := AsState[S](new)
+	if err != nil {
+		return nil, fmt.Errorf("new state: %w", err)
+	}
+
+	result := reflect.ValueOf(&currentState).Elem()
+	updateValue := reflect.ValueOf(update)
+	marker, _ := any(update).(changedMarker)
+
+	for name, field := range s.fields {
+		newField := updateValue.FieldByIndex(field.Index)
+		if newField.IsZero() && (marker == nil || !marker.IsChanged(name)) {
+			continue
+		}
+
+		target := result.FieldByIndex(field.Index)
+		reducer, ok := s.reducers[name]
+		if !ok {
+			target.Set(newField)
+			continue
+		}
+
+		merged, err := reducer(target.Interface(), newField.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("failed to reduce field %s: %w", name, err)
+		}
+		if err := setField(target, merged); err != nil {
+			return nil, fmt.Errorf("failed to reduce field %s: %w", name, err)
+		}
+	}
+
+	if cleared, ok := any(&currentState).(interface{ clearChanged() }); ok {
+		cleared.clearChanged()
+	}
+	return currentState, nil
+}
+
+// Cleanup resets ephemeral fields to their zero value.
+func (s *StructSchema[S]) Cleanup(state interface{}) interface{} {
+	if len(s.ephemeral) == 0 {
+		return state
+	}
+
+	typed, ok := state.(S)
+	if !ok {
+		return state
+	}
+
+	value := reflect.ValueOf(&typed).Elem()
+	for name := range s.ephemeral {
+		field := value.FieldByIndex(s.fields[name].Index)
+		field.Set(reflect.Zero(field.Type()))
+	}
+	return typed
+}
+
+// setField assigns a reducer result to a struct field, converting slices whose
+// element types were widened by the reducer (e.g. []interface{}) back to the field type.
+func setField(target reflect.Value, value interface{}) error {
+	if value == nil {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(target.Type()) {
+		target.Set(v)
+		return nil
+	}
+
+	if v.Kind() == reflect.Slice && target.Kind() == reflect.Slice {
+		elemType := target.Type().Elem()
+		converted := reflect.MakeSlice(target.Type(), 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			if elem.Kind() == reflect.Interface {
+				elem = elem.Elem()
+			}
+			if !elem.IsValid() || !elem.Type().AssignableTo(elemType) {
+				return fmt.Errorf("cannot assign %T to %s", value, target.Type())
+			}
+			converted = reflect.Append(converted, elem)
+		}
+		target.Set(converted)
+		return nil
+	}
+
+	if v.Type().ConvertibleTo(target.Type()) {
+		target.Set(v.Convert(target.Type()))
+		return nil
+	}
+
+	return fmt.Errorf("cannot assign %T to %s", value, target.Type())
+}
+
+// AsState converts a state value produced by the engine to S.
+// States restored from JSON-based checkpoint stores come back as generic maps;
+// they are decoded into S through their JSON representation.
+func AsState[S any](value interface{}) (S, error) {
+	var state S
+	if value == nil {
+		return state, nil
+	}
+	if typed, ok := value.(S); ok {
+		return typed, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return state, fmt.Errorf("cannot convert %T to %T: %w", value, state, err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("cannot convert %T to %T: %w", value, state, err)
+	}
+	return state, nil
+}
@@ -2,42 +2,142 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 )
 
-// Subgraph represents a nested graph that can be used as a node
+// Subgraph represents a nested graph that can be used as a node.
+//
+// The graph is compiled once when the Subgraph is created. When the parent run is
+// checkpointed, the subgraph run is checkpointed in the same store under the namespace
+// of its node (see CheckpointNamespace). An interrupt raised inside the subgraph
+// interrupts the parent run at the subgraph node, with GraphInterrupt.Subgraph set;
+// when the parent is resumed from its checkpoint, the subgraph resumes at the node
+// that was interrupted. Without checkpointing, the subgraph restarts from its entry point.
 type Subgraph struct {
 	name     string
 	graph    *StateGraph
 	runnable *Runnable
+
+	inputMapper  func(ctx context.Context, state interface{}) (interface{}, error)
+	outputMapper func(ctx context.Context, state interface{}, result interface{}) (interface{}, error)
+}
+
+// SubgraphOption is a function that configures a Subgraph
+type SubgraphOption func(*Subgraph)
+
+// WithInputMapper sets the function converting the parent state into the input of the subgraph.
+// By default the parent state is passed unchanged.
+func WithInputMapper(mapper func(ctx context.Context, state interface{}) (interface{}, error)) SubgraphOption {
+	return func(s *Subgraph) {
+		s.inputMapper = mapper
+	}
+}
+
+// WithOutputMapper sets the function converting the final state of the subgraph into the
+// update returned to the parent graph; it also receives the parent state. By default the
+// final state of the subgraph is returned unchanged.
+func WithOutputMapper(mapper func(ctx context.Context, state interface{}, result interface{}) (interface{}, error)) SubgraphOption {
+	return func(s *Subgraph) {
+		s.outputMapper = mapper
+	}
 }
 
 // NewSubgraph creates a new subgraph
-func NewSubgraph(name string, graph *StateGraph) (*Subgraph, error) {
+func NewSubgraph(name string, graph *StateGraph, opts ...SubgraphOption) (*Subgraph, error) {
 	runnable, err := graph.Compile()
 	if err != nil {
 		return nil, fmt.Errorf("failed to compile subgraph %s: %w", name, err)
 	}
 
-	return &Subgraph{
+	s := &Subgraph{
 		name:     name,
 		graph:    graph,
 		runnable: runnable,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
 }
 
 // Execute runs the subgraph as a node
 func (s *Subgraph) Execute(ctx context.Context, state interface{}) (interface{}, error) {
-	result, err := s.runnable.Invoke(ctx, state)
+	var config *Config
+	var listener *CheckpointListener
+	var resume *Checkpoint
+
+	// Checkpoint the subgraph run under the namespace of its node
+	if parent := activeCheckpointListener(ctx); parent != nil {
+		node := GetNodeName(ctx)
+		if node == "" {
+			node = s.name
+		}
+		listener = parent.nested(ctx, node)
+
+		var err error
+		resume, err = listener.pendingInterrupt(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("subgraph %s: failed to load checkpoints: %w", s.name, err)
+		}
+
+		config = &Config{Callbacks: []CallbackHandler{listener}}
+		if parentConfig := GetConfig(ctx); parentConfig != nil {
+			config.Tags = parentConfig.Tags
+			config.Metadata = parentConfig.Metadata
+			config.Configurable = parentConfig.Configurable
+		}
+		ctx = withCheckpointListener(ctx, listener)
+	}
+
+	// Resume the interrupted subgraph run, or start a new one
+	input := state
+	if resume != nil {
+		input = resume.State
+		config.ResumeFrom = resume.Next
+		listener.resumeFrom(resume)
+		ctx = withPendingWrites(ctx, resume.PendingWrites)
+	} else if s.inputMapper != nil {
+		var err error
+		input, err = s.inputMapper(ctx, state)
+		if err != nil {
+			return nil, fmt.Errorf("subgraph %s input mapping failed: %w", s.name, err)
+		}
+	}
+
+	// Trace the subgraph run inside the span of its node
+	runnable := s.runnable
+	if tracer := activeTracer(ctx); tracer != nil && runnable.tracer == nil {
+		runnable = runnable.WithTracer(tracer)
+	}
+
+	result, err := runnable.InvokeWithConfig(ctx, input, config)
+	if listener != nil {
+		if saveErr := listener.finish(ctx, err); saveErr != nil {
+			return nil, fmt.Errorf("subgraph %s: %w", s.name, saveErr)
+		}
+	}
 	if err != nil {
+		var graphInterrupt *GraphInterrupt
+		if errors.As(err, &graphInterrupt) {
+			return nil, &NodeInterrupt{Value: graphInterrupt.InterruptValue, Subgraph: graphInterrupt}
+		}
 		return nil, fmt.Errorf("subgraph %s execution failed: %w", s.name, err)
 	}
+
+	if s.outputMapper != nil {
+		result, err = s.outputMapper(ctx, state, result)
+		if err != nil {
+			return nil, fmt.Errorf("subgraph %s output mapping failed: %w", s.name, err)
+		}
+	}
 	return result, nil
 }
 
 // AddSubgraph adds a subgraph as a node in the parent graph
-func (g *StateGraph) AddSubgraph(name string, subgraph *StateGraph) error {
-	sg, err := NewSubgraph(name, subgraph)
+func (g *StateGraph) AddSubgraph(name string, subgraph *StateGraph, opts ...SubgraphOption) error {
+	sg, err := NewSubgraph(name, subgraph, opts...)
 	if err != nil {
 		return err
 	}
@@ -47,10 +147,10 @@ func (g *StateGraph) AddSubgraph(name string, subgraph *StateGraph) error {
 }
 
 // CreateSubgraph creates and adds a subgraph using a builder function
-func (g *StateGraph) CreateSubgraph(name string, builder func(*StateGraph)) error {
+func (g *StateGraph) CreateSubgraph(name string, builder func(*StateGraph), opts ...SubgraphOption) error {
 	subgraph := NewStateGraph()
 	builder(subgraph)
-	return g.AddSubgraph(name, subgraph)
+	return g.AddSubgraph(name, subgraph, opts...)
 }
 
 // CompositeGraph allows composing multiple graphs together
@@ -111,6 +211,11 @@ type RecursiveSubgraph struct {
 	graph     *StateGraph
 	maxDepth  int
 	condition func(interface{}, int) bool // Should continue recursion?
+
+	// The graph is compiled once, on the first execution
+	compileOnce sync.Once
+	runnable    *Runnable
+	compileErr  error
 }
 
 // NewRecursiveSubgraph creates a new recursive subgraph
@@ -143,13 +248,15 @@ func (rs *RecursiveSubgraph) executeRecursive(ctx context.Context, state interfa
 		return state, nil
 	}
 
-	// Compile and execute the graph
-	runnable, err := rs.graph.Compile()
-	if err != nil {
-		return nil, fmt.Errorf("failed to compile recursive subgraph at depth %d: %w", depth, err)
+	// Compile the graph once and execute it
+	rs.compileOnce.Do(func() {
+		rs.runnable, rs.compileErr = rs.graph.Compile()
+	})
+	if rs.compileErr != nil {
+		return nil, fmt.Errorf("failed to compile recursive subgraph at depth %d: %w", depth, rs.compileErr)
 	}
 
-	result, err := runnable.Invoke(ctx, state)
+	result, err := rs.runnable.Invoke(ctx, state)
 	if err != nil {
 		return nil, fmt.Errorf("recursive execution failed at depth %d: %w", depth, err)
 	}
@@ -175,24 +282,29 @@ func (g *StateGraph) AddNestedConditionalSubgraph(
 	name string,
 	router func(interface{}) string,
 	subgraphs map[string]*StateGraph,
+	opts ...SubgraphOption,
 ) error {
+	// Compile the subgraphs once
+	compiled := make(map[string]*Subgraph, len(subgraphs))
+	for _, subgraphName := range sortedKeys(subgraphs) {
+		sg, err := NewSubgraph(subgraphName, subgraphs[subgraphName], opts...)
+		if err != nil {
+			return err
+		}
+		compiled[subgraphName] = sg
+	}
+
 	// Create a wrapper node that routes to different subgraphs
 	g.AddNode(name, "Nested conditional subgraph: "+name, func(ctx context.Context, state interface{}) (interface{}, error) {
 		// Determine which subgraph to use
 		subgraphName := router(state)
 
-		subgraph, exists := subgraphs[subgraphName]
+		subgraph, exists := compiled[subgraphName]
 		if !exists {
 			return nil, fmt.Errorf("subgraph %s not found", subgraphName)
 		}
 
-		// Compile and execute the selected subgraph
-		runnable, err := subgraph.Compile()
-		if err != nil {
-			return nil, fmt.Errorf("failed to compile subgraph %s: %w", subgraphName, err)
-		}
-
-		return runnable.Invoke(ctx, state)
+		return subgraph.Execute(ctx, state)
 	})
 
 	return nil
@@ -0,0 +1,146 @@
+package graph_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubgraph_InputOutputMappers(t *testing.T) {
+	child := graph.NewStateGraph()
+	child.AddNode("shout", "shout", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return state.(string) + "!", nil
+	})
+	child.SetEntryPoint("shout")
+	child.AddEdge("shout", graph.END)
+
+	parent := graph.NewStateGraph()
+	err := parent.AddSubgraph("child", child,
+		graph.WithInputMapper(func(ctx context.Context, state interface{}) (interface{}, error) {
+			return state.(map[string]interface{})["text"], nil
+		}),
+		graph.WithOutputMapper(func(ctx context.Context, state interface{}, result interface{}) (interface{}, error) {
+			return map[string]interface{}{"text": state.(map[string]interface{})["text"], "reply": result}, nil
+		}),
+	)
+	require.NoError(t, err)
+	parent.SetEntryPoint("child")
+	parent.AddEdge("child", graph.END)
+
+	runnable, err := parent.Compile()
+	require.NoError(t, err)
+
+	res, err := runnable.Invoke(context.Background(), map[string]interface{}{"text": "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"text": "hi", "reply": "hi!"}, res)
+}
+
+func TestNestedConditionalSubgraph_CompilesOnce(t *testing.T) {
+	invalid := graph.NewStateGraph()
+	invalid.AddNode("a", "a", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return state, nil
+	})
+
+	parent := graph.NewStateGraph()
+	err := parent.AddNestedConditionalSubgraph("route", func(state interface{}) string {
+		return "invalid"
+	}, map[string]*graph.StateGraph{"invalid": invalid})
+	assert.ErrorIs(t, err, graph.ErrEntryPointNotSet)
+}
+
+// newInterruptingSubgraphRunnable builds a parent graph whose "review" node is a
+// subgraph asking for approval between its "draft" and "publish" nodes
+func newInterruptingSubgraphRunnable(t *testing.T, runs map[string]int) *graph.CheckpointableRunnable {
+	child := graph.NewStateGraph()
+	child.AddNode("draft", "draft", func(ctx context.Context, state interface{}) (interface{}, error) {
+		runs["draft"]++
+		return state.(string) + " draft", nil
+	})
+	child.AddNode("approve", "approve", func(ctx context.Context, state interface{}) (interface{}, error) {
+		runs["approve"]++
+		answer, err := graph.Interrupt(ctx, "approve?")
+		if err != nil {
+			return nil, err
+		}
+		return state.(string) + " " + answer.(string), nil
+	})
+	child.AddNode("publish", "publish", func(ctx context.Context, state interface{}) (interface{}, error) {
+		runs["publish"]++
+		return state.(string) + " published", nil
+	})
+	child.SetEntryPoint("draft")
+	child.AddEdge("draft", "approve")
+	child.AddEdge("approve", "publish")
+	child.AddEdge("publish", graph.END)
+
+	parent := graph.NewCheckpointableStateGraph()
+	parent.AddNode("start", "start", func(ctx context.Context, state interface{}) (interface{}, error) {
+		runs["start"]++
+		return state, nil
+	})
+	require.NoError(t, parent.AddSubgraph("review", child))
+	parent.SetEntryPoint("start")
+	parent.AddEdge("start", "review")
+	parent.AddEdge("review", graph.END)
+
+	runnable, err := parent.CompileCheckpointable()
+	require.NoError(t, err)
+	return runnable
+}
+
+func TestSubgraph_InterruptBubblesUp(t *testing.T) {
+	runs := map[string]int{}
+	runnable := newInterruptingSubgraphRunnable(t, runs)
+
+	_, err := runnable.Invoke(context.Background(), "post:")
+	var interrupt *graph.GraphInterrupt
+	require.ErrorAs(t, err, &interrupt)
+	assert.Equal(t, "review", interrupt.Node)
+	assert.Equal(t, "approve?", interrupt.InterruptValue)
+	require.NotNil(t, interrupt.Subgraph)
+	assert.Equal(t, "approve", interrupt.Subgraph.Node)
+	assert.Equal(t, "post: draft", interrupt.Subgraph.State)
+}
+
+func TestSubgraph_NamespacedCheckpointsAndResume(t *testing.T) {
+	runs := map[string]int{}
+	runnable := newInterruptingSubgraphRunnable(t, runs)
+	ctx := context.Background()
+
+	_, err := runnable.Invoke(ctx, "post:")
+	require.Error(t, err)
+
+	checkpoints, err := runnable.ListCheckpoints(ctx)
+	require.NoError(t, err)
+
+	var root, nested []*graph.Checkpoint
+	for _, cp := range checkpoints {
+		switch graph.CheckpointNamespace(cp) {
+		case "":
+			root = append(root, cp)
+		case "review:2":
+			nested = append(nested, cp)
+		default:
+			t.Fatalf("unexpected namespace %q", graph.CheckpointNamespace(cp))
+		}
+	}
+	require.NotEmpty(t, nested)
+	assert.Equal(t, "approve", nested[len(nested)-1].Interrupt.Node)
+
+	pending := root[len(root)-1]
+	require.NotNil(t, pending.Interrupt)
+	assert.Equal(t, []string{"review"}, pending.Next)
+
+	res, err := runnable.ResumeFromCheckpointWithConfig(ctx, pending.ID, &graph.Config{ResumeValue: "ok"})
+	require.NoError(t, err)
+	assert.Equal(t, "post: draft ok published", res)
+
+	// The child resumed at the interrupted node
+	assert.Equal(t, 1, runs["start"])
+	assert.Equal(t, 1, runs["draft"])
+	assert.Equal(t, 2, runs["approve"])
+	assert.Equal(t, 1, runs["publish"])
+}
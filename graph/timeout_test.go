@@ -0,0 +1,192 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
+
+func TestRunTimeout(t *testing.T) {
+	newGraph := func() *StateRunnable {
+		g := NewStateGraph()
+		g.AddNode("fast", "fast", func(ctx context.Context, state interface{}) (interface{}, error) {
+			return state.(string) + "-fast", nil
+		})
+		// slow observes cancellation through ctx
+		g.AddNode("slow", "slow", func(ctx context.Context, state interface{}) (interface{}, error) {
+			select {
+			case <-time.After(time.Second):
+				return state.(string) + "-slow", nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		})
+		// stubborn ignores ctx entirely
+		g.AddNode("stubborn", "stubborn", func(ctx context.Context, state interface{}) (interface{}, error) {
+			time.Sleep(time.Second)
+			return state.(string) + "-stubborn", nil
+		})
+		g.SetEntryPoint("fast")
+		g.AddEdge("fast", "slow")
+		g.AddEdge("fast", "stubborn")
+		g.AddEdge("slow", END)
+		g.AddEdge("stubborn", END)
+
+		runnable, err := g.Compile()
+		assert.NoError(t, err)
+		return runnable
+	}
+
+	t.Run("DeadlineReached", func(t *testing.T) {
+		runnable := newGraph()
+
+		start := time.Now()
+		res, err := runnable.InvokeWithConfig(context.Background(), "start", &Config{
+			Timeout: durationPtr(50 * time.Millisecond),
+		})
+		elapsed := time.Since(start)
+
+		var timeoutErr *GraphTimeoutError
+		assert.ErrorAs(t, err, &timeoutErr)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Equal(t, 50*time.Millisecond, timeoutErr.Timeout)
+		assert.Equal(t, 2, timeoutErr.Step)
+		assert.Equal(t, "start-fast", timeoutErr.State)
+		assert.Equal(t, "start-fast", res)
+		assert.ElementsMatch(t, []string{"slow", "stubborn"}, timeoutErr.RunningNodes)
+		assert.Less(t, elapsed, 500*time.Millisecond)
+	})
+
+	t.Run("ResumeAfterTimeout", func(t *testing.T) {
+		runnable := newGraph()
+
+		_, err := runnable.InvokeWithConfig(context.Background(), "start", &Config{
+			Timeout: durationPtr(50 * time.Millisecond),
+		})
+		var timeoutErr *GraphTimeoutError
+		assert.ErrorAs(t, err, &timeoutErr)
+
+		res, err := runnable.InvokeWithConfig(context.Background(), timeoutErr.State, &Config{
+			ResumeFrom: []string{"slow"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "start-fast-slow", res)
+	})
+
+	t.Run("WithinDeadline", func(t *testing.T) {
+		g := NewStateGraph()
+		g.AddNode("A", "A", func(ctx context.Context, state interface{}) (interface{}, error) {
+			return state.(string) + "A", nil
+		})
+		g.SetEntryPoint("A")
+		g.AddEdge("A", END)
+
+		runnable, err := g.Compile()
+		assert.NoError(t, err)
+
+		res, err := runnable.InvokeWithConfig(context.Background(), "", &Config{
+			Timeout: durationPtr(time.Second),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "A", res)
+	})
+
+	t.Run("ParentCancellationIsNotTimeout", func(t *testing.T) {
+		runnable := newGraph()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := runnable.InvokeWithConfig(ctx, "start", &Config{
+			Timeout: durationPtr(time.Minute),
+		})
+		var timeoutErr *GraphTimeoutError
+		assert.Error(t, err)
+		assert.False(t, errors.As(err, &timeoutErr))
+	})
+
+	t.Run("NestedRunAfterParentTimeout", func(t *testing.T) {
+		child := NewStateGraph()
+		child.AddNode("inner", "inner", func(ctx context.Context, state interface{}) (interface{}, error) {
+			return nil, ctx.Err()
+		})
+		child.SetEntryPoint("inner")
+		child.AddEdge("inner", END)
+		childRunnable, err := child.Compile()
+		assert.NoError(t, err)
+
+		// The child runs inherit the deadline of the parent, but not its timeout
+		// The parent stops waiting for the node at its deadline
+		childErrs := make(chan error, 2)
+		parent := NewStateGraph()
+		parent.AddNode("outer", "outer", func(ctx context.Context, state interface{}) (interface{}, error) {
+			<-ctx.Done()
+			for _, config := range []*Config{nil, {Timeout: durationPtr(time.Minute)}} {
+				func() {
+					defer func() {
+						if r := recover(); r != nil {
+							childErrs <- fmt.Errorf("child run panicked: %v", r)
+						}
+					}()
+					_, err := childRunnable.InvokeWithConfig(ctx, state, config)
+					childErrs <- err
+				}()
+			}
+			return nil, ctx.Err()
+		})
+		parent.SetEntryPoint("outer")
+		parent.AddEdge("outer", END)
+		parentRunnable, err := parent.Compile()
+		assert.NoError(t, err)
+
+		_, err = parentRunnable.InvokeWithConfig(context.Background(), "start", &Config{
+			Timeout: durationPtr(50 * time.Millisecond),
+		})
+		var timeoutErr *GraphTimeoutError
+		assert.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, []string{"outer"}, timeoutErr.RunningNodes)
+
+		for i := 0; i < 2; i++ {
+			childErr := <-childErrs
+			assert.ErrorIs(t, childErr, context.DeadlineExceeded)
+			assert.False(t, errors.As(childErr, &timeoutErr), "child reported the timeout of its parent: %v", childErr)
+		}
+	})
+}
+
+func TestListenableRunTimeout(t *testing.T) {
+	g := NewListenableStateGraph()
+	g.AddNode("A", "A", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return state.(int) + 1, nil
+	})
+	g.AddNode("B", "B", func(ctx context.Context, state interface{}) (interface{}, error) {
+		time.Sleep(time.Second)
+		return state.(int) + 1, nil
+	})
+	g.SetEntryPoint("A")
+	g.AddEdge("A", "B")
+	g.AddEdge("B", END)
+
+	runnable, err := g.CompileListenable()
+	assert.NoError(t, err)
+
+	start := time.Now()
+	_, err = runnable.InvokeWithConfig(context.Background(), 0, &Config{
+		Timeout: durationPtr(50 * time.Millisecond),
+	})
+
+	var timeoutErr *GraphTimeoutError
+	assert.ErrorAs(t, err, &timeoutErr)
+	assert.Equal(t, 1, timeoutErr.State)
+	assert.Equal(t, []string{"B"}, timeoutErr.RunningNodes)
+	assert.Equal(t, 2, timeoutErr.Step)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
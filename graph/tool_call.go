@@ -0,0 +1,57 @@
+package graph
+
+import "context"
+
+// CallTool calls a tool from a node and reports the call to the callbacks of the
+// current run (and of the runs it is nested in).
+//
+// OnToolStart and OnToolEnd or OnToolError are called around call, with the tool name
+// in the serialized map. When the run has a tracer, the call is traced in a span nested
+// in the span of the node, with the "tool" name in its metadata. Outside of a graph run
+// it only calls call.
+func CallTool(ctx context.Context, name string, input string, call func(ctx context.Context, input string) (string, error)) (string, error) {
+	tracer := activeTracer(ctx)
+	var span *TraceSpan
+	if tracer != nil {
+		span = tracer.StartSpan(ctx, TraceEventToolStart, GetNodeName(ctx))
+		span.State = input
+		span.Metadata["tool"] = name
+		ctx = tracer.contextWithSpan(ctx, span)
+	}
+
+	callbacks := inheritedCallbacks(ctx)
+	runID := GetRunID(ctx)
+	toolRunID := generateRunID()
+	if len(callbacks) > 0 {
+		var tags []string
+		if config := GetConfig(ctx); config != nil {
+			tags = config.Tags
+		}
+		serialized := map[string]interface{}{
+			"name": name,
+			"type": "tool",
+		}
+		metadata := map[string]interface{}{
+			"node":   GetNodeName(ctx),
+			"run_id": runID,
+		}
+		for _, cb := range callbacks {
+			cb.OnToolStart(ctx, serialized, input, toolRunID, &runID, tags, metadata)
+		}
+	}
+
+	output, err := call(ctx, input)
+
+	for _, cb := range callbacks {
+		if err != nil {
+			cb.OnToolError(ctx, err, toolRunID)
+		} else {
+			cb.OnToolEnd(ctx, output, toolRunID)
+		}
+	}
+	if span != nil {
+		tracer.EndSpan(ctx, span, output, err)
+	}
+
+	return output, err
+}
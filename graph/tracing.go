@@ -2,6 +2,9 @@ package graph
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -24,10 +27,52 @@ const (
 	// TraceEventNodeError indicates an error occurred in node execution
 	TraceEventNodeError TraceEvent = "node_error"
 
+	// TraceEventNodeRetry indicates a failed node attempt will be retried; the span
+	// metadata has the "attempt" number and the "delay" before the next attempt
+	TraceEventNodeRetry TraceEvent = "node_retry"
+
+	// TraceEventCacheHit indicates a node result was read from its cache; the span
+	// metadata has the cache "key"
+	TraceEventCacheHit TraceEvent = "cache_hit"
+
+	// TraceEventCacheMiss indicates a node result was not cached; the span
+	// metadata has the cache "key"
+	TraceEventCacheMiss TraceEvent = "cache_miss"
+
 	// TraceEventEdgeTraversal indicates traversal from one node to another
 	TraceEventEdgeTraversal TraceEvent = "edge_traversal"
+
+	// TraceEventStepStart indicates the start of a super-step; the span metadata has
+	// the "step" number and the "nodes" it runs
+	TraceEventStepStart TraceEvent = "step_start"
+
+	// TraceEventStepEnd indicates the end of a super-step
+	TraceEventStepEnd TraceEvent = "step_end"
+
+	// TraceEventLLMStart indicates the start of an LLM call made with GenerateContent
+	TraceEventLLMStart TraceEvent = "llm_start"
+
+	// TraceEventLLMEnd indicates the end of an LLM call; the span metadata has the
+	// "prompt_tokens", "completion_tokens" and "total_tokens" reported by the model
+	TraceEventLLMEnd TraceEvent = "llm_end"
+
+	// TraceEventToolStart indicates the start of a tool call made with CallTool; the
+	// span metadata has the "tool" name
+	TraceEventToolStart TraceEvent = "tool_start"
+
+	// TraceEventToolEnd indicates the end of a tool call
+	TraceEventToolEnd TraceEvent = "tool_end"
 )
 
+// traceEndEvents maps the event of a started span to the event of the ended span
+var traceEndEvents = map[TraceEvent]TraceEvent{
+	TraceEventGraphStart: TraceEventGraphEnd,
+	TraceEventNodeStart:  TraceEventNodeEnd,
+	TraceEventStepStart:  TraceEventStepEnd,
+	TraceEventLLMStart:   TraceEventLLMEnd,
+	TraceEventToolStart:  TraceEventToolEnd,
+}
+
 // TraceSpan represents a span of execution with timing and metadata
 type TraceSpan struct {
 	// ID is a unique identifier for this span
@@ -73,6 +118,16 @@ type TraceHook interface {
 	OnEvent(ctx context.Context, span *TraceSpan)
 }
 
+// TraceContextHook is implemented by trace hooks that make their own representation
+// of a span, e.g. an OpenTelemetry span, available to the code running inside it,
+// such as nodes, subgraphs and the calls they make
+type TraceContextHook interface {
+	TraceHook
+
+	// ContextWithSpan returns ctx carrying the representation of span
+	ContextWithSpan(ctx context.Context, span *TraceSpan) context.Context
+}
+
 // TraceHookFunc is a function adapter for TraceHook
 type TraceHookFunc func(ctx context.Context, span *TraceSpan)
 
@@ -81,10 +136,13 @@ func (f TraceHookFunc) OnEvent(ctx context.Context, span *TraceSpan) {
 	f(ctx, span)
 }
 
-// Tracer manages trace collection and hooks
+// Tracer manages trace collection and hooks. It is safe for concurrent use by the
+// nodes of a step.
 type Tracer struct {
-	hooks []TraceHook
-	spans map[string]*TraceSpan
+	hooks   []TraceHook
+	spans   map[string]*TraceSpan
+	mutex   sync.Mutex
+	discard bool
 }
 
 // NewTracer creates a new tracer instance
@@ -100,6 +158,14 @@ func (t *Tracer) AddHook(hook TraceHook) {
 	t.hooks = append(t.hooks, hook)
 }
 
+// WithSpanCollection enables or disables the collection of spans returned by GetSpans.
+// Collection is enabled by default; tracers exporting their spans through hooks in
+// long-running services disable it, so the spans are not kept in memory.
+func (t *Tracer) WithSpanCollection(enabled bool) *Tracer {
+	t.discard = !enabled
+	return t
+}
+
 // StartSpan creates a new trace span
 func (t *Tracer) StartSpan(ctx context.Context, event TraceEvent, nodeName string) *TraceSpan {
 	span := &TraceSpan{
@@ -115,7 +181,7 @@ func (t *Tracer) StartSpan(ctx context.Context, event TraceEvent, nodeName strin
 		span.ParentID = parentSpan.ID
 	}
 
-	t.spans[span.ID] = span
+	t.collect(span)
 
 	// Notify hooks
 	for _, hook := range t.hooks {
@@ -135,10 +201,8 @@ func (t *Tracer) EndSpan(ctx context.Context, span *TraceSpan, state interface{}
 	// Update event type if there was an error
 	if err != nil && span.Event == TraceEventNodeStart {
 		span.Event = TraceEventNodeError
-	} else if span.Event == TraceEventNodeStart {
-		span.Event = TraceEventNodeEnd
-	} else if span.Event == TraceEventGraphStart {
-		span.Event = TraceEventGraphEnd
+	} else if end, ok := traceEndEvents[span.Event]; ok {
+		span.Event = end
 	}
 
 	// Notify hooks
@@ -149,13 +213,14 @@ func (t *Tracer) EndSpan(ctx context.Context, span *TraceSpan, state interface{}
 
 // TraceEdgeTraversal records an edge traversal event
 func (t *Tracer) TraceEdgeTraversal(ctx context.Context, fromNode, toNode string) {
+	now := time.Now()
 	span := &TraceSpan{
 		ID:        generateSpanID(),
 		Event:     TraceEventEdgeTraversal,
 		FromNode:  fromNode,
 		ToNode:    toNode,
-		StartTime: time.Now(),
-		EndTime:   time.Now(),
+		StartTime: now,
+		EndTime:   now,
 		Duration:  0,
 		Metadata:  make(map[string]interface{}),
 	}
@@ -165,7 +230,7 @@ func (t *Tracer) TraceEdgeTraversal(ctx context.Context, fromNode, toNode string
 		span.ParentID = parentSpan.ID
 	}
 
-	t.spans[span.ID] = span
+	t.collect(span)
 
 	// Notify hooks
 	for _, hook := range t.hooks {
@@ -173,16 +238,60 @@ func (t *Tracer) TraceEdgeTraversal(ctx context.Context, fromNode, toNode string
 	}
 }
 
+// collect keeps span for GetSpans unless collection is disabled
+func (t *Tracer) collect(span *TraceSpan) {
+	if t.discard {
+		return
+	}
+	t.mutex.Lock()
+	t.spans[span.ID] = span
+	t.mutex.Unlock()
+}
+
 // GetSpans returns all collected spans
 func (t *Tracer) GetSpans() map[string]*TraceSpan {
-	return t.spans
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	spans := make(map[string]*TraceSpan, len(t.spans))
+	for id, span := range t.spans {
+		spans[id] = span
+	}
+	return spans
 }
 
 // Clear removes all collected spans
 func (t *Tracer) Clear() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
 	t.spans = make(map[string]*TraceSpan)
 }
 
+// contextWithSpan returns ctx carrying span, and the representations of span made
+// by the hooks implementing TraceContextHook, for the code running inside the span
+func (t *Tracer) contextWithSpan(ctx context.Context, span *TraceSpan) context.Context {
+	ctx = ContextWithSpan(ctx, span)
+	for _, hook := range t.hooks {
+		if contextHook, ok := hook.(TraceContextHook); ok {
+			ctx = contextHook.ContextWithSpan(ctx, span)
+		}
+	}
+	return ctx
+}
+
+type tracerKey struct{}
+
+// withTracer adds the tracer of the current run to the context
+func withTracer(ctx context.Context, tracer *Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, tracer)
+}
+
+// activeTracer returns the tracer of the run the context belongs to, if any
+func activeTracer(ctx context.Context) *Tracer {
+	tracer, _ := ctx.Value(tracerKey{}).(*Tracer)
+	return tracer
+}
+
 // Context keys for span storage
 type contextKey string
 
@@ -201,9 +310,12 @@ func SpanFromContext(ctx context.Context) *TraceSpan {
 	return nil
 }
 
+// spanCounter makes the IDs of spans started at the same time unique
+var spanCounter atomic.Uint64
+
 // generateSpanID creates a unique span identifier
 func generateSpanID() string {
-	return time.Now().Format("20060102150405.000000")
+	return fmt.Sprintf("%s-%d", time.Now().Format("20060102150405.000000"), spanCounter.Add(1))
 }
 
 // TracedRunnable wraps a Runnable with tracing capabilities
@@ -224,7 +336,7 @@ func NewTracedRunnable(runnable *Runnable, tracer *Tracer) *TracedRunnable {
 func (tr *TracedRunnable) Invoke(ctx context.Context, initialState interface{}) (interface{}, error) {
 	// Start graph execution span
 	graphSpan := tr.tracer.StartSpan(ctx, TraceEventGraphStart, "")
-	ctx = ContextWithSpan(ctx, graphSpan)
+	ctx = tr.tracer.contextWithSpan(ctx, graphSpan)
 
 	state := initialState
 	currentNode := tr.graph.entryPoint
@@ -244,7 +356,7 @@ func (tr *TracedRunnable) Invoke(ctx context.Context, initialState interface{})
 
 		// Start node execution span
 		nodeSpan := tr.tracer.StartSpan(ctx, TraceEventNodeStart, currentNode)
-		nodeCtx := ContextWithSpan(ctx, nodeSpan)
+		nodeCtx := tr.tracer.contextWithSpan(ctx, nodeSpan)
 
 		var err error
 		state, err = node.Function(nodeCtx, state)
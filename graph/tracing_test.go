@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"testing"
 
@@ -114,6 +115,28 @@ func TestTracer_Hooks(t *testing.T) {
 	}
 }
 
+func TestTracer_WithoutSpanCollection(t *testing.T) {
+	t.Parallel()
+
+	var events []graph.TraceEvent
+	tracer := graph.NewTracer().WithSpanCollection(false)
+	tracer.AddHook(graph.TraceHookFunc(func(ctx context.Context, span *graph.TraceSpan) {
+		events = append(events, span.Event)
+	}))
+
+	span := tracer.StartSpan(context.Background(), graph.TraceEventNodeStart, "node")
+	tracer.EndSpan(context.Background(), span, nil, nil)
+	tracer.TraceEdgeTraversal(context.Background(), "node", graph.END)
+
+	// The hooks are notified, but the spans are not kept
+	if len(events) != 3 {
+		t.Errorf("Expected 3 events, got %v", events)
+	}
+	if spans := tracer.GetSpans(); len(spans) != 0 {
+		t.Errorf("Expected no collected spans, got %d", len(spans))
+	}
+}
+
 func TestTracer_EdgeTraversal(t *testing.T) {
 	t.Parallel()
 
@@ -323,6 +346,77 @@ func TestTracer_SpanHierarchy(t *testing.T) {
 	}
 }
 
+func TestStateGraph_NestsSpans(t *testing.T) {
+	t.Parallel()
+
+	g := graph.NewStateGraph()
+	g.AddNode("agent", "agent", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return graph.CallTool(ctx, "echo", state.(string), func(ctx context.Context, input string) (string, error) {
+			return input + "!", nil
+		})
+	})
+	g.AddNode("end", "end", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return state, nil
+	})
+	g.AddEdge("agent", "end")
+	g.AddEdge("end", graph.END)
+	g.SetEntryPoint("agent")
+
+	runnable, err := g.Compile()
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	tracer := graph.NewTracer()
+	runnable.SetTracer(tracer)
+
+	if _, err := runnable.Invoke(context.Background(), "hi"); err != nil {
+		t.Fatalf("Failed to invoke: %v", err)
+	}
+
+	byEvent := make(map[graph.TraceEvent][]*graph.TraceSpan)
+	for _, span := range tracer.GetSpans() {
+		byEvent[span.Event] = append(byEvent[span.Event], span)
+	}
+	if len(byEvent[graph.TraceEventGraphEnd]) != 1 || len(byEvent[graph.TraceEventStepEnd]) != 2 ||
+		len(byEvent[graph.TraceEventNodeEnd]) != 2 || len(byEvent[graph.TraceEventToolEnd]) != 1 {
+		t.Fatalf("Unexpected spans: %v", byEvent)
+	}
+
+	// graph > step > node > tool, and the edges are traced in the step they leave
+	graphSpan := byEvent[graph.TraceEventGraphEnd][0]
+	steps := make(map[string]*graph.TraceSpan)
+	for _, step := range byEvent[graph.TraceEventStepEnd] {
+		if step.ParentID != graphSpan.ID {
+			t.Errorf("Expected step %v in the graph span", step.Metadata["step"])
+		}
+		steps[step.ID] = step
+	}
+	nodes := make(map[string]*graph.TraceSpan)
+	for _, node := range byEvent[graph.TraceEventNodeEnd] {
+		step, ok := steps[node.ParentID]
+		if !ok || step.Metadata["step"] != node.Metadata["step"] {
+			t.Errorf("Expected node %s in the span of its step", node.NodeName)
+		}
+		nodes[node.NodeName] = node
+	}
+	tool := byEvent[graph.TraceEventToolEnd][0]
+	if tool.ParentID != nodes["agent"].ID || tool.Metadata["tool"] != "echo" || tool.State != "hi!" {
+		t.Errorf("Unexpected tool span: %+v", tool)
+	}
+
+	var edges []string
+	for _, edge := range byEvent[graph.TraceEventEdgeTraversal] {
+		if _, ok := steps[edge.ParentID]; !ok {
+			t.Errorf("Expected edge %s -> %s in a step span", edge.FromNode, edge.ToNode)
+		}
+		edges = append(edges, edge.FromNode+"->"+edge.ToNode)
+	}
+	sort.Strings(edges)
+	if strings.Join(edges, ",") != "agent->end,end->END" {
+		t.Errorf("Unexpected edges: %v", edges)
+	}
+}
+
 // Benchmark tests
 func BenchmarkTracer_StartEndSpan(b *testing.B) {
 	tracer := graph.NewTracer()
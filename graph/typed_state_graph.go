@@ -0,0 +1,176 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// TypedNodeFunc is a node function over a typed state S.
+// For struct states the returned value is an update merged by the StructSchema: zero
+// fields are left unchanged, unless marked with Changed, and `append` or
+// `add_messages` fields are appended, so return only what the node changes.
+type TypedNodeFunc[S any] func(ctx context.Context, state S) (S, error)
+
+// TypedStateGraph is a StateGraph whose nodes, edges and results use the state type S
+// instead of interface{}. It compiles onto the same engine as StateGraph, so node
+// listeners, callbacks, tracing, checkpointing and streaming keep working.
+//
+// When S is a struct, node results are merged with a StructSchema built from the
+// struct tags of S; for any other type the last node result replaces the state.
+type TypedStateGraph[S any] struct {
+	graph     *ListenableStateGraph
+	schema    *StructSchema[S]
+	schemaErr error
+}
+
+// NewTypedStateGraph creates a new typed state graph
+func NewTypedStateGraph[S any]() *TypedStateGraph[S] {
+	g := &TypedStateGraph[S]{
+		graph: NewListenableStateGraph(),
+	}
+
+	if reflect.TypeOf((*S)(nil)).Elem().Kind() == reflect.Struct {
+		g.schema, g.schemaErr = NewStructSchema[S]()
+		if g.schemaErr == nil {
+			g.graph.SetSchema(g.schema)
+		}
+	}
+
+	return g
+}
+
+// AddNode adds a typed node to the graph.
+// Options set execution policies of the node; see StateGraph.AddNode.
+//
+// For struct states, fn returns an update, not the full state: returning the state
+// it received appends every `append` and `add_messages` field to itself again, and a
+// field set to its zero value is ignored unless marked with Changed.MarkChanged.
+func (g *TypedStateGraph[S]) AddNode(name string, description string, fn TypedNodeFunc[S], opts ...NodeOption) *ListenableNode {
+	return g.graph.AddNode(name, description, func(ctx context.Context, state interface{}) (interface{}, error) {
+		typed, err := AsState[S](state)
+		if err != nil {
+			return nil, err
+		}
+		return fn(ctx, typed)
+	}, opts...)
+}
+
+// AddEdge adds a new edge between the "from" and "to" nodes
+func (g *TypedStateGraph[S]) AddEdge(from, to string) {
+	g.graph.AddEdge(from, to)
+}
+
+// AddConditionalEdge adds a conditional edge whose target is chosen from the typed state.
+// The possible destinations may be declared; see StateGraph.AddConditionalEdge.
+func (g *TypedStateGraph[S]) AddConditionalEdge(from string, condition func(ctx context.Context, state S) string, destinations ...string) {
+	g.graph.addConditionalEdge(from, func(ctx context.Context, state interface{}) (string, error) {
+		typed, err := AsState[S](state)
+		if err != nil {
+			return "", err
+		}
+		return condition(ctx, typed), nil
+	}, destinations...)
+}
+
+// AddConditionalEdges adds a conditional edge whose router can choose several next nodes,
+// optionally through a path map; see StateGraph.AddConditionalEdges.
+func (g *TypedStateGraph[S]) AddConditionalEdges(from string, router func(ctx context.Context, state S) []string, pathMap map[string]string) {
+	g.graph.addConditionalEdges(from, func(ctx context.Context, state interface{}) ([]string, error) {
+		typed, err := AsState[S](state)
+		if err != nil {
+			return nil, err
+		}
+		return router(ctx, typed), nil
+	}, pathMap)
+}
+
+// AddConditionalSendEdge adds an edge whose router returns the Sends to execute after
+// the "from" node. The Arg of each Send is converted to S like the graph state.
+func (g *TypedStateGraph[S]) AddConditionalSendEdge(from string, router func(ctx context.Context, state S) []Send) {
+	g.graph.addConditionalSendEdge(from, func(ctx context.Context, state interface{}) ([]Send, error) {
+		typed, err := AsState[S](state)
+		if err != nil {
+			return nil, err
+		}
+		return router(ctx, typed), nil
+	})
+}
+
+// SetEntryPoint sets the entry point node name for the graph
+func (g *TypedStateGraph[S]) SetEntryPoint(name string) {
+	g.graph.SetEntryPoint(name)
+}
+
+// SetStrictValidation makes Compile fail on validation warnings as well as errors
+func (g *TypedStateGraph[S]) SetStrictValidation(strict bool) {
+	g.graph.SetStrictValidation(strict)
+}
+
+// SetRetryPolicy sets the retry policy for the graph
+func (g *TypedStateGraph[S]) SetRetryPolicy(policy *RetryPolicy) {
+	g.graph.SetRetryPolicy(policy)
+}
+
+// AddGlobalListener adds a listener to all nodes in the graph
+func (g *TypedStateGraph[S]) AddGlobalListener(listener NodeListener) {
+	g.graph.AddGlobalListener(listener)
+}
+
+// Schema returns the struct schema used to merge node results, or nil when S is not a struct
+func (g *TypedStateGraph[S]) Schema() *StructSchema[S] {
+	return g.schema
+}
+
+// Compile compiles the graph into a typed runnable
+func (g *TypedStateGraph[S]) Compile() (*TypedStateRunnable[S], error) {
+	if g.schemaErr != nil {
+		return nil, fmt.Errorf("invalid state schema: %w", g.schemaErr)
+	}
+
+	runnable, err := g.graph.CompileListenable()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TypedStateRunnable[S]{runnable: runnable}, nil
+}
+
+// TypedStateRunnable is a compiled TypedStateGraph
+type TypedStateRunnable[S any] struct {
+	runnable *ListenableRunnable
+}
+
+// Invoke executes the graph and returns the final typed state
+func (r *TypedStateRunnable[S]) Invoke(ctx context.Context, initialState S) (S, error) {
+	return r.InvokeWithConfig(ctx, initialState, nil)
+}
+
+// InvokeWithConfig executes the graph with config and returns the final typed state.
+// On interrupts and other errors that carry a state, the state reached so far is returned.
+func (r *TypedStateRunnable[S]) InvokeWithConfig(ctx context.Context, initialState S, config *Config) (S, error) {
+	result, err := r.runnable.InvokeWithConfig(ctx, initialState, config)
+
+	state, convErr := AsState[S](result)
+	if err != nil {
+		return state, err
+	}
+	return state, convErr
+}
+
+// SetTracer sets a tracer for observability
+func (r *TypedStateRunnable[S]) SetTracer(tracer *Tracer) {
+	r.runnable.SetTracer(tracer)
+}
+
+// Listenable returns the untyped runnable, e.g. to wrap it with
+// NewCheckpointableRunnable or NewStreamingRunnable. Use AsState to convert
+// the states it produces back to S.
+func (r *TypedStateRunnable[S]) Listenable() *ListenableRunnable {
+	return r.runnable
+}
+
+// GetGraph returns a Exporter for visualization
+func (r *TypedStateRunnable[S]) GetGraph() *Exporter {
+	return r.runnable.GetGraph()
+}
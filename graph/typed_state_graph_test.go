@@ -0,0 +1,329 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+type typedAgentState struct {
+	Messages []llms.MessageContent `reducer:"add_messages"`
+	Steps    []string              `reducer:"append"`
+	Scratch  string                `ephemeral:"true"`
+	Count    int
+	Done     bool
+}
+
+func TestTypedStateGraph_Invoke(t *testing.T) {
+	g := graph.NewTypedStateGraph[typedAgentState]()
+
+	g.AddNode("think", "think", func(ctx context.Context, state typedAgentState) (typedAgentState, error) {
+		return typedAgentState{
+			Messages: []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeAI, "thinking")},
+			Steps:    []string{"think"},
+			Scratch:  "temporary",
+			Count:    state.Count + 1,
+		}, nil
+	})
+	g.AddNode("finish", "finish", func(ctx context.Context, state typedAgentState) (typedAgentState, error) {
+		// Ephemeral fields are cleared after the step that wrote them
+		assert.Empty(t, state.Scratch)
+		return typedAgentState{Steps: []string{"finish"}, Done: true}, nil
+	})
+
+	g.SetEntryPoint("think")
+	g.AddConditionalEdge("think", func(ctx context.Context, state typedAgentState) string {
+		if state.Count >= 3 {
+			return "finish"
+		}
+		return "think"
+	})
+	g.AddEdge("finish", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	res, err := runnable.Invoke(context.Background(), typedAgentState{
+		Messages: []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "hi")},
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, res.Messages, 4)
+	assert.Equal(t, []string{"think", "think", "think", "finish"}, res.Steps)
+	assert.Equal(t, 3, res.Count)
+	assert.True(t, res.Done)
+	assert.Empty(t, res.Scratch)
+}
+
+func TestTypedStateGraph_ParallelBranchesMerge(t *testing.T) {
+	g := graph.NewTypedStateGraph[typedAgentState]()
+	g.AddNode("start", "start", func(ctx context.Context, state typedAgentState) (typedAgentState, error) {
+		return typedAgentState{Steps: []string{"start"}}, nil
+	})
+	for _, name := range []string{"a", "b"} {
+		name := name
+		g.AddNode(name, name, func(ctx context.Context, state typedAgentState) (typedAgentState, error) {
+			return typedAgentState{Steps: []string{name}}, nil
+		})
+		g.AddEdge("start", name)
+		g.AddEdge(name, graph.END)
+	}
+	g.SetEntryPoint("start")
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	res, err := runnable.Invoke(context.Background(), typedAgentState{})
+	require.NoError(t, err)
+	assert.Equal(t, "start", res.Steps[0])
+	assert.ElementsMatch(t, []string{"a", "b"}, res.Steps[1:])
+}
+
+func TestTypedStateGraph_NonStructState(t *testing.T) {
+	g := graph.NewTypedStateGraph[string]()
+	g.AddNode("upper", "upper", func(ctx context.Context, state string) (string, error) {
+		return strings.ToUpper(state), nil
+	})
+	g.SetEntryPoint("upper")
+	g.AddEdge("upper", graph.END)
+
+	assert.Nil(t, g.Schema())
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	res, err := runnable.Invoke(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO", res)
+}
+
+func TestTypedStateGraph_FieldReducers(t *testing.T) {
+	type counterState struct {
+		Total int
+		Label string
+	}
+
+	g := graph.NewTypedStateGraph[counterState]()
+	require.NoError(t, graph.RegisterFieldReducer(g.Schema(), "Total", func(current, update int) (int, error) {
+		return current + update, nil
+	}))
+
+	err := graph.RegisterFieldReducer(g.Schema(), "Total", func(current, update string) (string, error) {
+		return update, nil
+	})
+	assert.Error(t, err)
+	assert.Error(t, g.Schema().RegisterReducer("Missing", graph.OverwriteReducer))
+
+	g.AddNode("add", "add", func(ctx context.Context, state counterState) (counterState, error) {
+		return counterState{Total: 5, Label: "added"}, nil
+	})
+	g.SetEntryPoint("add")
+	g.AddEdge("add", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	res, err := runnable.Invoke(context.Background(), counterState{Total: 10})
+	require.NoError(t, err)
+	assert.Equal(t, counterState{Total: 15, Label: "added"}, res)
+}
+
+func TestTypedStateGraph_InvalidReducerTag(t *testing.T) {
+	type badState struct {
+		Items []string `reducer:"unknown"`
+	}
+
+	g := graph.NewTypedStateGraph[badState]()
+	g.AddNode("noop", "noop", func(ctx context.Context, state badState) (badState, error) {
+		return state, nil
+	})
+	g.SetEntryPoint("noop")
+
+	_, err := g.Compile()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown reducer")
+}
+
+func TestTypedStateGraph_ChangedZeroValues(t *testing.T) {
+	type resetState struct {
+		graph.Changed
+		Count int
+		Done  bool
+		Label string
+		Steps []string `reducer:"append"`
+	}
+
+	g := graph.NewTypedStateGraph[resetState]()
+	g.AddNode("set", "set", func(ctx context.Context, state resetState) (resetState, error) {
+		return resetState{Count: 3, Done: true, Label: "set", Steps: []string{"set"}}, nil
+	})
+	g.AddNode("ignored", "ignored", func(ctx context.Context, state resetState) (resetState, error) {
+		// Zero fields are not part of the update
+		return resetState{Steps: []string{"ignored"}}, nil
+	})
+	g.AddNode("reset", "reset", func(ctx context.Context, state resetState) (resetState, error) {
+		update := resetState{Steps: []string{"reset"}}
+		update.MarkChanged("Count", "Done", "Label")
+		return update, nil
+	})
+	g.SetEntryPoint("set")
+	g.AddEdge("set", "ignored")
+	g.AddConditionalEdge("ignored", func(ctx context.Context, state resetState) string {
+		assert.Equal(t, 3, state.Count)
+		assert.True(t, state.Done)
+		assert.Equal(t, "set", state.Label)
+		return "reset"
+	}, "reset")
+	g.AddEdge("reset", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	res, err := runnable.Invoke(context.Background(), resetState{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Count)
+	assert.False(t, res.Done)
+	assert.Equal(t, "", res.Label)
+	assert.Equal(t, []string{"set", "ignored", "reset"}, res.Steps)
+	assert.False(t, res.IsChanged("Count"), "marks are cleared by the update")
+}
+
+func TestTypedStateGraph_FullStateUpdateDuplicatesAppendFields(t *testing.T) {
+	run := func(node graph.TypedNodeFunc[typedAgentState]) typedAgentState {
+		g := graph.NewTypedStateGraph[typedAgentState]()
+		g.AddNode("node", "node", node)
+		g.SetEntryPoint("node")
+		g.AddEdge("node", graph.END)
+
+		runnable, err := g.Compile()
+		require.NoError(t, err)
+		res, err := runnable.Invoke(context.Background(), typedAgentState{Steps: []string{"start"}})
+		require.NoError(t, err)
+		return res
+	}
+
+	// Returning the received state appends its fields to themselves
+	full := run(func(ctx context.Context, state typedAgentState) (typedAgentState, error) {
+		state.Steps = append(state.Steps, "node")
+		state.Count++
+		return state, nil
+	})
+	assert.Equal(t, []string{"start", "start", "node"}, full.Steps)
+	assert.Equal(t, 1, full.Count)
+
+	// Returning only the changes does not
+	partial := run(func(ctx context.Context, state typedAgentState) (typedAgentState, error) {
+		return typedAgentState{Steps: []string{"node"}, Count: state.Count + 1}, nil
+	})
+	assert.Equal(t, []string{"start", "node"}, partial.Steps)
+	assert.Equal(t, 1, partial.Count)
+}
+
+func TestTypedStateGraph_ErrorsReturnPartialState(t *testing.T) {
+	g := graph.NewTypedStateGraph[typedAgentState]()
+	g.AddNode("one", "one", func(ctx context.Context, state typedAgentState) (typedAgentState, error) {
+		return typedAgentState{Count: 1}, nil
+	})
+	g.AddNode("two", "two", func(ctx context.Context, state typedAgentState) (typedAgentState, error) {
+		return typedAgentState{}, errors.New("boom")
+	})
+	g.SetEntryPoint("one")
+	g.AddEdge("one", "two")
+	g.AddEdge("two", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	res, err := runnable.InvokeWithConfig(context.Background(), typedAgentState{}, &graph.Config{InterruptBefore: []string{"two"}})
+	var interrupt *graph.GraphInterrupt
+	require.ErrorAs(t, err, &interrupt)
+	assert.Equal(t, 1, res.Count)
+
+	_, err = runnable.Invoke(context.Background(), typedAgentState{})
+	assert.ErrorContains(t, err, "boom")
+}
+
+func TestTypedStateGraph_ListenersAndCheckpointing(t *testing.T) {
+	g := graph.NewTypedStateGraph[typedAgentState]()
+	g.AddNode("one", "one", func(ctx context.Context, state typedAgentState) (typedAgentState, error) {
+		return typedAgentState{Steps: []string{"one"}, Count: state.Count + 1}, nil
+	})
+	g.AddNode("two", "two", func(ctx context.Context, state typedAgentState) (typedAgentState, error) {
+		return typedAgentState{Steps: []string{"two"}, Count: state.Count + 1}, nil
+	})
+	g.SetEntryPoint("one")
+	g.AddEdge("one", "two")
+	g.AddEdge("two", graph.END)
+
+	var mu sync.Mutex
+	var completed []string
+	g.AddGlobalListener(graph.NodeListenerFunc(func(ctx context.Context, event graph.NodeEvent, nodeName string, state interface{}, err error) {
+		if event == graph.NodeEventComplete {
+			mu.Lock()
+			completed = append(completed, nodeName)
+			mu.Unlock()
+		}
+	}))
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	// A JSON-backed store returns states as generic maps
+	store, err := graph.NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+	checkpointable := graph.NewCheckpointableRunnable(runnable.Listenable(), graph.CheckpointConfig{Store: store, AutoSave: true})
+
+	ctx := context.Background()
+	_, err = checkpointable.Invoke(ctx, typedAgentState{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one", "two"}, completed)
+
+	checkpoints, err := checkpointable.ListCheckpoints(ctx)
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 2)
+
+	first, err := graph.AsState[typedAgentState](checkpoints[0].State)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one"}, first.Steps)
+
+	resumed, err := checkpointable.ResumeFromCheckpoint(ctx, checkpoints[0].ID)
+	require.NoError(t, err)
+	final, err := graph.AsState[typedAgentState](resumed)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one", "two"}, final.Steps)
+	assert.Equal(t, 2, final.Count)
+}
+
+func TestTypedStateGraph_Streaming(t *testing.T) {
+	g := graph.NewTypedStateGraph[typedAgentState]()
+	g.AddNode("one", "one", func(ctx context.Context, state typedAgentState) (typedAgentState, error) {
+		return typedAgentState{Count: 1}, nil
+	})
+	g.SetEntryPoint("one")
+	g.AddEdge("one", graph.END)
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+
+	streaming := graph.NewStreamingRunnableWithDefaults(runnable.Listenable())
+	result := streaming.Stream(context.Background(), typedAgentState{})
+
+	var events []graph.StreamEvent
+	for event := range result.Events {
+		events = append(events, event)
+	}
+	final := <-result.Result
+	<-result.Done
+
+	state, err := graph.AsState[typedAgentState](final)
+	require.NoError(t, err)
+	assert.Equal(t, 1, state.Count)
+	assert.NotEmpty(t, events)
+}
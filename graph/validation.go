@@ -0,0 +1,275 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrUnreachableNode is reported for nodes that cannot be reached from the entry point.
+	ErrUnreachableNode = errors.New("node is unreachable from the entry point")
+
+	// ErrNoPathToEnd is reported for nodes from which END cannot be reached.
+	ErrNoPathToEnd = errors.New("no path from node to END")
+
+	// ErrUndeclaredDestinations is reported for conditional edges whose possible
+	// destinations are not declared, so the graph cannot be fully checked.
+	ErrUndeclaredDestinations = errors.New("conditional edge has no declared destinations")
+)
+
+// ValidationSeverity indicates whether a validation issue prevents compilation
+type ValidationSeverity string
+
+const (
+	// SeverityError issues always make Compile fail
+	SeverityError ValidationSeverity = "error"
+	// SeverityWarning issues only make Compile fail in strict mode
+	SeverityWarning ValidationSeverity = "warning"
+)
+
+// ValidationIssue is a problem found in the structure of a graph
+type ValidationIssue struct {
+	// Severity of the issue
+	Severity ValidationSeverity
+	// Node is the node the issue is about
+	Node string
+	// Err describes the issue; it wraps one of the graph sentinel errors,
+	// e.g. ErrNodeNotFound or ErrUnreachableNode
+	Err error
+}
+
+func (i ValidationIssue) Error() string {
+	return fmt.Sprintf("%s: %v", i.Severity, i.Err)
+}
+
+// Unwrap allows errors.Is to match the sentinel error of the issue
+func (i ValidationIssue) Unwrap() error {
+	return i.Err
+}
+
+// GraphValidationError is returned by Compile when the graph is invalid.
+// It lists every issue found, errors first, so they can all be fixed at once.
+type GraphValidationError struct {
+	Issues []ValidationIssue
+}
+
+func (e *GraphValidationError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("graph validation failed with %d issue(s):", len(e.Issues)))
+	for _, issue := range e.Issues {
+		sb.WriteString("\n  - ")
+		sb.WriteString(issue.Error())
+	}
+	return sb.String()
+}
+
+// Unwrap allows errors.Is and errors.As to match any of the issues
+func (e *GraphValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Issues))
+	for i, issue := range e.Issues {
+		errs[i] = issue
+	}
+	return errs
+}
+
+// Errors returns the issues with SeverityError
+func (e *GraphValidationError) Errors() []ValidationIssue {
+	return e.filter(SeverityError)
+}
+
+// Warnings returns the issues with SeverityWarning
+func (e *GraphValidationError) Warnings() []ValidationIssue {
+	return e.filter(SeverityWarning)
+}
+
+func (e *GraphValidationError) filter(severity ValidationSeverity) []ValidationIssue {
+	var issues []ValidationIssue
+	for _, issue := range e.Issues {
+		if issue.Severity == severity {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// SetStrictValidation makes Compile fail on validation warnings as well as errors
+func (g *StateGraph) SetStrictValidation(strict bool) {
+	g.strictValidation = strict
+}
+
+// Validate checks the structure of the graph and returns a *GraphValidationError
+// listing every issue found, or nil when there is none.
+//
+// Errors are references to nodes that do not exist and a missing entry point.
+// Warnings are unreachable nodes, nodes without outgoing edge or path to END, and
+// conditional edges without declared destinations. Nodes routed with Command.Goto
+// only may be reported as unreachable: declare them as destinations of a conditional
+// edge or ignore the warning.
+func (g *StateGraph) Validate() error {
+	v := &graphValidator{graph: g}
+	v.run()
+	if len(v.issues) == 0 {
+		return nil
+	}
+
+	// Errors first, keeping the order in which they were found
+	sort.SliceStable(v.issues, func(i, j int) bool {
+		return v.issues[i].Severity == SeverityError && v.issues[j].Severity != SeverityError
+	})
+	return &GraphValidationError{Issues: v.issues}
+}
+
+// validateForCompile returns the validation error when it must prevent compilation
+func (g *StateGraph) validateForCompile() error {
+	err := g.Validate()
+	if err == nil {
+		return nil
+	}
+	var validationErr *GraphValidationError
+	if errors.As(err, &validationErr) && len(validationErr.Errors()) == 0 && !g.strictValidation {
+		return nil
+	}
+	return err
+}
+
+type graphValidator struct {
+	graph  *StateGraph
+	issues []ValidationIssue
+}
+
+func (v *graphValidator) report(severity ValidationSeverity, node string, err error) {
+	v.issues = append(v.issues, ValidationIssue{Severity: severity, Node: node, Err: err})
+}
+
+func (v *graphValidator) exists(node string) bool {
+	_, ok := v.graph.nodes[node]
+	return ok
+}
+
+func (v *graphValidator) run() {
+	g := v.graph
+
+	// Entry point
+	switch {
+	case g.entryPoint == "":
+		v.report(SeverityError, "", ErrEntryPointNotSet)
+	case !v.exists(g.entryPoint):
+		v.report(SeverityError, g.entryPoint, fmt.Errorf("entry point %s: %w: %s", g.entryPoint, ErrNodeNotFound, g.entryPoint))
+	}
+
+	// Static edges
+	for _, edge := range g.edges {
+		if !v.exists(edge.From) {
+			v.report(SeverityError, edge.From, fmt.Errorf("edge %s -> %s: %w: %s", edge.From, edge.To, ErrNodeNotFound, edge.From))
+		}
+		if edge.To != END && !v.exists(edge.To) {
+			v.report(SeverityError, edge.To, fmt.Errorf("edge %s -> %s: %w: %s", edge.From, edge.To, ErrNodeNotFound, edge.To))
+		}
+	}
+
+	// Conditional and send edges
+	for _, from := range sortedKeys(g.conditionalEdges) {
+		if !v.exists(from) {
+			v.report(SeverityError, from, fmt.Errorf("conditional edge from %s: %w: %s", from, ErrNodeNotFound, from))
+		}
+		destinations := g.conditionalEdges[from].destinations
+		if destinations == nil {
+			v.report(SeverityWarning, from, fmt.Errorf("%w: %s", ErrUndeclaredDestinations, from))
+		}
+		for _, to := range destinations {
+			if to != END && !v.exists(to) {
+				v.report(SeverityError, to, fmt.Errorf("conditional edge %s -> %s: %w: %s", from, to, ErrNodeNotFound, to))
+			}
+		}
+	}
+	for _, from := range sortedKeys(g.sendEdges) {
+		if !v.exists(from) {
+			v.report(SeverityError, from, fmt.Errorf("send edge from %s: %w: %s", from, ErrNodeNotFound, from))
+		}
+	}
+
+	if g.entryPoint == "" || !v.exists(g.entryPoint) {
+		return
+	}
+
+	// Reachability, following the declared destinations. Conditional edges without
+	// declared destinations and send edges may lead to any node.
+	successors := v.successors()
+	reachable := reach([]string{g.entryPoint}, successors)
+
+	predecessors := make(map[string][]string)
+	for from, targets := range successors {
+		for _, to := range targets {
+			predecessors[to] = append(predecessors[to], from)
+		}
+	}
+	reachesEnd := reach([]string{END}, predecessors)
+
+	for _, name := range sortedKeys(g.nodes) {
+		switch {
+		case !reachable[name]:
+			v.report(SeverityWarning, name, fmt.Errorf("%w: %s", ErrUnreachableNode, name))
+		case len(successors[name]) == 0:
+			v.report(SeverityWarning, name, fmt.Errorf("%w: %s", ErrNoOutgoingEdge, name))
+		case !reachesEnd[name]:
+			v.report(SeverityWarning, name, fmt.Errorf("%w: %s", ErrNoPathToEnd, name))
+		}
+	}
+}
+
+// successors returns the possible next nodes of every node, END included
+func (v *graphValidator) successors() map[string][]string {
+	g := v.graph
+
+	anyNode := make([]string, 0, len(g.nodes)+1)
+	anyNode = append(anyNode, sortedKeys(g.nodes)...)
+	anyNode = append(anyNode, END)
+
+	successors := make(map[string][]string)
+	for _, edge := range g.edges {
+		successors[edge.From] = append(successors[edge.From], edge.To)
+	}
+	for from, edge := range g.conditionalEdges {
+		if edge.destinations != nil {
+			successors[from] = append(successors[from], edge.destinations...)
+		} else {
+			successors[from] = append(successors[from], anyNode...)
+		}
+	}
+	for from := range g.sendEdges {
+		successors[from] = append(successors[from], anyNode...)
+	}
+	return successors
+}
+
+// reach returns the nodes reachable from start following links
+func reach(start []string, links map[string][]string) map[string]bool {
+	visited := make(map[string]bool)
+	queue := append([]string{}, start...)
+	for _, node := range start {
+		visited[node] = true
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, next := range links[node] {
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return visited
+}
+
+// sortedKeys returns the keys of a map keyed by node name in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,133 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func passthrough(ctx context.Context, state interface{}) (interface{}, error) {
+	return state, nil
+}
+
+func issueNodes(issues []graph.ValidationIssue) []string {
+	nodes := make([]string, len(issues))
+	for i, issue := range issues {
+		nodes[i] = issue.Node
+	}
+	return nodes
+}
+
+func TestCompile_ReportsAllErrors(t *testing.T) {
+	g := graph.NewStateGraph()
+	g.AddNode("a", "a", passthrough)
+	g.AddNode("b", "b", passthrough)
+	g.SetEntryPoint("a")
+	g.AddEdge("a", "missing")
+	g.AddEdge("ghost", "b")
+	g.AddConditionalEdge("b", func(ctx context.Context, state interface{}) string {
+		return graph.END
+	}, "a", "nowhere", graph.END)
+
+	_, err := g.Compile()
+	require.Error(t, err)
+
+	var validationErr *graph.GraphValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.ErrorIs(t, err, graph.ErrNodeNotFound)
+	assert.Equal(t, []string{"missing", "ghost", "nowhere"}, issueNodes(validationErr.Errors()))
+
+	for _, node := range []string{"missing", "ghost", "nowhere"} {
+		assert.Contains(t, err.Error(), node)
+	}
+}
+
+func TestCompile_EntryPoint(t *testing.T) {
+	g := graph.NewStateGraph()
+	g.AddNode("a", "a", passthrough)
+	g.AddEdge("a", graph.END)
+
+	_, err := g.Compile()
+	assert.ErrorIs(t, err, graph.ErrEntryPointNotSet)
+
+	g.SetEntryPoint("b")
+	_, err = g.Compile()
+	assert.ErrorIs(t, err, graph.ErrNodeNotFound)
+	assert.ErrorContains(t, err, "entry point b")
+}
+
+func TestCompile_WarningsAndStrictMode(t *testing.T) {
+	g := graph.NewStateGraph()
+	for _, name := range []string{"start", "route", "loop"} {
+		g.AddNode(name, name, passthrough)
+	}
+	g.SetEntryPoint("start")
+	g.AddEdge("start", "route")
+	g.AddConditionalEdge("route", func(ctx context.Context, state interface{}) string {
+		return graph.END
+	})
+	g.AddEdge("loop", "loop")
+
+	// Warnings do not prevent compilation by default
+	_, err := g.Compile()
+	require.NoError(t, err)
+
+	err = g.Validate()
+	var validationErr *graph.GraphValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Empty(t, validationErr.Errors())
+
+	warnings := validationErr.Warnings()
+	require.Len(t, warnings, 2)
+	assert.ErrorIs(t, warnings[0], graph.ErrUndeclaredDestinations)
+	assert.Equal(t, "route", warnings[0].Node)
+	// The undeclared conditional edge may lead to "loop", which never reaches END
+	assert.ErrorIs(t, warnings[1], graph.ErrNoPathToEnd)
+	assert.Equal(t, "loop", warnings[1].Node)
+
+	g.SetStrictValidation(true)
+	_, err = g.Compile()
+	assert.ErrorIs(t, err, graph.ErrUndeclaredDestinations)
+}
+
+func TestCompile_DeclaredDestinations(t *testing.T) {
+	g := graph.NewStateGraph()
+	for _, name := range []string{"agent", "tools", "unused"} {
+		g.AddNode(name, name, passthrough)
+	}
+	g.SetEntryPoint("agent")
+	g.AddConditionalEdge("agent", func(ctx context.Context, state interface{}) string {
+		return graph.END
+	}, "tools", graph.END)
+	g.AddEdge("tools", "agent")
+	g.AddEdge("unused", graph.END)
+	g.SetStrictValidation(true)
+
+	// With declared destinations, the unused node is known to be unreachable
+	_, err := g.Compile()
+	require.Error(t, err)
+	var validationErr *graph.GraphValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []string{"unused"}, issueNodes(validationErr.Issues))
+
+	g.AddEdge("tools", "unused")
+	_, err = g.Compile()
+	assert.NoError(t, err)
+}
+
+func TestCompile_NoOutgoingEdge(t *testing.T) {
+	g := graph.NewStateGraph()
+	g.AddNode("a", "a", passthrough)
+	g.SetEntryPoint("a")
+
+	err := g.Validate()
+	assert.ErrorIs(t, err, graph.ErrNoOutgoingEdge)
+
+	g.SetStrictValidation(true)
+	_, err = g.Compile()
+	assert.ErrorIs(t, err, graph.ErrNoOutgoingEdge)
+}
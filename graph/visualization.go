@@ -3,7 +3,9 @@ package graph
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 // Exporter provides methods to export graphs in different formats
@@ -63,13 +65,7 @@ func (ge *Exporter) DrawMermaidWithOptions(opts MermaidOptions) string {
 	}
 
 	// Add END node if referenced
-	hasEnd := false
-	for _, edge := range ge.graph.edges {
-		if edge.To == END {
-			hasEnd = true
-			break
-		}
-	}
+	hasEnd := ge.referencesEnd()
 
 	if hasEnd {
 		sb.WriteString("    END([\"END\"])\n")
@@ -81,12 +77,31 @@ func (ge *Exporter) DrawMermaidWithOptions(opts MermaidOptions) string {
 		sb.WriteString(fmt.Sprintf("    %s --> %s\n", edge.From, edge.To))
 	}
 
-	// Add conditional edges
-	for from := range ge.graph.conditionalEdges {
+	// Add conditional edges: labeled branches of the path map, or declared destinations when known
+	for _, from := range sortedKeys(ge.graph.conditionalEdges) {
+		edge := ge.graph.conditionalEdges[from]
+		if branches := edge.branches(); branches != nil {
+			for _, branch := range branches {
+				sb.WriteString(fmt.Sprintf("    %s -.->|%s| %s\n", from, mermaidLabel(branch[0]), branch[1]))
+			}
+			continue
+		}
+		if edge.destinations != nil {
+			for _, to := range edge.destinations {
+				sb.WriteString(fmt.Sprintf("    %s -.-> %s\n", from, to))
+			}
+			continue
+		}
 		sb.WriteString(fmt.Sprintf("    %s -.-> %s_condition((?))\n", from, from))
 		sb.WriteString(fmt.Sprintf("    style %s_condition fill:#FFFFE0,stroke:#333,stroke-dasharray: 5 5\n", from))
 	}
 
+	// Add send edges
+	for _, from := range sortedKeys(ge.graph.sendEdges) {
+		sb.WriteString(fmt.Sprintf("    %s -.->|Send| %s_send[[\"Send\"]]\n", from, from))
+		sb.WriteString(fmt.Sprintf("    style %s_send fill:#E0FFFF,stroke:#333,stroke-dasharray: 5 5\n", from))
+	}
+
 	// Style entry point
 	if ge.graph.entryPoint != "" {
 		sb.WriteString(fmt.Sprintf("    style %s fill:#87CEEB\n", ge.graph.entryPoint))
@@ -95,6 +110,33 @@ func (ge *Exporter) DrawMermaidWithOptions(opts MermaidOptions) string {
 	return sb.String()
 }
 
+// mermaidEscaper replaces the characters that would end a quoted Mermaid label, or be
+// read as markup, by entity codes
+var mermaidEscaper = strings.NewReplacer(
+	"#", "#35;",
+	`"`, "#quot;",
+	"|", "#124;",
+	"<", "#lt;",
+	">", "#gt;",
+	"\n", "<br>",
+)
+
+// mermaidLabel returns label as the text of a Mermaid edge: plain words are kept as
+// is, other labels are quoted and escaped
+func mermaidLabel(label string) string {
+	plain := label != ""
+	for _, r := range label {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != ' ' {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return label
+	}
+	return `"` + mermaidEscaper.Replace(label) + `"`
+}
+
 // DrawDOT generates a DOT (Graphviz) representation of the graph
 func (ge *Exporter) DrawDOT() string {
 	var sb strings.Builder
@@ -115,13 +157,7 @@ func (ge *Exporter) DrawDOT() string {
 	}
 
 	// Add END node styling if referenced
-	hasEnd := false
-	for _, edge := range ge.graph.edges {
-		if edge.To == END {
-			hasEnd = true
-			break
-		}
-	}
+	hasEnd := ge.referencesEnd()
 
 	if hasEnd {
 		sb.WriteString("    END [label=\"END\", shape=ellipse, style=filled, fillcolor=lightpink];\n")
@@ -132,12 +168,31 @@ func (ge *Exporter) DrawDOT() string {
 		sb.WriteString(fmt.Sprintf("    %s -> %s;\n", edge.From, edge.To))
 	}
 
-	// Add conditional edges
-	for from := range ge.graph.conditionalEdges {
+	// Add conditional edges: labeled branches of the path map, or declared destinations when known
+	for _, from := range sortedKeys(ge.graph.conditionalEdges) {
+		edge := ge.graph.conditionalEdges[from]
+		if branches := edge.branches(); branches != nil {
+			for _, branch := range branches {
+				sb.WriteString(fmt.Sprintf("    %s -> %s [style=dashed, label=%s];\n", from, branch[1], strconv.Quote(branch[0])))
+			}
+			continue
+		}
+		if edge.destinations != nil {
+			for _, to := range edge.destinations {
+				sb.WriteString(fmt.Sprintf("    %s -> %s [style=dashed];\n", from, to))
+			}
+			continue
+		}
 		sb.WriteString(fmt.Sprintf("    %s -> %s_condition [style=dashed, label=\"?\"];\n", from, from))
 		sb.WriteString(fmt.Sprintf("    %s_condition [label=\"?\", shape=diamond, style=filled, fillcolor=lightyellow];\n", from))
 	}
 
+	// Add send edges
+	for _, from := range sortedKeys(ge.graph.sendEdges) {
+		sb.WriteString(fmt.Sprintf("    %s -> %s_send [style=dashed, label=\"Send\"];\n", from, from))
+		sb.WriteString(fmt.Sprintf("    %s_send [label=\"Send\", shape=box3d, style=filled, fillcolor=lightcyan];\n", from))
+	}
+
 	sb.WriteString("}\n")
 	return sb.String()
 }
@@ -195,8 +250,15 @@ func (ge *Exporter) drawASCIINode(nodeName string, prefix string, isLast bool, v
 	}
 
 	// Check for conditional edge
-	if _, ok := ge.graph.conditionalEdges[nodeName]; ok {
-		outgoingEdges = append(outgoingEdges, "(Conditional)")
+	if edge, ok := ge.graph.conditionalEdges[nodeName]; ok {
+		if edge.destinations != nil {
+			outgoingEdges = append(outgoingEdges, edge.destinations...)
+		} else {
+			outgoingEdges = append(outgoingEdges, "(Conditional)")
+		}
+	}
+	if _, ok := ge.graph.sendEdges[nodeName]; ok {
+		outgoingEdges = append(outgoingEdges, "(Send)")
 	}
 
 	// Sort for consistent output
@@ -213,6 +275,13 @@ func (ge *Exporter) drawASCIINode(nodeName string, prefix string, isLast bool, v
 				condConnector = "└──"
 			}
 			sb.WriteString(fmt.Sprintf("%s%s (?)\n", nextPrefix, condConnector))
+		} else if target == "(Send)" {
+			// Draw send indicator
+			sendConnector := "├──"
+			if isLastChild {
+				sendConnector = "└──"
+			}
+			sb.WriteString(fmt.Sprintf("%s%s (Send)\n", nextPrefix, sendConnector))
 		} else {
 			ge.drawASCIINode(target, nextPrefix, isLastChild, visited, sb)
 		}
@@ -223,3 +292,20 @@ func (ge *Exporter) drawASCIINode(nodeName string, prefix string, isLast bool, v
 func (r *Runnable) GetGraph() *Exporter {
 	return NewExporter(r.graph)
 }
+
+// referencesEnd reports whether an edge or a declared conditional destination leads to END
+func (ge *Exporter) referencesEnd() bool {
+	for _, edge := range ge.graph.edges {
+		if edge.To == END {
+			return true
+		}
+	}
+	for _, edge := range ge.graph.conditionalEdges {
+		for _, to := range edge.destinations {
+			if to == END {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -48,3 +48,95 @@ func TestVisualization(t *testing.T) {
 	// C is not reachable via static edges from B, so it won't be shown under B.
 	// This is expected behavior for static visualization of dynamic graphs.
 }
+
+func TestVisualizationSendEdges(t *testing.T) {
+	g := NewStateGraph()
+	g.AddNode("split", "split", func(ctx context.Context, state interface{}) (interface{}, error) { return state, nil })
+	g.AddNode("work", "work", func(ctx context.Context, state interface{}) (interface{}, error) { return state, nil })
+	g.SetEntryPoint("split")
+	g.AddConditionalSendEdge("split", func(ctx context.Context, state interface{}) []Send {
+		return []Send{NewSend("work", state)}
+	})
+	g.AddEdge("work", END)
+
+	runnable, err := g.Compile()
+	assert.NoError(t, err)
+
+	exporter := runnable.GetGraph()
+	assert.Contains(t, exporter.DrawMermaid(), "split -.->|Send| split_send[[\"Send\"]]")
+	assert.Contains(t, exporter.DrawDOT(), "split -> split_send [style=dashed, label=\"Send\"]")
+	assert.Contains(t, exporter.DrawASCII(), "(Send)")
+}
+
+func TestVisualizationDeclaredDestinations(t *testing.T) {
+	g := NewStateGraph()
+	g.AddNode("agent", "agent", func(ctx context.Context, state interface{}) (interface{}, error) { return state, nil })
+	g.AddNode("tools", "tools", func(ctx context.Context, state interface{}) (interface{}, error) { return state, nil })
+	g.SetEntryPoint("agent")
+	g.AddConditionalEdge("agent", func(ctx context.Context, state interface{}) string { return END }, "tools", END)
+	g.AddEdge("tools", "agent")
+
+	runnable, err := g.Compile()
+	assert.NoError(t, err)
+
+	exporter := runnable.GetGraph()
+	mermaid := exporter.DrawMermaid()
+	assert.Contains(t, mermaid, "agent -.-> tools")
+	assert.Contains(t, mermaid, "agent -.-> END")
+	assert.Contains(t, mermaid, "END([\"END\"])")
+	assert.NotContains(t, mermaid, "agent_condition")
+
+	dot := exporter.DrawDOT()
+	assert.Contains(t, dot, "agent -> tools [style=dashed];")
+	assert.Contains(t, dot, "agent -> END [style=dashed];")
+
+	assert.Contains(t, exporter.DrawASCII(), "tools")
+}
+
+func TestVisualizationPathMap(t *testing.T) {
+	g := NewStateGraph()
+	g.AddNode("plan", "plan", func(ctx context.Context, state interface{}) (interface{}, error) { return state, nil })
+	g.AddNode("search", "search", func(ctx context.Context, state interface{}) (interface{}, error) { return state, nil })
+	g.SetEntryPoint("plan")
+	g.AddConditionalEdges("plan", func(ctx context.Context, state interface{}) []string { return nil }, map[string]string{
+		"lookup": "search",
+		"done":   END,
+	})
+	g.AddEdge("search", END)
+
+	runnable, err := g.Compile()
+	assert.NoError(t, err)
+
+	exporter := runnable.GetGraph()
+	mermaid := exporter.DrawMermaid()
+	assert.Contains(t, mermaid, "plan -.->|done| END")
+	assert.Contains(t, mermaid, "plan -.->|lookup| search")
+
+	dot := exporter.DrawDOT()
+	assert.Contains(t, dot, "plan -> END [style=dashed, label=\"done\"];")
+	assert.Contains(t, dot, "plan -> search [style=dashed, label=\"lookup\"];")
+}
+
+func TestVisualizationEscapesPathMapLabels(t *testing.T) {
+	g := NewStateGraph()
+	g.AddNode("plan", "plan", func(ctx context.Context, state interface{}) (interface{}, error) { return state, nil })
+	g.AddNode("search", "search", func(ctx context.Context, state interface{}) (interface{}, error) { return state, nil })
+	g.SetEntryPoint("plan")
+	g.AddConditionalEdges("plan", func(ctx context.Context, state interface{}) []string { return nil }, map[string]string{
+		`say "hi" | <b>`: "search",
+		"line\nbreak":    END,
+	})
+	g.AddEdge("search", END)
+
+	runnable, err := g.Compile()
+	assert.NoError(t, err)
+
+	exporter := runnable.GetGraph()
+	mermaid := exporter.DrawMermaid()
+	assert.Contains(t, mermaid, `plan -.->|"say #quot;hi#quot; #124; #lt;b#gt;"| search`)
+	assert.Contains(t, mermaid, `plan -.->|"line<br>break"| END`)
+
+	dot := exporter.DrawDOT()
+	assert.Contains(t, dot, `plan -> search [style=dashed, label="say \"hi\" | <b>"];`)
+	assert.Contains(t, dot, `plan -> END [style=dashed, label="line\nbreak"];`)
+}
@@ -1,6 +1,7 @@
 package log
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -23,7 +24,8 @@ const (
 	LogLevelNone
 )
 
-// Logger interface for PTC logging
+// Logger is a printf-style logger. The loggers of this package also implement
+// ContextLogger, to write structured records.
 type Logger interface {
 	Debug(format string, v ...interface{})
 	Info(format string, v ...interface{})
@@ -31,7 +33,9 @@ type Logger interface {
 	Error(format string, v ...interface{})
 }
 
-// DefaultLogger implements Logger using Go's standard log package
+// DefaultLogger implements Logger using Go's standard log package. The lines are
+// prefixed with "[PTC]" and the level; the fields of structured records are appended
+// to the message as key=value pairs.
 type DefaultLogger struct {
 	logger *log.Logger
 	level  LogLevel
@@ -81,6 +85,34 @@ func (l *DefaultLogger) Error(format string, v ...interface{}) {
 	}
 }
 
+// DebugContext logs a debug record
+func (l *DefaultLogger) DebugContext(ctx context.Context, msg string, args ...any) {
+	if l.level <= LogLevelDebug {
+		l.logger.Printf("[DEBUG] %s%s", msg, formatFields(ctx, args...))
+	}
+}
+
+// InfoContext logs an informational record
+func (l *DefaultLogger) InfoContext(ctx context.Context, msg string, args ...any) {
+	if l.level <= LogLevelInfo {
+		l.logger.Printf("[INFO] %s%s", msg, formatFields(ctx, args...))
+	}
+}
+
+// WarnContext logs a warning record
+func (l *DefaultLogger) WarnContext(ctx context.Context, msg string, args ...any) {
+	if l.level <= LogLevelWarn {
+		l.logger.Printf("[WARN] %s%s", msg, formatFields(ctx, args...))
+	}
+}
+
+// ErrorContext logs an error record
+func (l *DefaultLogger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	if l.level <= LogLevelError {
+		l.logger.Printf("[ERROR] %s%s", msg, formatFields(ctx, args...))
+	}
+}
+
 // NoOpLogger is a logger that doesn't log anything
 type NoOpLogger struct{}
 
@@ -96,6 +128,18 @@ func (l *NoOpLogger) Warn(format string, v ...interface{}) {}
 // Error does nothing
 func (l *NoOpLogger) Error(format string, v ...interface{}) {}
 
+// DebugContext does nothing
+func (l *NoOpLogger) DebugContext(ctx context.Context, msg string, args ...any) {}
+
+// InfoContext does nothing
+func (l *NoOpLogger) InfoContext(ctx context.Context, msg string, args ...any) {}
+
+// WarnContext does nothing
+func (l *NoOpLogger) WarnContext(ctx context.Context, msg string, args ...any) {}
+
+// ErrorContext does nothing
+func (l *NoOpLogger) ErrorContext(ctx context.Context, msg string, args ...any) {}
+
 // String returns the string representation of LogLevel
 func (l LogLevel) String() string {
 	switch l {
@@ -114,7 +158,8 @@ func (l LogLevel) String() string {
 	}
 }
 
-// Package-level logger (default is DefaultLogger with info level)
+// Package-level logger (default is DefaultLogger with info level). Use
+// SetDefaultLogger(NewTextLogger(...)) or NewJSONLogger to write structured records.
 var defaultLogger Logger = NewDefaultLogger(LogLevelInfo)
 
 // SetDefaultLogger sets the package-level logger
@@ -153,3 +198,40 @@ func Warn(format string, v ...interface{}) {
 func Error(format string, v ...interface{}) {
 	defaultLogger.Error(format, v...)
 }
+
+// DebugContext logs a debug record using the package-level logger. When the logger
+// is not a ContextLogger, the fields are appended to the message.
+func DebugContext(ctx context.Context, msg string, args ...any) {
+	if logger, ok := defaultLogger.(ContextLogger); ok {
+		logger.DebugContext(ctx, msg, args...)
+		return
+	}
+	defaultLogger.Debug("%s%s", msg, formatFields(ctx, args...))
+}
+
+// InfoContext logs an informational record using the package-level logger
+func InfoContext(ctx context.Context, msg string, args ...any) {
+	if logger, ok := defaultLogger.(ContextLogger); ok {
+		logger.InfoContext(ctx, msg, args...)
+		return
+	}
+	defaultLogger.Info("%s%s", msg, formatFields(ctx, args...))
+}
+
+// WarnContext logs a warning record using the package-level logger
+func WarnContext(ctx context.Context, msg string, args ...any) {
+	if logger, ok := defaultLogger.(ContextLogger); ok {
+		logger.WarnContext(ctx, msg, args...)
+		return
+	}
+	defaultLogger.Warn("%s%s", msg, formatFields(ctx, args...))
+}
+
+// ErrorContext logs an error record using the package-level logger
+func ErrorContext(ctx context.Context, msg string, args ...any) {
+	if logger, ok := defaultLogger.(ContextLogger); ok {
+		logger.ErrorContext(ctx, msg, args...)
+		return
+	}
+	defaultLogger.Error("%s%s", msg, formatFields(ctx, args...))
+}
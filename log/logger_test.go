@@ -180,8 +180,9 @@ func TestSetLogLevel(t *testing.T) {
 
 	SetLogLevel(LogLevelInfo)
 
-	// Verify the logger is set (we can't directly check the type, but we can test behavior)
-	// This is implicitly tested by the package level functions test above
+	if _, ok := defaultLogger.(*DefaultLogger); !ok {
+		t.Errorf("Expected SetLogLevel to set a DefaultLogger, got %T", defaultLogger)
+	}
 }
 
 // TestPackageLevelNoOp tests that package-level functions don't panic with default NoOpLogger
@@ -0,0 +1,250 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// ContextLogger writes structured records: a message with key/value fields, like
+// log/slog. The attributes of the context, e.g. the run ID, thread ID, node and step
+// of a graph run or the fields added with ContextWithAttrs, are added to the records.
+type ContextLogger interface {
+	DebugContext(ctx context.Context, msg string, args ...any)
+	InfoContext(ctx context.Context, msg string, args ...any)
+	WarnContext(ctx context.Context, msg string, args ...any)
+	ErrorContext(ctx context.Context, msg string, args ...any)
+}
+
+type attrsKey struct{}
+
+// ContextWithAttrs returns ctx carrying attrs, which are added to the records logged
+// with ctx. An attribute replaces the attribute of ctx with the same key.
+func ContextWithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	current := AttrsFromContext(ctx)
+	merged := make([]slog.Attr, 0, len(current)+len(attrs))
+	for _, attr := range current {
+		if !hasKey(attrs, attr.Key) {
+			merged = append(merged, attr)
+		}
+	}
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+// AttrsFromContext returns the attributes added to ctx with ContextWithAttrs
+func AttrsFromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// contextAttrFuncs compute attributes from the values carried by a context
+var contextAttrFuncs []func(ctx context.Context) []slog.Attr
+
+// RegisterContextAttrs registers fn to compute attributes of the records logged with a
+// context from the values it carries, e.g. the graph package adds the run ID, thread
+// ID, node and step of the run. The attributes are only computed when a record is
+// written. It is meant to be called from an init function.
+func RegisterContextAttrs(fn func(ctx context.Context) []slog.Attr) {
+	contextAttrFuncs = append(contextAttrFuncs, fn)
+}
+
+// contextAttrs returns the attributes of the records logged with ctx: the registered
+// attributes, replaced by the attributes added with ContextWithAttrs with the same key
+func contextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	added := AttrsFromContext(ctx)
+	var attrs []slog.Attr
+	for _, fn := range contextAttrFuncs {
+		for _, attr := range fn(ctx) {
+			if !hasKey(added, attr.Key) {
+				attrs = append(attrs, attr)
+			}
+		}
+	}
+	if attrs == nil {
+		return added
+	}
+	return append(attrs, added...)
+}
+
+func hasKey(attrs []slog.Attr, key string) bool {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// contextHandler adds the attributes of the context to the records
+type contextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps handler to add the attributes of the context (see
+// ContextWithAttrs and RegisterContextAttrs) to the records, e.g. to use it with
+// slog.SetDefault
+func NewContextHandler(handler slog.Handler) slog.Handler {
+	if _, ok := handler.(*contextHandler); ok {
+		return handler
+	}
+	return &contextHandler{Handler: handler}
+}
+
+// Handle implements slog.Handler
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs := missingAttrs(record, contextAttrs(ctx)); len(attrs) > 0 {
+		record = record.Clone()
+		record.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// missingAttrs returns the attributes of the context the record has no field for;
+// the fields of the record take precedence
+func missingAttrs(record slog.Record, attrs []slog.Attr) []slog.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	var missing []slog.Attr
+	for _, attr := range attrs {
+		found := false
+		record.Attrs(func(field slog.Attr) bool {
+			found = field.Key == attr.Key
+			return !found
+		})
+		if !found {
+			missing = append(missing, attr)
+		}
+	}
+	return missing
+}
+
+// WithAttrs implements slog.Handler
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// SlogLogger is a Logger and a ContextLogger writing records with log/slog
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger creates a logger writing to handler. The attributes of the context are
+// added to the records.
+func NewSlogLogger(handler slog.Handler) *SlogLogger {
+	return &SlogLogger{logger: slog.New(NewContextHandler(handler))}
+}
+
+// NewTextLogger creates a logger writing records as key=value pairs to out
+func NewTextLogger(out io.Writer, level LogLevel) *SlogLogger {
+	return NewSlogLogger(slog.NewTextHandler(out, &slog.HandlerOptions{Level: level.slogLevel()}))
+}
+
+// NewJSONLogger creates a logger writing records as JSON lines to out
+func NewJSONLogger(out io.Writer, level LogLevel) *SlogLogger {
+	return NewSlogLogger(slog.NewJSONHandler(out, &slog.HandlerOptions{Level: level.slogLevel()}))
+}
+
+// With returns a logger adding args to every record
+func (l *SlogLogger) With(args ...any) *SlogLogger {
+	return &SlogLogger{logger: l.logger.With(args...)}
+}
+
+// Slog returns the underlying slog.Logger
+func (l *SlogLogger) Slog() *slog.Logger {
+	return l.logger
+}
+
+// Debug logs debug messages
+func (l *SlogLogger) Debug(format string, v ...interface{}) {
+	l.logf(slog.LevelDebug, format, v...)
+}
+
+// Info logs informational messages
+func (l *SlogLogger) Info(format string, v ...interface{}) {
+	l.logf(slog.LevelInfo, format, v...)
+}
+
+// Warn logs warning messages
+func (l *SlogLogger) Warn(format string, v ...interface{}) {
+	l.logf(slog.LevelWarn, format, v...)
+}
+
+// Error logs error messages
+func (l *SlogLogger) Error(format string, v ...interface{}) {
+	l.logf(slog.LevelError, format, v...)
+}
+
+func (l *SlogLogger) logf(level slog.Level, format string, v ...interface{}) {
+	ctx := context.Background()
+	if l.logger.Enabled(ctx, level) {
+		l.logger.Log(ctx, level, fmt.Sprintf(format, v...))
+	}
+}
+
+// DebugContext logs a debug record
+func (l *SlogLogger) DebugContext(ctx context.Context, msg string, args ...any) {
+	l.logger.DebugContext(ctx, msg, args...)
+}
+
+// InfoContext logs an informational record
+func (l *SlogLogger) InfoContext(ctx context.Context, msg string, args ...any) {
+	l.logger.InfoContext(ctx, msg, args...)
+}
+
+// WarnContext logs a warning record
+func (l *SlogLogger) WarnContext(ctx context.Context, msg string, args ...any) {
+	l.logger.WarnContext(ctx, msg, args...)
+}
+
+// ErrorContext logs an error record
+func (l *SlogLogger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	l.logger.ErrorContext(ctx, msg, args...)
+}
+
+// slogLevel returns the slog level of l
+func (l LogLevel) slogLevel() slog.Level {
+	switch l {
+	case LogLevelDebug:
+		return slog.LevelDebug
+	case LogLevelInfo:
+		return slog.LevelInfo
+	case LogLevelWarn:
+		return slog.LevelWarn
+	case LogLevelError:
+		return slog.LevelError
+	default:
+		// Above any level used by the loggers
+		return slog.LevelError + 100
+	}
+}
+
+// formatFields formats the fields of a structured record, and the attributes of ctx,
+// as " key=value" pairs for the printf-style loggers
+func formatFields(ctx context.Context, args ...any) string {
+	var record slog.Record
+	record.Add(args...)
+	record.AddAttrs(missingAttrs(record, contextAttrs(ctx))...)
+
+	var fields strings.Builder
+	record.Attrs(func(attr slog.Attr) bool {
+		fields.WriteString(" ")
+		fields.WriteString(attr.String())
+		return true
+	})
+	return fields.String()
+}
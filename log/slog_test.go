@@ -0,0 +1,149 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// TestJSONLogger tests that records are enriched with the attributes of the context
+func TestJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewJSONLogger(&buf, LogLevelInfo)
+
+	ctx := ContextWithAttrs(context.Background(), slog.String("run_id", "run-1"), slog.String("node", "outer"))
+	ctx = ContextWithAttrs(ctx, slog.String("node", "inner"), slog.Int("step", 2))
+
+	logger.DebugContext(ctx, "filtered")
+	logger.InfoContext(ctx, "node start", "attempt", 1)
+	logger.WarnContext(ctx, "explicit", "node", "override")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records, got: %s", buf.String())
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Expected a JSON record: %v", err)
+	}
+	expected := map[string]interface{}{
+		"level": "INFO", "msg": "node start", "attempt": float64(1),
+		"run_id": "run-1", "node": "inner", "step": float64(2),
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("Expected %s=%v, got %v", key, value, record[key])
+		}
+	}
+
+	// Fields of the record take precedence over the attributes of the context
+	if strings.Count(lines[1], `"node"`) != 1 || !strings.Contains(lines[1], `"node":"override"`) {
+		t.Errorf("Expected the node field of the record, got: %s", lines[1])
+	}
+}
+
+// TestSlogLoggerPrintf tests the printf-style methods of SlogLogger
+func TestSlogLoggerPrintf(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewTextLogger(&buf, LogLevelWarn).With("component", "ptc")
+
+	logger.Info("info %d", 1)
+	logger.Warn("warn %d", 2)
+
+	output := buf.String()
+	if strings.Contains(output, "info 1") {
+		t.Errorf("Expected info messages to be filtered, got: %s", output)
+	}
+	if !strings.Contains(output, `level=WARN msg="warn 2" component=ptc`) {
+		t.Errorf("Expected warn record, got: %s", output)
+	}
+}
+
+// TestDefaultLoggerContext tests that the printf-style loggers append the fields
+func TestDefaultLoggerContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewCustomLogger(&buf, LogLevelInfo)
+
+	ctx := ContextWithAttrs(context.Background(), slog.String("run_id", "run-1"))
+	logger.DebugContext(ctx, "filtered")
+	logger.ErrorContext(ctx, "node error", "error", fmt.Errorf("boom"))
+
+	output := buf.String()
+	if strings.Contains(output, "filtered") {
+		t.Errorf("Expected debug records to be filtered, got: %s", output)
+	}
+	if !strings.Contains(output, "[ERROR] node error error=boom run_id=run-1") {
+		t.Errorf("Expected error record with fields, got: %s", output)
+	}
+}
+
+// printfLogger only implements Logger
+type printfLogger struct {
+	lines []string
+}
+
+func (l *printfLogger) Debug(format string, v ...interface{}) {}
+func (l *printfLogger) Info(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+func (l *printfLogger) Warn(format string, v ...interface{})  {}
+func (l *printfLogger) Error(format string, v ...interface{}) {}
+
+// TestPackageLevelContextFunctions tests the package-level structured functions with
+// a logger that only implements Logger
+func TestPackageLevelContextFunctions(t *testing.T) {
+	originalLogger := defaultLogger
+	defer func() {
+		defaultLogger = originalLogger
+	}()
+
+	logger := &printfLogger{}
+	SetDefaultLogger(logger)
+
+	ctx := ContextWithAttrs(context.Background(), slog.String("thread_id", "thread-1"))
+	InfoContext(ctx, "resumed", "step", 3)
+
+	if len(logger.lines) != 1 || logger.lines[0] != "resumed step=3 thread_id=thread-1" {
+		t.Errorf("Unexpected lines: %v", logger.lines)
+	}
+}
+
+type requestIDKey struct{}
+
+// TestRegisterContextAttrs tests that the registered attributes are computed from the
+// values of the context when a record is written
+func TestRegisterContextAttrs(t *testing.T) {
+	RegisterContextAttrs(func(ctx context.Context) []slog.Attr {
+		if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+			return []slog.Attr{slog.String("request_id", id)}
+		}
+		return nil
+	})
+
+	var buf bytes.Buffer
+	logger := NewTextLogger(&buf, LogLevelInfo)
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	logger.InfoContext(ctx, "computed")
+	logger.InfoContext(ContextWithAttrs(ctx, slog.String("request_id", "req-2")), "replaced")
+	logger.InfoContext(context.Background(), "absent")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 records, got: %s", buf.String())
+	}
+	if !strings.HasSuffix(lines[0], "msg=computed request_id=req-1") {
+		t.Errorf("Expected the registered attribute, got: %s", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "msg=replaced request_id=req-2") {
+		t.Errorf("Expected the attribute of ContextWithAttrs to replace it, got: %s", lines[1])
+	}
+	if strings.Contains(lines[2], "request_id") {
+		t.Errorf("Expected no attribute without the context value, got: %s", lines[2])
+	}
+}
@@ -0,0 +1,283 @@
+// Package otelgraph exports the spans of graph.Tracer as OpenTelemetry spans.
+//
+// A Hook is added to the tracer of a runnable; it starts an OpenTelemetry span for
+// every graph run, super-step, node, LLM call (graph.GenerateContent) and tool call
+// (graph.CallTool), nested as they are in the run, and records edge traversals as
+// zero-length spans. Node retries and cache lookups are recorded as events of the
+// node span.
+//
+// The OpenTelemetry span of the running node is available from the node context,
+// so spans started by the node, e.g. by an instrumented HTTP client, are nested in it,
+// and the trace continues in the subgraphs the node runs.
+//
+//	tracer := otelgraph.NewTracer(otelgraph.WithTracerProvider(provider))
+//	runnable.SetTracer(tracer)
+package otelgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the spans
+const ScopeName = "github.com/smallnest/langgraphgo/otelgraph"
+
+// Attribute keys set on the spans
+const (
+	AttrEvent       = attribute.Key("langgraph.event")
+	AttrNode        = attribute.Key("langgraph.node")
+	AttrStep        = attribute.Key("langgraph.step")
+	AttrStepNodes   = attribute.Key("langgraph.step.nodes")
+	AttrRunID       = attribute.Key("langgraph.run_id")
+	AttrThreadID    = attribute.Key("langgraph.thread_id")
+	AttrEdgeFrom    = attribute.Key("langgraph.edge.from")
+	AttrEdgeTo      = attribute.Key("langgraph.edge.to")
+	AttrInterrupted = attribute.Key("langgraph.interrupted")
+
+	AttrOperationName     = attribute.Key("gen_ai.operation.name")
+	AttrToolName          = attribute.Key("gen_ai.tool.name")
+	AttrUsageInputTokens  = attribute.Key("gen_ai.usage.input_tokens")
+	AttrUsageOutputTokens = attribute.Key("gen_ai.usage.output_tokens")
+	AttrUsageTotalTokens  = attribute.Key("langgraph.usage.total_tokens")
+)
+
+// metadataAttributes maps the span metadata set by the graph engine to attributes
+var metadataAttributes = map[string]attribute.Key{
+	"step":              AttrStep,
+	"nodes":             AttrStepNodes,
+	"run_id":            AttrRunID,
+	"thread_id":         AttrThreadID,
+	"tool":              AttrToolName,
+	"prompt_tokens":     AttrUsageInputTokens,
+	"completion_tokens": AttrUsageOutputTokens,
+	"total_tokens":      AttrUsageTotalTokens,
+}
+
+// pointEvents are the graph events recorded as events of the enclosing span
+var pointEvents = map[graph.TraceEvent]bool{
+	graph.TraceEventNodeRetry: true,
+	graph.TraceEventCacheHit:  true,
+	graph.TraceEventCacheMiss: true,
+}
+
+// Option configures a Hook
+type Option func(*Hook)
+
+// WithTracerProvider sets the provider of the OpenTelemetry tracer. By default the
+// global provider is used.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(h *Hook) {
+		h.provider = provider
+	}
+}
+
+// Hook is a graph.TraceHook that exports the spans of a graph.Tracer to OpenTelemetry
+type Hook struct {
+	provider trace.TracerProvider
+	tracer   trace.Tracer
+
+	mutex sync.Mutex
+	spans map[string]trace.Span
+}
+
+var _ graph.TraceContextHook = (*Hook)(nil)
+
+// NewHook creates a hook exporting spans with the given options
+func NewHook(opts ...Option) *Hook {
+	h := &Hook{
+		spans: make(map[string]trace.Span),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.provider == nil {
+		h.provider = otel.GetTracerProvider()
+	}
+	h.tracer = h.provider.Tracer(ScopeName)
+	return h
+}
+
+// NewTracer creates a graph.Tracer exporting its spans to OpenTelemetry. The spans
+// are not collected by the tracer.
+func NewTracer(opts ...Option) *graph.Tracer {
+	tracer := graph.NewTracer().WithSpanCollection(false)
+	tracer.AddHook(NewHook(opts...))
+	return tracer
+}
+
+// OnEvent implements graph.TraceHook
+func (h *Hook) OnEvent(ctx context.Context, span *graph.TraceSpan) {
+	h.mutex.Lock()
+	otelSpan, started := h.spans[span.ID]
+	if started {
+		delete(h.spans, span.ID)
+	}
+	h.mutex.Unlock()
+
+	switch {
+	case started:
+		h.end(otelSpan, span)
+	case span.Event == graph.TraceEventEdgeTraversal:
+		h.edge(ctx, span)
+	case pointEvents[span.Event]:
+		// Recorded once the event is complete
+		if !span.EndTime.IsZero() {
+			h.event(ctx, span)
+		}
+	case span.Event == graph.TraceEventNodeError:
+		// The error is recorded on the span of the node
+	case span.EndTime.IsZero():
+		h.start(ctx, span)
+	}
+}
+
+// ContextWithSpan implements graph.TraceContextHook
+func (h *Hook) ContextWithSpan(ctx context.Context, span *graph.TraceSpan) context.Context {
+	h.mutex.Lock()
+	otelSpan, ok := h.spans[span.ID]
+	h.mutex.Unlock()
+
+	if !ok {
+		return ctx
+	}
+	return trace.ContextWithSpan(ctx, otelSpan)
+}
+
+// start starts the OpenTelemetry span of span, nested in the span of ctx
+func (h *Hook) start(ctx context.Context, span *graph.TraceSpan) {
+	attrs := []attribute.KeyValue{AttrEvent.String(string(span.Event))}
+	if span.NodeName != "" && span.Event != graph.TraceEventGraphStart {
+		attrs = append(attrs, AttrNode.String(span.NodeName))
+	}
+	switch span.Event {
+	case graph.TraceEventLLMStart:
+		attrs = append(attrs, AttrOperationName.String("chat"))
+	case graph.TraceEventToolStart:
+		attrs = append(attrs, AttrOperationName.String("execute_tool"))
+	}
+
+	_, otelSpan := h.tracer.Start(ctx, spanName(span),
+		trace.WithTimestamp(span.StartTime),
+		trace.WithAttributes(attrs...),
+	)
+
+	h.mutex.Lock()
+	h.spans[span.ID] = otelSpan
+	h.mutex.Unlock()
+}
+
+// end ends the OpenTelemetry span of span with the metadata and error it ended with
+func (h *Hook) end(otelSpan trace.Span, span *graph.TraceSpan) {
+	// The engine completes the metadata once the span has started
+	otelSpan.SetName(spanName(span))
+	otelSpan.SetAttributes(metadata(span)...)
+	recordError(otelSpan, span.Error)
+	otelSpan.End(trace.WithTimestamp(span.EndTime))
+}
+
+// edge records an edge traversal as a zero-length span
+func (h *Hook) edge(ctx context.Context, span *graph.TraceSpan) {
+	_, otelSpan := h.tracer.Start(ctx, fmt.Sprintf("edge %s -> %s", span.FromNode, span.ToNode),
+		trace.WithTimestamp(span.StartTime),
+		trace.WithAttributes(
+			AttrEvent.String(string(span.Event)),
+			AttrEdgeFrom.String(span.FromNode),
+			AttrEdgeTo.String(span.ToNode),
+		),
+	)
+	otelSpan.End(trace.WithTimestamp(span.EndTime))
+}
+
+// event records span as an event of the span of ctx
+func (h *Hook) event(ctx context.Context, span *graph.TraceSpan) {
+	otelSpan := trace.SpanFromContext(ctx)
+	attrs := []attribute.KeyValue{AttrNode.String(span.NodeName)}
+	for key, value := range span.Metadata {
+		attrs = append(attrs, attributeOf(attribute.Key("langgraph."+key), value))
+	}
+	if span.Error != nil {
+		attrs = append(attrs, attribute.String("exception.message", span.Error.Error()))
+	}
+	otelSpan.AddEvent(string(span.Event), trace.WithTimestamp(span.StartTime), trace.WithAttributes(attrs...))
+}
+
+// spanName returns the name of the OpenTelemetry span of span
+func spanName(span *graph.TraceSpan) string {
+	switch span.Event {
+	case graph.TraceEventGraphStart, graph.TraceEventGraphEnd:
+		return "graph"
+	case graph.TraceEventStepStart, graph.TraceEventStepEnd:
+		if step, ok := span.Metadata["step"]; ok {
+			return fmt.Sprintf("step %v", step)
+		}
+		return "step"
+	case graph.TraceEventNodeStart, graph.TraceEventNodeEnd, graph.TraceEventNodeError:
+		return "node " + span.NodeName
+	case graph.TraceEventLLMStart, graph.TraceEventLLMEnd:
+		return "chat"
+	case graph.TraceEventToolStart, graph.TraceEventToolEnd:
+		if tool, ok := span.Metadata["tool"].(string); ok {
+			return "execute_tool " + tool
+		}
+		return "execute_tool"
+	default:
+		return string(span.Event)
+	}
+}
+
+// metadata converts the metadata of span set by the graph engine to attributes
+func metadata(span *graph.TraceSpan) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for key, value := range span.Metadata {
+		if attrKey, ok := metadataAttributes[key]; ok {
+			attrs = append(attrs, attributeOf(attrKey, value))
+		}
+	}
+	return attrs
+}
+
+// recordError records err on the span. Interrupts end the span without an error.
+func recordError(otelSpan trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	var graphInterrupt *graph.GraphInterrupt
+	var nodeInterrupt *graph.NodeInterrupt
+	if errors.As(err, &graphInterrupt) || errors.As(err, &nodeInterrupt) {
+		otelSpan.SetAttributes(AttrInterrupted.Bool(true))
+		return
+	}
+	otelSpan.RecordError(err)
+	otelSpan.SetStatus(codes.Error, err.Error())
+}
+
+// attributeOf converts a metadata value to an attribute
+func attributeOf(key attribute.Key, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return key.String(v)
+	case []string:
+		return key.StringSlice(v)
+	case int:
+		return key.Int(v)
+	case int64:
+		return key.Int64(v)
+	case float64:
+		return key.Float64(v)
+	case bool:
+		return key.Bool(v)
+	case time.Duration:
+		return key.String(v.String())
+	default:
+		return key.String(fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,252 @@
+package otelgraph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var errFlaky = errors.New("flaky")
+
+// usageLLM answers with a fixed content and token usage
+type usageLLM struct{}
+
+func (m *usageLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{
+		Content:        "answer",
+		GenerationInfo: map[string]interface{}{"PromptTokens": 12, "CompletionTokens": 5, "TotalTokens": 17},
+	}}}, nil
+}
+
+func (m *usageLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return "answer", nil
+}
+
+// newExporter returns a tracer exporting its spans to an in-memory exporter
+func newExporter() (*graph.Tracer, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return NewTracer(WithTracerProvider(provider)), exporter
+}
+
+// spanNamed returns the only exported span with the given name
+func spanNamed(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+	var found []tracetest.SpanStub
+	for _, span := range spans {
+		if span.Name == name {
+			found = append(found, span)
+		}
+	}
+	require.Len(t, found, 1, "spans named %q", name)
+	return found[0]
+}
+
+// attr returns the value of an attribute of span
+func attr(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestHook_ExportsRun(t *testing.T) {
+	tracer, exporter := newExporter()
+
+	var nodeSpanContext trace.SpanContext
+	g := graph.NewStateGraph()
+	g.AddNode("agent", "agent", func(ctx context.Context, state interface{}) (interface{}, error) {
+		nodeSpanContext = trace.SpanContextFromContext(ctx)
+		resp, err := graph.GenerateContent(ctx, &usageLLM{}, []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, state.(string))})
+		if err != nil {
+			return nil, err
+		}
+		return resp.Choices[0].Content, nil
+	})
+	g.AddNode("tools", "tools", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return graph.CallTool(ctx, "search", state.(string), func(ctx context.Context, input string) (string, error) {
+			return "found " + input, nil
+		})
+	})
+	g.AddEdge("agent", "tools")
+	g.AddEdge("tools", graph.END)
+	g.SetEntryPoint("agent")
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+	runnable.SetTracer(tracer)
+
+	res, err := runnable.InvokeWithConfig(context.Background(), "question", &graph.Config{
+		Configurable: map[string]interface{}{"thread_id": "thread-1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "found answer", res)
+
+	spans := exporter.GetSpans()
+	root := spanNamed(t, spans, "graph")
+	assert.False(t, root.Parent.IsValid())
+	assert.Equal(t, "thread-1", attr(root, AttrThreadID).AsString())
+	assert.NotEmpty(t, attr(root, AttrRunID).AsString())
+
+	// graph > step > node > chat / execute_tool, all in the same trace
+	step1 := spanNamed(t, spans, "step 1")
+	step2 := spanNamed(t, spans, "step 2")
+	assert.Equal(t, root.SpanContext.SpanID(), step1.Parent.SpanID())
+	assert.Equal(t, root.SpanContext.SpanID(), step2.Parent.SpanID())
+	assert.Equal(t, []string{"agent"}, attr(step1, AttrStepNodes).AsStringSlice())
+
+	agent := spanNamed(t, spans, "node agent")
+	assert.Equal(t, step1.SpanContext.SpanID(), agent.Parent.SpanID())
+	assert.Equal(t, "agent", attr(agent, AttrNode).AsString())
+	assert.Equal(t, int64(1), attr(agent, AttrStep).AsInt64())
+	assert.Equal(t, "thread-1", attr(agent, AttrThreadID).AsString())
+	assert.Equal(t, agent.SpanContext, nodeSpanContext)
+
+	chat := spanNamed(t, spans, "chat")
+	assert.Equal(t, agent.SpanContext.SpanID(), chat.Parent.SpanID())
+	assert.Equal(t, int64(12), attr(chat, AttrUsageInputTokens).AsInt64())
+	assert.Equal(t, int64(5), attr(chat, AttrUsageOutputTokens).AsInt64())
+	assert.Equal(t, int64(17), attr(chat, AttrUsageTotalTokens).AsInt64())
+
+	tools := spanNamed(t, spans, "node tools")
+	assert.Equal(t, step2.SpanContext.SpanID(), tools.Parent.SpanID())
+	tool := spanNamed(t, spans, "execute_tool search")
+	assert.Equal(t, tools.SpanContext.SpanID(), tool.Parent.SpanID())
+	assert.Equal(t, "search", attr(tool, AttrToolName).AsString())
+
+	// Edges are zero-length spans in the step they leave
+	edge := spanNamed(t, spans, "edge agent -> tools")
+	assert.Equal(t, step1.SpanContext.SpanID(), edge.Parent.SpanID())
+	assert.Equal(t, edge.StartTime, edge.EndTime)
+
+	for _, span := range spans {
+		assert.Equal(t, root.SpanContext.TraceID(), span.SpanContext.TraceID())
+		assert.Equal(t, codes.Unset, span.Status.Code)
+	}
+}
+
+func TestHook_PropagatesIntoSubgraphs(t *testing.T) {
+	tracer, exporter := newExporter()
+
+	child := graph.NewStateGraph()
+	child.AddNode("inner", "inner", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return state, nil
+	})
+	child.SetEntryPoint("inner")
+	child.AddEdge("inner", graph.END)
+
+	parent := graph.NewStateGraph()
+	require.NoError(t, parent.AddSubgraph("sub", child))
+	parent.SetEntryPoint("sub")
+	parent.AddEdge("sub", graph.END)
+
+	runnable, err := parent.Compile()
+	require.NoError(t, err)
+	runnable.SetTracer(tracer)
+
+	// The run continues the trace of the caller
+	ctx, caller := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "request")
+	_, err = runnable.Invoke(ctx, "input")
+	caller.End()
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	graphs := 0
+	for _, span := range spans {
+		assert.Equal(t, caller.SpanContext().TraceID(), span.SpanContext.TraceID())
+		if span.Name == "graph" {
+			graphs++
+		}
+	}
+	assert.Equal(t, 2, graphs)
+
+	sub := spanNamed(t, spans, "node sub")
+	inner := spanNamed(t, spans, "node inner")
+
+	// node inner > step 1 > graph > node sub
+	parents := make(map[trace.SpanID]tracetest.SpanStub)
+	for _, span := range spans {
+		parents[span.SpanContext.SpanID()] = span
+	}
+	var chain []string
+	for span, ok := inner, true; ok && span.SpanContext.SpanID() != sub.SpanContext.SpanID(); span, ok = parents[span.Parent.SpanID()] {
+		chain = append(chain, span.Name)
+	}
+	assert.Equal(t, []string{"node inner", "step 1", "graph"}, chain)
+}
+
+func TestHook_RecordsErrors(t *testing.T) {
+	t.Run("Failed", func(t *testing.T) {
+		tracer, exporter := newExporter()
+
+		calls := 0
+		g := graph.NewStateGraph()
+		g.AddNode("call", "call", func(ctx context.Context, state interface{}) (interface{}, error) {
+			calls++
+			return nil, errFlaky
+		}, graph.WithRetry(&graph.RetryConfig{
+			MaxAttempts:     2,
+			InitialDelay:    time.Millisecond,
+			BackoffFactor:   1,
+			RetryableErrors: graph.RetryOn(errFlaky),
+		}))
+		g.SetEntryPoint("call")
+		g.AddEdge("call", graph.END)
+
+		runnable, err := g.Compile()
+		require.NoError(t, err)
+		runnable.SetTracer(tracer)
+
+		_, err = runnable.Invoke(context.Background(), "input")
+		require.ErrorIs(t, err, errFlaky)
+		assert.Equal(t, 2, calls)
+
+		spans := exporter.GetSpans()
+		node := spanNamed(t, spans, "node call")
+		assert.Equal(t, codes.Error, node.Status.Code)
+		require.Len(t, node.Events, 2)
+		assert.Equal(t, string(graph.TraceEventNodeRetry), node.Events[0].Name)
+		assert.Equal(t, "exception", node.Events[1].Name)
+
+		assert.Equal(t, codes.Error, spanNamed(t, spans, "graph").Status.Code)
+		assert.Equal(t, codes.Error, spanNamed(t, spans, "step 1").Status.Code)
+		assert.Len(t, spans, 3)
+	})
+
+	t.Run("Interrupted", func(t *testing.T) {
+		tracer, exporter := newExporter()
+
+		g := graph.NewStateGraph()
+		g.AddNode("review", "review", func(ctx context.Context, state interface{}) (interface{}, error) {
+			return graph.Interrupt(ctx, "approve?")
+		})
+		g.SetEntryPoint("review")
+		g.AddEdge("review", graph.END)
+
+		runnable, err := g.Compile()
+		require.NoError(t, err)
+		runnable.SetTracer(tracer)
+
+		_, err = runnable.Invoke(context.Background(), "input")
+		var interrupt *graph.GraphInterrupt
+		require.ErrorAs(t, err, &interrupt)
+
+		for _, span := range exporter.GetSpans() {
+			assert.Equal(t, codes.Unset, span.Status.Code, span.Name)
+		}
+		assert.True(t, attr(spanNamed(t, exporter.GetSpans(), "graph"), AttrInterrupted).AsBool())
+	})
+}
@@ -77,7 +77,8 @@ func (c *ChatAgent) Chat(ctx context.Context, message string) (string, error) {
 		input["extra_tools"] = c.dynamicTools
 	}
 
-	// 3. Create config with thread_id
+	// 3. Create config with thread_id. When called from a node, the LLM tokens reach
+	// the stream of the parent run through the callbacks inherited by ctx.
 	config := &graph.Config{
 		Configurable: map[string]interface{}{
 			"thread_id": c.threadID,
@@ -2,7 +2,6 @@ package prebuilt
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"strings"
@@ -183,50 +182,8 @@ func CreateAgent(model llms.Model, inputTools []tools.Tool, opts ...CreateAgentO
 			return nil, fmt.Errorf("messages key not found or invalid type")
 		}
 
-		// Convert tools to ToolInfo for the model
-		var toolDefs []llms.Tool
-		// Combine input tools with extra tools
-		var allTools []tools.Tool
-		allTools = append(allTools, inputTools...)
-
-		if extra, ok := mState["extra_tools"].([]tools.Tool); ok {
-			allTools = append(allTools, extra...)
-		} else if extra, ok := mState["extra_tools"].([]interface{}); ok {
-			// Handle case where AppendReducer might return []interface{} if types were mixed or initial append
-			// But since we append []tools.Tool, reflect might keep it as []tools.Tool or []interface{} depending on implementation.
-			// Graph schema AppendReducer returns interface{}.
-			// If we appended a slice to nil, it returns the slice.
-			// If we appended slice to slice, it returns slice.
-			// We need to be careful about type assertion.
-			for _, t := range extra {
-				if tool, ok := t.(tools.Tool); ok {
-					allTools = append(allTools, tool)
-				}
-			}
-		}
-
-		// Convert tools to ToolInfo for the model
-		// var toolDefs []llms.Tool // Removed redeclaration
-		for _, t := range allTools {
-			toolDefs = append(toolDefs, llms.Tool{
-				Type: "function",
-				Function: &llms.FunctionDefinition{
-					Name:        t.Name(),
-					Description: t.Description(),
-					Parameters: map[string]interface{}{
-						"type": "object",
-						"properties": map[string]interface{}{
-							"input": map[string]interface{}{
-								"type":        "string",
-								"description": "The input query for the tool",
-							},
-						},
-						"required":             []string{"input"},
-						"additionalProperties": false,
-					},
-				},
-			})
-		}
+		// Combine input tools with extra tools and convert them to ToolInfo for the model
+		toolDefs := toolDefinitions(agentTools(inputTools, mState))
 
 		// We need to pass tools to the model
 		callOpts := []llms.CallOption{
@@ -266,7 +223,7 @@ func CreateAgent(model llms.Model, inputTools []tools.Tool, opts ...CreateAgentO
 			msgsToSend = options.StateModifier(msgsToSend)
 		}
 
-		resp, err := model.GenerateContent(ctx, msgsToSend, callOpts...)
+		resp, err := graph.GenerateContent(ctx, model, msgsToSend, callOpts...)
 		if err != nil {
 			return nil, err
 		}
@@ -309,62 +266,18 @@ func CreateAgent(model llms.Model, inputTools []tools.Tool, opts ...CreateAgentO
 			return nil, fmt.Errorf("last message is not an AI message")
 		}
 
-		var toolMessages []llms.MessageContent
-
-		for _, part := range lastMsg.Parts {
-			if tc, ok := part.(llms.ToolCall); ok {
-				// Parse arguments to get input
-				var args map[string]interface{}
-				if err := json.Unmarshal([]byte(tc.FunctionCall.Arguments), &args); err != nil {
-					// If unmarshal fails, try to use the raw string if it's not JSON object
-				}
-
-				inputVal := ""
-				if val, ok := args["input"].(string); ok {
-					inputVal = val
-				} else {
-					inputVal = tc.FunctionCall.Arguments
-				}
-
-				// Combine input tools with extra tools for execution
-				var allTools []tools.Tool
-				allTools = append(allTools, inputTools...)
-				if extra, ok := mState["extra_tools"].([]tools.Tool); ok {
-					allTools = append(allTools, extra...)
-				} else if extra, ok := mState["extra_tools"].([]interface{}); ok {
-					for _, t := range extra {
-						if tool, ok := t.(tools.Tool); ok {
-							allTools = append(allTools, tool)
-						}
-					}
-				}
-
-				// Create a temporary executor for this run
-				// Optimization: We could cache this if tools don't change often, but here they might.
-				currentToolExecutor := NewToolExecutor(allTools)
-
-				// Execute tool
-				res, err := currentToolExecutor.Execute(ctx, ToolInvocation{
-					Tool:      tc.FunctionCall.Name,
-					ToolInput: inputVal,
-				})
-				if err != nil {
-					res = fmt.Sprintf("Error: %v", err)
-				}
-
-				// Create ToolMessage
-				toolMsg := llms.MessageContent{
-					Role: llms.ChatMessageTypeTool,
-					Parts: []llms.ContentPart{
-						llms.ToolCallResponse{
-							ToolCallID: tc.ID,
-							Name:       tc.FunctionCall.Name,
-							Content:    res,
-						},
-					},
-				}
-				toolMessages = append(toolMessages, toolMsg)
-			}
+		// Create a temporary executor for this run, since extra tools may change between runs
+		currentToolExecutor := NewToolExecutor(agentTools(inputTools, mState))
+		runner := toolCallRunner{
+			executor:       currentToolExecutor,
+			maxParallelism: 1,
+			errorFormat: func(name string, err error) string {
+				return fmt.Sprintf("Error: %v", err)
+			},
+		}
+		toolMessages, err := runner.run(ctx, lastMsg)
+		if err != nil {
+			return nil, err
 		}
 
 		return map[string]interface{}{
@@ -397,13 +310,32 @@ func CreateAgent(model llms.Model, inputTools []tools.Tool, opts ...CreateAgentO
 			return "tools"
 		}
 		return graph.END
-	})
+	}, "tools", graph.END)
 
 	workflow.AddEdge("tools", "agent")
 
 	return workflow.Compile()
 }
 
+// agentTools combines the agent's input tools with the extra tools found in the state.
+func agentTools(inputTools []tools.Tool, state map[string]interface{}) []tools.Tool {
+	var allTools []tools.Tool
+	allTools = append(allTools, inputTools...)
+
+	if extra, ok := state["extra_tools"].([]tools.Tool); ok {
+		allTools = append(allTools, extra...)
+	} else if extra, ok := state["extra_tools"].([]interface{}); ok {
+		// AppendReducer may widen the slice to []interface{}
+		for _, t := range extra {
+			if tool, ok := t.(tools.Tool); ok {
+				allTools = append(allTools, tool)
+			}
+		}
+	}
+
+	return allTools
+}
+
 func discoverSkills(skillDir string) (map[string]*goskills.SkillPackage, error) {
 	packages, err := goskills.ParseSkillPackages(skillDir)
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/smallnest/langgraphgo/graph"
 	"github.com/stretchr/testify/assert"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/tools"
@@ -179,3 +180,102 @@ func TestCreateAgent_StateModifier(t *testing.T) {
 	assert.Equal(t, llms.ChatMessageTypeHuman, firstCallMessages[0].Role)
 	assert.Equal(t, "Modified: Hello", firstCallMessages[0].Parts[0].(llms.TextContent).Text)
 }
+
+// MockStreamingLLM streams its content in chunks when a streaming function is set
+type MockStreamingLLM struct {
+	chunks []string
+}
+
+func (m *MockStreamingLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	content := ""
+	for _, chunk := range m.chunks {
+		content += chunk
+		if opts.StreamingFunc != nil {
+			if err := opts.StreamingFunc(ctx, []byte(chunk)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: content}}}, nil
+}
+
+func (m *MockStreamingLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return "", nil
+}
+
+func TestCreateAgent_StreamsTokens(t *testing.T) {
+	mockLLM := &MockStreamingLLM{chunks: []string{"Hello", " world"}}
+	agent, err := CreateAgent(mockLLM, nil)
+	assert.NoError(t, err)
+
+	events := make(chan graph.StreamEvent, 100)
+	listener := graph.NewStreamingListener(events, graph.StreamConfig{Mode: graph.StreamModeMessages})
+
+	_, err = agent.InvokeWithConfig(context.Background(), map[string]interface{}{
+		"messages": []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "Hi")},
+	}, &graph.Config{Callbacks: []graph.CallbackHandler{listener}})
+	assert.NoError(t, err)
+	close(events)
+
+	var tokens []string
+	for event := range events {
+		if event.Event == graph.EventToken {
+			assert.Equal(t, "agent", event.NodeName)
+			assert.NotEmpty(t, event.Metadata["run_id"])
+			assert.NotEmpty(t, event.Metadata["message_id"])
+			tokens = append(tokens, event.State.(string))
+		}
+	}
+	assert.Equal(t, []string{"Hello", " world"}, tokens)
+}
+
+// chainCounter counts the runs it is notified of
+type chainCounter struct {
+	graph.NoOpCallbackHandler
+	starts int
+}
+
+func (c *chainCounter) OnChainStart(ctx context.Context, serialized map[string]interface{}, inputs map[string]interface{}, runID string, parentRunID *string, tags []string, metadata map[string]interface{}) {
+	c.starts++
+}
+
+func TestChatAgent_StreamsTokensFromParentRun(t *testing.T) {
+	mockLLM := &MockStreamingLLM{chunks: []string{"Hi", "!"}}
+	agent, err := NewChatAgent(mockLLM, nil)
+	assert.NoError(t, err)
+
+	g := graph.NewStateGraph()
+	g.AddNode("chat", "chat", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return agent.Chat(ctx, state.(string))
+	})
+	g.SetEntryPoint("chat")
+	g.AddEdge("chat", graph.END)
+	runnable, err := g.Compile()
+	assert.NoError(t, err)
+
+	events := make(chan graph.StreamEvent, 100)
+	listener := graph.NewStreamingListener(events, graph.StreamConfig{Mode: graph.StreamModeMessages})
+	counter := &chainCounter{}
+
+	resp, err := runnable.InvokeWithConfig(context.Background(), "Hello", &graph.Config{
+		Callbacks: []graph.CallbackHandler{listener, counter},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi!", resp)
+	close(events)
+
+	var tokens []string
+	for event := range events {
+		if event.Event == graph.EventToken {
+			tokens = append(tokens, event.State.(string))
+		}
+	}
+	assert.Equal(t, []string{"Hi", "!"}, tokens)
+
+	// The run of the agent is not reported to the callbacks of the parent run
+	assert.Equal(t, 1, counter.starts)
+}
@@ -74,6 +74,11 @@ type RAGConfig struct {
 	UseReranking   bool    // Whether to use reranking
 	UseFallback    bool    // Whether to use fallback search
 
+	// Hybrid search configuration, used by BuildAdvancedRAG
+	UseHybridSearch bool         // Whether to fuse the Retriever with the KeywordRetriever
+	FusionMethod    FusionMethod // How to fuse the rankings, FusionRRF when empty
+	HybridWeights   []float64    // Weights of the Retriever and the KeywordRetriever, equal when nil
+
 	// Generation configuration
 	SystemPrompt     string
 	IncludeCitations bool
@@ -88,6 +93,13 @@ type RAGConfig struct {
 	Retriever   Retriever
 	Reranker    Reranker
 	LLM         llms.Model
+
+	// KeywordRetriever is the lexical retriever of hybrid search, e.g. a BM25Retriever
+	KeywordRetriever Retriever
+
+	// RetrievalCache, when set, caches the results of the retrieve node, so the same
+	// query is not retrieved again, including when a run is resumed
+	RetrievalCache *graph.CachePolicy
 }
 
 // DefaultRAGConfig returns a default RAG configuration
@@ -106,8 +118,9 @@ func DefaultRAGConfig() *RAGConfig {
 
 // RAGPipeline represents a complete RAG pipeline
 type RAGPipeline struct {
-	config *RAGConfig
-	graph  *graph.StateGraph
+	config    *RAGConfig
+	graph     *graph.StateGraph
+	retriever Retriever // replaces config.Retriever, e.g. for hybrid search
 }
 
 // NewRAGPipeline creates a new RAG pipeline with the given configuration
@@ -132,7 +145,7 @@ func (p *RAGPipeline) BuildBasicRAG() error {
 	}
 
 	// Add retrieval node
-	p.graph.AddNode("retrieve", "Document retrieval node", p.retrieveNode)
+	p.graph.AddNode("retrieve", "Document retrieval node", p.retrieveNode, p.retrieveOptions()...)
 
 	// Add generation node
 	p.graph.AddNode("generate", "Answer generation node", p.generateNode)
@@ -145,7 +158,9 @@ func (p *RAGPipeline) BuildBasicRAG() error {
 	return nil
 }
 
-// BuildAdvancedRAG builds an advanced RAG pipeline: Retrieve -> Rerank -> Generate
+// BuildAdvancedRAG builds an advanced RAG pipeline: Retrieve -> Rerank -> Generate.
+// With UseHybridSearch, the retrieve node fuses the results of the Retriever and the
+// KeywordRetriever with an EnsembleRetriever.
 func (p *RAGPipeline) BuildAdvancedRAG() error {
 	if p.config.Retriever == nil {
 		return fmt.Errorf("retriever is required for advanced RAG")
@@ -153,9 +168,23 @@ func (p *RAGPipeline) BuildAdvancedRAG() error {
 	if p.config.LLM == nil {
 		return fmt.Errorf("LLM is required for advanced RAG")
 	}
+	if p.config.UseHybridSearch {
+		if p.config.KeywordRetriever == nil {
+			return fmt.Errorf("keyword retriever is required for hybrid search")
+		}
+		if p.config.HybridWeights != nil && len(p.config.HybridWeights) != 2 {
+			return fmt.Errorf("hybrid search needs 2 weights, got %d", len(p.config.HybridWeights))
+		}
+
+		ensemble := NewEnsembleRetriever([]Retriever{p.config.Retriever, p.config.KeywordRetriever}, p.config.HybridWeights, p.config.TopK)
+		if p.config.FusionMethod != "" {
+			ensemble.Method = p.config.FusionMethod
+		}
+		p.retriever = ensemble
+	}
 
 	// Add retrieval node
-	p.graph.AddNode("retrieve", "Document retrieval node", p.retrieveNode)
+	p.graph.AddNode("retrieve", "Document retrieval node", p.retrieveNode, p.retrieveOptions()...)
 
 	// Add reranking node if enabled
 	if p.config.UseReranking && p.config.Reranker != nil {
@@ -200,7 +229,7 @@ func (p *RAGPipeline) BuildConditionalRAG() error {
 	}
 
 	// Add retrieval node
-	p.graph.AddNode("retrieve", "Document retrieval node", p.retrieveNode)
+	p.graph.AddNode("retrieve", "Document retrieval node", p.retrieveNode, p.retrieveOptions()...)
 
 	// Add reranking node
 	p.graph.AddNode("rerank", "Document reranking node", p.rerankNode)
@@ -260,13 +289,34 @@ func (p *RAGPipeline) GetGraph() *graph.StateGraph {
 
 // Node implementations
 
+// retrieveOptions returns the policies of the retrieve node
+func (p *RAGPipeline) retrieveOptions() []graph.NodeOption {
+	if p.config.RetrievalCache == nil {
+		return nil
+	}
+	policy := *p.config.RetrievalCache
+	if policy.Decode == nil {
+		// Serializing caches return the state as a map
+		policy.Decode = graph.DecodeAs[RAGState]()
+	}
+	return []graph.NodeOption{graph.WithCache(policy)}
+}
+
 func (p *RAGPipeline) retrieveNode(ctx context.Context, state interface{}) (interface{}, error) {
 	ragState := state.(RAGState)
+	writer := graph.GetStreamWriter(ctx)
 
-	docs, err := p.config.Retriever.GetRelevantDocuments(ctx, ragState.Query)
+	retriever := p.config.Retriever
+	if p.retriever != nil {
+		retriever = p.retriever
+	}
+
+	writer(map[string]interface{}{"status": "retrieving", "query": ragState.Query})
+	docs, err := retriever.GetRelevantDocuments(ctx, ragState.Query)
 	if err != nil {
 		return nil, fmt.Errorf("retrieval failed: %w", err)
 	}
+	writer(map[string]interface{}{"status": "retrieved", "documents": len(docs)})
 
 	ragState.RetrievedDocuments = docs
 	ragState.Documents = docs
@@ -389,3 +439,8 @@ func NewVectorStoreRetriever(vectorStore VectorStore, topK int) *VectorStoreRetr
 func (r *VectorStoreRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]Document, error) {
 	return r.VectorStore.SimilaritySearch(ctx, query, r.TopK)
 }
+
+// GetRelevantDocumentsWithScore retrieves relevant documents with their similarity scores
+func (r *VectorStoreRetriever) GetRelevantDocumentsWithScore(ctx context.Context, query string) ([]DocumentWithScore, error) {
+	return r.VectorStore.SimilaritySearchWithScore(ctx, query, r.TopK)
+}
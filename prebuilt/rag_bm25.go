@@ -0,0 +1,169 @@
+package prebuilt
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// BM25Retriever implements Retriever with an in-memory keyword index ranked by Okapi
+// BM25. It finds the documents containing the exact terms of a query, e.g. names,
+// identifiers or error codes, which an embedding can miss; combine it with a vector
+// retriever in an EnsembleRetriever for hybrid search.
+type BM25Retriever struct {
+	mutex     sync.RWMutex
+	topK      int
+	k1        float64
+	b         float64
+	tokenizer func(string) []string
+
+	documents   []Document
+	lengths     []int
+	postings    map[string][]bm25Posting
+	totalLength int
+}
+
+// bm25Posting is the frequency of a term in a document
+type bm25Posting struct {
+	doc  int
+	freq int
+}
+
+// BM25Option configures a BM25Retriever
+type BM25Option func(*BM25Retriever)
+
+// WithBM25Parameters sets the term frequency saturation k1 (1.2 by default) and the
+// length normalization b (0.75 by default)
+func WithBM25Parameters(k1, b float64) BM25Option {
+	return func(r *BM25Retriever) {
+		r.k1 = k1
+		r.b = b
+	}
+}
+
+// WithTokenizer sets the function splitting documents and queries into terms. The
+// default lowercases the text and splits it on anything but letters and digits.
+func WithTokenizer(tokenizer func(string) []string) BM25Option {
+	return func(r *BM25Retriever) {
+		r.tokenizer = tokenizer
+	}
+}
+
+// NewBM25Retriever creates a BM25Retriever indexing documents, which returns the topK
+// best matches of a query
+func NewBM25Retriever(documents []Document, topK int, opts ...BM25Option) *BM25Retriever {
+	r := &BM25Retriever{
+		topK:      topK,
+		k1:        1.2,
+		b:         0.75,
+		tokenizer: tokenize,
+		postings:  make(map[string][]bm25Posting),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	r.AddDocuments(documents)
+	return r
+}
+
+// AddDocuments adds documents to the index
+func (r *BM25Retriever) AddDocuments(documents []Document) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, doc := range documents {
+		id := len(r.documents)
+		terms := r.tokenizer(doc.PageContent)
+
+		freqs := make(map[string]int)
+		for _, term := range terms {
+			freqs[term]++
+		}
+		for term, freq := range freqs {
+			r.postings[term] = append(r.postings[term], bm25Posting{doc: id, freq: freq})
+		}
+
+		r.documents = append(r.documents, doc)
+		r.lengths = append(r.lengths, len(terms))
+		r.totalLength += len(terms)
+	}
+}
+
+// GetRelevantDocuments retrieves the documents best matching the terms of query
+func (r *BM25Retriever) GetRelevantDocuments(ctx context.Context, query string) ([]Document, error) {
+	results, err := r.GetRelevantDocumentsWithScore(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]Document, len(results))
+	for i, result := range results {
+		docs[i] = result.Document
+	}
+	return docs, nil
+}
+
+// GetRelevantDocumentsWithScore retrieves the documents best matching the terms of
+// query with their BM25 scores. Documents sharing no term with query are left out.
+func (r *BM25Retriever) GetRelevantDocumentsWithScore(ctx context.Context, query string) ([]DocumentWithScore, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if len(r.documents) == 0 {
+		return []DocumentWithScore{}, nil
+	}
+
+	n := float64(len(r.documents))
+	avgLength := float64(r.totalLength) / n
+	if avgLength == 0 {
+		avgLength = 1
+	}
+
+	scores := make(map[int]float64)
+	seen := make(map[string]bool)
+	for _, term := range r.tokenizer(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		postings := r.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, posting := range postings {
+			freq := float64(posting.freq)
+			norm := r.k1 * (1 - r.b + r.b*float64(r.lengths[posting.doc])/avgLength)
+			scores[posting.doc] += idf * freq * (r.k1 + 1) / (freq + norm)
+		}
+	}
+
+	k := r.topK
+	if k <= 0 {
+		k = len(scores)
+	}
+	top := &minScoreHeap{}
+	for doc, score := range scores {
+		top.pushTopK(scoredItem{index: doc, score: score}, k)
+	}
+
+	items := top.sorted()
+	results := make([]DocumentWithScore, len(items))
+	for i, item := range items {
+		results[i] = DocumentWithScore{Document: r.documents[item.index], Score: item.score}
+	}
+	return results, nil
+}
+
+// tokenize lowercases text and splits it on anything but letters and digits
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsNumber(c)
+	})
+}
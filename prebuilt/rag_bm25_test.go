@@ -0,0 +1,87 @@
+package prebuilt
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func bm25Corpus() []Document {
+	return []Document{
+		{PageContent: "The deployment failed with error E1234 after the upgrade.", Metadata: map[string]interface{}{"source": "incident.md"}},
+		{PageContent: "Deployments are rolled out with a canary stage before the upgrade completes.", Metadata: map[string]interface{}{"source": "runbook.md"}},
+		{PageContent: "The upgrade guide lists the steps of the upgrade, the upgrade checks and the rollback of an upgrade.", Metadata: map[string]interface{}{"source": "guide.md"}},
+		{PageContent: "Vector search finds documents by meaning.", Metadata: map[string]interface{}{"source": "search.md"}},
+	}
+}
+
+func TestBM25Retriever(t *testing.T) {
+	ctx := context.Background()
+	retriever := NewBM25Retriever(bm25Corpus(), 3)
+
+	results, err := retriever.GetRelevantDocumentsWithScore(ctx, "Error e1234 upgrade")
+	if err != nil {
+		t.Fatalf("Failed to retrieve: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected the 3 documents mentioning the terms, got %d", len(results))
+	}
+	if results[0].Document.Metadata["source"] != "incident.md" {
+		t.Errorf("Expected the document with the exact error code first, got %v", results[0].Document.Metadata["source"])
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Score > results[i-1].Score {
+			t.Errorf("Expected results sorted by score, got %v", results)
+		}
+	}
+
+	// Term frequency saturates and long documents are normalized, so repeating a
+	// common term does not outrank a rare one
+	results, _ = retriever.GetRelevantDocumentsWithScore(ctx, "canary upgrade")
+	if results[0].Document.Metadata["source"] != "runbook.md" {
+		t.Errorf("Expected the document with the rare term first, got %v", results[0].Document.Metadata["source"])
+	}
+
+	docs, err := retriever.GetRelevantDocuments(ctx, "kubernetes")
+	if err != nil {
+		t.Fatalf("Failed to retrieve: %v", err)
+	}
+	if len(docs) != 0 {
+		t.Errorf("Expected no documents for an unknown term, got %v", docs)
+	}
+
+	retriever.AddDocuments([]Document{{PageContent: "Kubernetes runs the deployment."}})
+	docs, _ = retriever.GetRelevantDocuments(ctx, "kubernetes")
+	if len(docs) != 1 {
+		t.Errorf("Expected the added document, got %v", docs)
+	}
+}
+
+func TestBM25Retriever_Options(t *testing.T) {
+	ctx := context.Background()
+
+	// Match on prefixes of 4 characters, e.g. deploy/deployment
+	prefixes := WithTokenizer(func(text string) []string {
+		terms := tokenize(text)
+		for i, term := range terms {
+			if len(term) > 4 {
+				terms[i] = term[:4]
+			}
+		}
+		return terms
+	})
+	retriever := NewBM25Retriever(bm25Corpus(), 0, prefixes, WithBM25Parameters(1.5, 0))
+
+	docs, err := retriever.GetRelevantDocuments(ctx, "deploy")
+	if err != nil {
+		t.Fatalf("Failed to retrieve: %v", err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("Expected the 2 documents about deployments, got %d", len(docs))
+	}
+	for _, doc := range docs {
+		if !strings.Contains(strings.ToLower(doc.PageContent), "deploy") {
+			t.Errorf("Unexpected document: %s", doc.PageContent)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"sync"
 )
 
 // SimpleTextSplitter splits text into chunks of a given size
@@ -91,8 +92,10 @@ func (s *SimpleTextSplitter) splitText(text string) []string {
 	return chunks
 }
 
-// InMemoryVectorStore is a simple in-memory vector store implementation
+// InMemoryVectorStore is a simple in-memory vector store implementation. Use
+// FileVectorStore to update, delete, filter or persist documents.
 type InMemoryVectorStore struct {
+	mutex      sync.RWMutex
 	documents  []Document
 	embeddings [][]float64
 	embedder   Embedder
@@ -113,12 +116,20 @@ func (s *InMemoryVectorStore) AddDocuments(ctx context.Context, documents []Docu
 		return fmt.Errorf("number of documents (%d) must match number of embeddings (%d)", len(documents), len(embeddings))
 	}
 
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	s.documents = append(s.documents, documents...)
 	s.embeddings = append(s.embeddings, embeddings...)
 
 	return nil
 }
 
+func (s *InMemoryVectorStore) len() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.documents)
+}
+
 // SimilaritySearch performs similarity search and returns top k documents
 func (s *InMemoryVectorStore) SimilaritySearch(ctx context.Context, query string, k int) ([]Document, error) {
 	results, err := s.SimilaritySearchWithScore(ctx, query, k)
@@ -136,7 +147,7 @@ func (s *InMemoryVectorStore) SimilaritySearch(ctx context.Context, query string
 
 // SimilaritySearchWithScore performs similarity search and returns documents with scores
 func (s *InMemoryVectorStore) SimilaritySearchWithScore(ctx context.Context, query string, k int) ([]DocumentWithScore, error) {
-	if len(s.documents) == 0 {
+	if s.len() == 0 {
 		return nil, fmt.Errorf("no documents in vector store")
 	}
 
@@ -146,37 +157,21 @@ func (s *InMemoryVectorStore) SimilaritySearchWithScore(ctx context.Context, que
 		return nil, fmt.Errorf("failed to embed query: %w", err)
 	}
 
-	// Calculate similarities
-	type docScore struct {
-		index int
-		score float64
-	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 
-	scores := make([]docScore, len(s.documents))
+	// Keep the top k similarities
+	top := &minScoreHeap{}
 	for i, docEmb := range s.embeddings {
-		similarity := cosineSimilarity(queryEmbedding, docEmb)
-		scores[i] = docScore{index: i, score: similarity}
-	}
-
-	// Sort by score (descending)
-	for i := 0; i < len(scores); i++ {
-		for j := i + 1; j < len(scores); j++ {
-			if scores[j].score > scores[i].score {
-				scores[i], scores[j] = scores[j], scores[i]
-			}
-		}
-	}
-
-	// Return top k
-	if k > len(scores) {
-		k = len(scores)
+		top.pushTopK(scoredItem{index: i, score: cosineSimilarity(queryEmbedding, docEmb)}, k)
 	}
 
-	results := make([]DocumentWithScore, k)
-	for i := 0; i < k; i++ {
+	items := top.sorted()
+	results := make([]DocumentWithScore, len(items))
+	for i, item := range items {
 		results[i] = DocumentWithScore{
-			Document: s.documents[scores[i].index],
-			Score:    scores[i].score,
+			Document: s.documents[item.index],
+			Score:    item.score,
 		}
 	}
 
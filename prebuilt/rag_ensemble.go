@@ -0,0 +1,204 @@
+package prebuilt
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// FusionMethod selects how an EnsembleRetriever fuses the rankings of its retrievers
+type FusionMethod string
+
+const (
+	// FusionRRF scores a document with the sum of weight / (k + rank) over the
+	// rankings it appears in (reciprocal rank fusion). It only uses ranks, so the
+	// scales of the retrievers' scores do not matter.
+	FusionRRF FusionMethod = "rrf"
+	// FusionWeighted scores a document with the weighted sum of its scores, each
+	// min-max normalized to [0, 1] within its ranking
+	FusionWeighted FusionMethod = "weighted"
+)
+
+// DefaultRRFK is the rank constant of reciprocal rank fusion
+const DefaultRRFK = 60
+
+// ScoredRetriever is a Retriever that also returns the relevance scores of the
+// documents, which FusionWeighted fuses
+type ScoredRetriever interface {
+	Retriever
+	GetRelevantDocumentsWithScore(ctx context.Context, query string) ([]DocumentWithScore, error)
+}
+
+// EnsembleRetriever implements Retriever by querying several retrievers concurrently
+// and fusing their rankings, e.g. a BM25Retriever and a VectorStoreRetriever for
+// hybrid search. Documents are matched across rankings by their content.
+type EnsembleRetriever struct {
+	Retrievers []Retriever
+	Weights    []float64    // Weight of each retriever, 1 when nil
+	Method     FusionMethod // FusionRRF when empty
+	RRFK       float64      // Rank constant of FusionRRF, DefaultRRFK when 0
+	TopK       int          // Number of documents returned, all when 0
+}
+
+// NewEnsembleRetriever creates an EnsembleRetriever fusing retrievers with reciprocal
+// rank fusion. Weights may be nil to weigh the retrievers equally.
+func NewEnsembleRetriever(retrievers []Retriever, weights []float64, topK int) *EnsembleRetriever {
+	return &EnsembleRetriever{
+		Retrievers: retrievers,
+		Weights:    weights,
+		Method:     FusionRRF,
+		TopK:       topK,
+	}
+}
+
+// GetRelevantDocuments retrieves the documents with the best fused scores
+func (r *EnsembleRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]Document, error) {
+	results, err := r.GetRelevantDocumentsWithScore(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]Document, len(results))
+	for i, result := range results {
+		docs[i] = result.Document
+	}
+	return docs, nil
+}
+
+// GetRelevantDocumentsWithScore retrieves the documents with their fused scores, best
+// first
+func (r *EnsembleRetriever) GetRelevantDocumentsWithScore(ctx context.Context, query string) ([]DocumentWithScore, error) {
+	if len(r.Retrievers) == 0 {
+		return nil, fmt.Errorf("ensemble retriever has no retrievers")
+	}
+	if r.Weights != nil && len(r.Weights) != len(r.Retrievers) {
+		return nil, fmt.Errorf("number of weights (%d) must match number of retrievers (%d)", len(r.Weights), len(r.Retrievers))
+	}
+
+	rankings, err := r.retrieve(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var fused []DocumentWithScore
+	switch r.Method {
+	case FusionRRF, "":
+		fused = r.fuseRanks(rankings)
+	case FusionWeighted:
+		fused = r.fuseScores(rankings)
+	default:
+		return nil, fmt.Errorf("unknown fusion method %q", r.Method)
+	}
+
+	sort.SliceStable(fused, func(i, j int) bool {
+		return fused[i].Score > fused[j].Score
+	})
+	if r.TopK > 0 && len(fused) > r.TopK {
+		fused = fused[:r.TopK]
+	}
+	return fused, nil
+}
+
+// retrieve queries the retrievers concurrently. Retrievers without scores get scores
+// decreasing with the rank.
+func (r *EnsembleRetriever) retrieve(ctx context.Context, query string) ([][]DocumentWithScore, error) {
+	rankings := make([][]DocumentWithScore, len(r.Retrievers))
+	errs := make([]error, len(r.Retrievers))
+
+	var wg sync.WaitGroup
+	for i, retriever := range r.Retrievers {
+		wg.Add(1)
+		go func(i int, retriever Retriever) {
+			defer wg.Done()
+
+			if scored, ok := retriever.(ScoredRetriever); ok {
+				rankings[i], errs[i] = scored.GetRelevantDocumentsWithScore(ctx, query)
+				return
+			}
+
+			docs, err := retriever.GetRelevantDocuments(ctx, query)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			rankings[i] = make([]DocumentWithScore, len(docs))
+			for rank, doc := range docs {
+				rankings[i][rank] = DocumentWithScore{Document: doc, Score: 1 / float64(rank+1)}
+			}
+		}(i, retriever)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("retriever %d failed: %w", i, err)
+		}
+	}
+	return rankings, nil
+}
+
+func (r *EnsembleRetriever) weight(i int) float64 {
+	if r.Weights == nil {
+		return 1
+	}
+	return r.Weights[i]
+}
+
+// fuseRanks applies reciprocal rank fusion
+func (r *EnsembleRetriever) fuseRanks(rankings [][]DocumentWithScore) []DocumentWithScore {
+	k := r.RRFK
+	if k == 0 {
+		k = DefaultRRFK
+	}
+
+	fused := newFusedDocuments()
+	for i, ranking := range rankings {
+		for rank, result := range ranking {
+			fused.add(result.Document, r.weight(i)/(k+float64(rank+1)))
+		}
+	}
+	return fused.results
+}
+
+// fuseScores sums the weighted, min-max normalized scores
+func (r *EnsembleRetriever) fuseScores(rankings [][]DocumentWithScore) []DocumentWithScore {
+	fused := newFusedDocuments()
+	for i, ranking := range rankings {
+		low, high := math.Inf(1), math.Inf(-1)
+		for _, result := range ranking {
+			low = math.Min(low, result.Score)
+			high = math.Max(high, result.Score)
+		}
+
+		for _, result := range ranking {
+			normalized := 1.0
+			if high > low {
+				normalized = (result.Score - low) / (high - low)
+			}
+			fused.add(result.Document, r.weight(i)*normalized)
+		}
+	}
+	return fused.results
+}
+
+// fusedDocuments accumulates the scores of the documents, in the order they are first
+// seen
+type fusedDocuments struct {
+	index   map[string]int
+	results []DocumentWithScore
+}
+
+func newFusedDocuments() *fusedDocuments {
+	return &fusedDocuments{index: make(map[string]int)}
+}
+
+func (f *fusedDocuments) add(doc Document, score float64) {
+	if i, ok := f.index[doc.PageContent]; ok {
+		f.results[i].Score += score
+		return
+	}
+	f.index[doc.PageContent] = len(f.results)
+	f.results = append(f.results, DocumentWithScore{Document: doc, Score: score})
+}
@@ -0,0 +1,172 @@
+package prebuilt
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+// staticRetriever returns the same documents for every query
+type staticRetriever struct {
+	docs []Document
+	err  error
+}
+
+func (r *staticRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]Document, error) {
+	return r.docs, r.err
+}
+
+// scoredStaticRetriever returns the same scored documents for every query
+type scoredStaticRetriever struct {
+	results []DocumentWithScore
+}
+
+func (r *scoredStaticRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]Document, error) {
+	docs := make([]Document, len(r.results))
+	for i, result := range r.results {
+		docs[i] = result.Document
+	}
+	return docs, nil
+}
+
+func (r *scoredStaticRetriever) GetRelevantDocumentsWithScore(ctx context.Context, query string) ([]DocumentWithScore, error) {
+	return r.results, nil
+}
+
+func contents(results []DocumentWithScore) []string {
+	out := make([]string, len(results))
+	for i, result := range results {
+		out[i] = result.Document.PageContent
+	}
+	return out
+}
+
+func TestEnsembleRetriever_RRF(t *testing.T) {
+	ctx := context.Background()
+	vector := &staticRetriever{docs: []Document{{PageContent: "a"}, {PageContent: "b"}, {PageContent: "c"}}}
+	keyword := &staticRetriever{docs: []Document{{PageContent: "c"}, {PageContent: "d"}}}
+
+	retriever := NewEnsembleRetriever([]Retriever{vector, keyword}, nil, 3)
+	results, err := retriever.GetRelevantDocumentsWithScore(ctx, "query")
+	if err != nil {
+		t.Fatalf("Failed to retrieve: %v", err)
+	}
+
+	// c is ranked by both retrievers: 1/63 + 1/61. b and d tie, b is seen first.
+	expected := []string{"c", "a", "b"}
+	if got := contents(results); len(got) != 3 || got[0] != expected[0] || got[1] != expected[1] || got[2] != expected[2] {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	if math.Abs(results[0].Score-(1.0/63+1.0/61)) > 1e-12 {
+		t.Errorf("Unexpected fused score %v", results[0].Score)
+	}
+
+	// Weighing the keyword retriever up puts d before a
+	retriever.Weights = []float64{1, 2}
+	results, _ = retriever.GetRelevantDocumentsWithScore(ctx, "query")
+	if got := contents(results); got[1] != "d" {
+		t.Errorf("Expected d second, got %v", got)
+	}
+}
+
+func TestEnsembleRetriever_Weighted(t *testing.T) {
+	ctx := context.Background()
+	vector := &scoredStaticRetriever{results: []DocumentWithScore{
+		{Document: Document{PageContent: "a"}, Score: 0.92},
+		{Document: Document{PageContent: "b"}, Score: 0.90},
+		{Document: Document{PageContent: "c"}, Score: 0.80},
+	}}
+	keyword := &scoredStaticRetriever{results: []DocumentWithScore{
+		{Document: Document{PageContent: "b"}, Score: 14.2},
+		{Document: Document{PageContent: "d"}, Score: 3.1},
+	}}
+
+	retriever := &EnsembleRetriever{
+		Retrievers: []Retriever{vector, keyword},
+		Weights:    []float64{0.5, 0.5},
+		Method:     FusionWeighted,
+	}
+	results, err := retriever.GetRelevantDocumentsWithScore(ctx, "query")
+	if err != nil {
+		t.Fatalf("Failed to retrieve: %v", err)
+	}
+
+	// Normalized: a=1, b=5/6 for the vector scores; b=1, d=0 for the keyword scores
+	expected := map[string]float64{"b": 0.5*5/6 + 0.5, "a": 0.5, "c": 0, "d": 0}
+	if got := contents(results); len(got) != 4 || got[0] != "b" || got[1] != "a" {
+		t.Fatalf("Expected b then a, got %v", got)
+	}
+	for _, result := range results {
+		if math.Abs(result.Score-expected[result.Document.PageContent]) > 1e-9 {
+			t.Errorf("Expected %s to score %v, got %v", result.Document.PageContent, expected[result.Document.PageContent], result.Score)
+		}
+	}
+}
+
+func TestEnsembleRetriever_Errors(t *testing.T) {
+	ctx := context.Background()
+	errSearch := errors.New("search unavailable")
+	ok := &staticRetriever{docs: []Document{{PageContent: "a"}}}
+
+	retriever := NewEnsembleRetriever([]Retriever{ok, &staticRetriever{err: errSearch}}, nil, 0)
+	if _, err := retriever.GetRelevantDocuments(ctx, "query"); !errors.Is(err, errSearch) {
+		t.Errorf("Expected the retriever error, got %v", err)
+	}
+
+	retriever = NewEnsembleRetriever([]Retriever{ok}, []float64{1, 2}, 0)
+	if _, err := retriever.GetRelevantDocuments(ctx, "query"); err == nil {
+		t.Error("Expected an error for mismatched weights")
+	}
+
+	retriever = &EnsembleRetriever{Retrievers: []Retriever{ok}, Method: "max"}
+	if _, err := retriever.GetRelevantDocuments(ctx, "query"); err == nil {
+		t.Error("Expected an error for an unknown fusion method")
+	}
+}
+
+func TestRAGPipeline_HybridSearch(t *testing.T) {
+	ctx := context.Background()
+	corpus := bm25Corpus()
+
+	config := DefaultRAGConfig()
+	config.LLM = &mockLLM{}
+	config.IncludeCitations = false
+	config.TopK = 2
+	config.UseHybridSearch = true
+
+	// The vector retriever misses the document with the exact error code
+	config.Retriever = &staticRetriever{docs: []Document{corpus[3], corpus[1]}}
+
+	pipeline := NewRAGPipeline(config)
+	if err := pipeline.BuildAdvancedRAG(); err == nil {
+		t.Fatal("Expected an error without a keyword retriever")
+	}
+
+	config.KeywordRetriever = NewBM25Retriever(corpus, 2)
+	pipeline = NewRAGPipeline(config)
+	if err := pipeline.BuildAdvancedRAG(); err != nil {
+		t.Fatalf("Failed to build advanced RAG pipeline: %v", err)
+	}
+	runnable, err := pipeline.Compile()
+	if err != nil {
+		t.Fatalf("Failed to compile pipeline: %v", err)
+	}
+
+	result, err := runnable.Invoke(ctx, RAGState{Query: "What is error E1234?"})
+	if err != nil {
+		t.Fatalf("Failed to run pipeline: %v", err)
+	}
+
+	docs := result.(RAGState).RetrievedDocuments
+	if len(docs) != 2 {
+		t.Fatalf("Expected %d documents, got %d", config.TopK, len(docs))
+	}
+	found := false
+	for _, doc := range docs {
+		found = found || doc.Metadata["source"] == "incident.md"
+	}
+	if !found {
+		t.Errorf("Expected the keyword match to be retrieved, got %v", docs)
+	}
+}
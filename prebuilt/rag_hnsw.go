@@ -0,0 +1,244 @@
+package prebuilt
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+)
+
+// HNSWConfig configures the approximate nearest neighbour index of a FileVectorStore.
+// The zero value uses the defaults.
+type HNSWConfig struct {
+	// M is the number of neighbours linked to a node, 2*M on the bottom layer.
+	// Defaults to 16.
+	M int
+	// EfConstruction is the size of the candidate list when inserting. Defaults to 200.
+	EfConstruction int
+	// EfSearch is the size of the candidate list when searching, raised to k when
+	// smaller. Larger values trade speed for recall. Defaults to 64.
+	EfSearch int
+	// Seed seeds the layer assignment, so the same documents build the same index
+	Seed int64
+}
+
+func (c HNSWConfig) withDefaults() HNSWConfig {
+	if c.M < 2 {
+		c.M = 16
+	}
+	if c.EfConstruction <= 0 {
+		c.EfConstruction = 200
+	}
+	if c.EfSearch <= 0 {
+		c.EfSearch = 64
+	}
+	return c
+}
+
+// hnswNode is a document in the graph. Deleted nodes stay in the graph to keep it
+// connected, but are never returned.
+type hnswNode struct {
+	record  *vectorRecord
+	links   [][]int
+	deleted bool
+}
+
+// hnswIndex is a Hierarchical Navigable Small World graph (Malkov & Yashunin), which
+// finds the nearest neighbours of a vector by walking down layers of increasingly
+// dense proximity graphs. It is not safe for concurrent use: the store holds its
+// lock around the index.
+type hnswIndex struct {
+	config    HNSWConfig
+	nodes     []*hnswNode
+	entry     int
+	maxLevel  int
+	levelMult float64
+	rng       *rand.Rand
+	deleted   int
+}
+
+func newHNSWIndex(config HNSWConfig) *hnswIndex {
+	config = config.withDefaults()
+	return &hnswIndex{
+		config:    config,
+		entry:     -1,
+		levelMult: 1 / math.Log(float64(config.M)),
+		rng:       rand.New(rand.NewSource(config.Seed)),
+	}
+}
+
+// maxLinks returns the number of neighbours a node keeps on layer
+func (h *hnswIndex) maxLinks(layer int) int {
+	if layer == 0 {
+		return 2 * h.config.M
+	}
+	return h.config.M
+}
+
+// insert adds record to the graph
+func (h *hnswIndex) insert(record *vectorRecord) {
+	level := int(-math.Log(1-h.rng.Float64()) * h.levelMult)
+	id := len(h.nodes)
+	node := &hnswNode{record: record, links: make([][]int, level+1)}
+	h.nodes = append(h.nodes, node)
+	record.node = id
+
+	if h.entry < 0 {
+		h.entry, h.maxLevel = id, level
+		return
+	}
+
+	entry := h.entry
+	for layer := h.maxLevel; layer > level; layer-- {
+		entry = h.greedy(record, entry, layer)
+	}
+
+	entries := []int{entry}
+	for layer := min(level, h.maxLevel); layer >= 0; layer-- {
+		candidates := h.searchLayer(record, entries, h.config.EfConstruction, layer, nil)
+
+		neighbours := candidates
+		if len(neighbours) > h.config.M {
+			neighbours = neighbours[:h.config.M]
+		}
+		node.links[layer] = make([]int, 0, len(neighbours))
+		for _, neighbour := range neighbours {
+			node.links[layer] = append(node.links[layer], neighbour.index)
+			h.link(neighbour.index, id, layer)
+		}
+
+		entries = entries[:0]
+		for _, candidate := range candidates {
+			entries = append(entries, candidate.index)
+		}
+	}
+
+	if level > h.maxLevel {
+		h.entry, h.maxLevel = id, level
+	}
+}
+
+// link adds to from a link to to on layer, dropping its farthest neighbour when it
+// has too many
+func (h *hnswIndex) link(from, to, layer int) {
+	node := h.nodes[from]
+	node.links[layer] = append(node.links[layer], to)
+	if len(node.links[layer]) <= h.maxLinks(layer) {
+		return
+	}
+
+	worst, worstScore := 0, math.Inf(1)
+	for i, neighbour := range node.links[layer] {
+		if score := node.record.similarity(h.nodes[neighbour].record); score < worstScore {
+			worst, worstScore = i, score
+		}
+	}
+	node.links[layer] = append(node.links[layer][:worst], node.links[layer][worst+1:]...)
+}
+
+// remove marks record as deleted
+func (h *hnswIndex) remove(record *vectorRecord) {
+	if node := h.nodes[record.node]; !node.deleted {
+		node.deleted = true
+		h.deleted++
+	}
+}
+
+// stale reports whether most of the nodes are deleted, so the index should be rebuilt
+func (h *hnswIndex) stale() bool {
+	return h.deleted > 0 && h.deleted*2 > len(h.nodes)
+}
+
+// search returns the k nodes closest to query accepted by accept, best first
+func (h *hnswIndex) search(query *vectorRecord, k int, accept func(*vectorRecord) bool) []scoredItem {
+	if h.entry < 0 || k <= 0 {
+		return nil
+	}
+
+	entry := h.entry
+	for layer := h.maxLevel; layer > 0; layer-- {
+		entry = h.greedy(query, entry, layer)
+	}
+
+	results := h.searchLayer(query, []int{entry}, max(h.config.EfSearch, k), 0, func(node *hnswNode) bool {
+		return !node.deleted && (accept == nil || accept(node.record))
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// greedy walks layer from entry to the node closest to query
+func (h *hnswIndex) greedy(query *vectorRecord, entry, layer int) int {
+	best, bestScore := entry, query.similarity(h.nodes[entry].record)
+	for improved := true; improved; {
+		improved = false
+		for _, neighbour := range h.nodes[best].links[layer] {
+			if score := query.similarity(h.nodes[neighbour].record); score > bestScore {
+				best, bestScore, improved = neighbour, score, true
+			}
+		}
+	}
+	return best
+}
+
+// searchLayer returns the ef nodes of layer closest to query accepted by accept,
+// best first. Rejected nodes are still walked through, so a filter does not
+// disconnect the graph.
+func (h *hnswIndex) searchLayer(query *vectorRecord, entries []int, ef, layer int, accept func(*hnswNode) bool) []scoredItem {
+	visited := make(map[int]struct{}, ef*4)
+	candidates := &maxScoreHeap{}
+	results := &minScoreHeap{}
+
+	for _, entry := range entries {
+		if _, ok := visited[entry]; ok {
+			continue
+		}
+		visited[entry] = struct{}{}
+		item := scoredItem{index: entry, score: query.similarity(h.nodes[entry].record)}
+		heap.Push(candidates, item)
+		if accept == nil || accept(h.nodes[entry]) {
+			results.pushTopK(item, ef)
+		}
+	}
+
+	for candidates.Len() > 0 {
+		current := heap.Pop(candidates).(scoredItem)
+		if results.Len() >= ef && current.score < (*results)[0].score {
+			break
+		}
+
+		for _, neighbour := range h.nodes[current.index].links[layer] {
+			if _, ok := visited[neighbour]; ok {
+				continue
+			}
+			visited[neighbour] = struct{}{}
+
+			item := scoredItem{index: neighbour, score: query.similarity(h.nodes[neighbour].record)}
+			if results.Len() < ef || item.score > (*results)[0].score {
+				heap.Push(candidates, item)
+				if accept == nil || accept(h.nodes[neighbour]) {
+					results.pushTopK(item, ef)
+				}
+			}
+		}
+	}
+
+	return results.sorted()
+}
+
+// maxScoreHeap pops the best scores first
+type maxScoreHeap []scoredItem
+
+func (h maxScoreHeap) Len() int           { return len(h) }
+func (h maxScoreHeap) Less(i, j int) bool { return h[i].score > h[j].score }
+func (h maxScoreHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *maxScoreHeap) Push(x any) { *h = append(*h, x.(scoredItem)) }
+
+func (h *maxScoreHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,211 @@
+package prebuilt
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Metadata filter operators
+const (
+	FilterEq  = "$eq"
+	FilterNe  = "$ne"
+	FilterGt  = "$gt"
+	FilterGte = "$gte"
+	FilterLt  = "$lt"
+	FilterLte = "$lte"
+	FilterIn  = "$in"
+	FilterNin = "$nin"
+)
+
+// MetadataFilter restricts a search to the documents whose metadata match it. Each
+// key names a metadata field and maps either to the value the field must equal, or
+// to a map of operators:
+//
+//	MetadataFilter{
+//		"source": "handbook.pdf",
+//		"year":   map[string]interface{}{"$gte": 2020, "$lt": 2024},
+//		"lang":   map[string]interface{}{"$in": []string{"en", "de"}},
+//	}
+//
+// Numbers compare by value whatever their type, strings and times compare in their
+// natural order. All the fields must match; a missing field only matches $ne and $nin.
+type MetadataFilter map[string]interface{}
+
+// Validate checks the operators of the filter and their operands
+func (f MetadataFilter) Validate() error {
+	for field, condition := range f {
+		operators, ok := condition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for operator, operand := range operators {
+			switch operator {
+			case FilterEq, FilterNe:
+			case FilterGt, FilterGte, FilterLt, FilterLte:
+				if !isOrdered(operand) {
+					return fmt.Errorf("filter on %q: %s needs a number, a string or a time, got %T", field, operator, operand)
+				}
+			case FilterIn, FilterNin:
+				if kind := reflect.ValueOf(operand).Kind(); kind != reflect.Slice && kind != reflect.Array {
+					return fmt.Errorf("filter on %q: %s needs a list, got %T", field, operator, operand)
+				}
+			default:
+				return fmt.Errorf("filter on %q: unknown operator %s", field, operator)
+			}
+		}
+	}
+	return nil
+}
+
+// Match reports whether metadata match the filter
+func (f MetadataFilter) Match(metadata map[string]interface{}) bool {
+	for field, condition := range f {
+		value, present := metadata[field]
+
+		operators, ok := condition.(map[string]interface{})
+		if !ok {
+			if !present || !valuesEqual(value, condition) {
+				return false
+			}
+			continue
+		}
+
+		for operator, operand := range operators {
+			if !matchOperator(operator, value, present, operand) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchOperator applies a filter operator to the value of a metadata field
+func matchOperator(operator string, value interface{}, present bool, operand interface{}) bool {
+	switch operator {
+	case FilterEq:
+		return present && valuesEqual(value, operand)
+	case FilterNe:
+		return !present || !valuesEqual(value, operand)
+	case FilterIn:
+		return present && listContains(operand, value)
+	case FilterNin:
+		return !present || !listContains(operand, value)
+	}
+
+	if !present {
+		return false
+	}
+	c, ok := compareValues(value, operand)
+	if !ok {
+		return false
+	}
+	switch operator {
+	case FilterGt:
+		return c > 0
+	case FilterGte:
+		return c >= 0
+	case FilterLt:
+		return c < 0
+	case FilterLte:
+		return c <= 0
+	}
+	return false
+}
+
+// listContains reports whether the slice list holds value
+func listContains(list interface{}, value interface{}) bool {
+	items := reflect.ValueOf(list)
+	if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+		return false
+	}
+	for i := 0; i < items.Len(); i++ {
+		if valuesEqual(value, items.Index(i).Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+// valuesEqual compares numbers by value, so 2020 equals 2020.0 restored from JSON
+func valuesEqual(a, b interface{}) bool {
+	if c, ok := compareValues(a, b); ok {
+		return c == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compareValues orders two numbers, two strings or two times
+func compareValues(a, b interface{}) (int, bool) {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y), true
+		}
+	}
+	return 0, false
+}
+
+func isOrdered(v interface{}) bool {
+	if _, ok := toFloat(v); ok {
+		return true
+	}
+	switch v.(type) {
+	case string, time.Time:
+		return true
+	}
+	return false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
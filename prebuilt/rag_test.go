@@ -2,9 +2,12 @@ package prebuilt
 
 import (
 	"context"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/smallnest/langgraphgo/checkpoint/sqlite"
+	"github.com/smallnest/langgraphgo/graph"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/openai"
 )
@@ -324,6 +327,115 @@ func TestRAGPipelineAdvanced(t *testing.T) {
 	}
 }
 
+func TestRAGPipeline_StreamsRetrievalProgress(t *testing.T) {
+	ctx := context.Background()
+
+	embedder := NewMockEmbedder(128)
+	vectorStore := NewInMemoryVectorStore(embedder)
+	docs := []Document{
+		{PageContent: "Document 1 about AI"},
+		{PageContent: "Document 2 about ML"},
+	}
+	embeddings, err := embedder.EmbedDocuments(ctx, []string{docs[0].PageContent, docs[1].PageContent})
+	if err != nil {
+		t.Fatalf("Failed to generate embeddings: %v", err)
+	}
+	if err := vectorStore.AddDocuments(ctx, docs, embeddings); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+
+	config := DefaultRAGConfig()
+	config.Retriever = NewVectorStoreRetriever(vectorStore, 2)
+	config.LLM = &mockLLM{}
+
+	pipeline := NewRAGPipeline(config)
+	if err := pipeline.BuildBasicRAG(); err != nil {
+		t.Fatalf("Failed to build RAG pipeline: %v", err)
+	}
+	runnable, err := pipeline.Compile()
+	if err != nil {
+		t.Fatalf("Failed to compile pipeline: %v", err)
+	}
+
+	var progress []interface{}
+	for chunk := range runnable.Stream(ctx, RAGState{Query: "What is AI?"}, nil, graph.StreamModeCustom) {
+		if chunk.Err != nil {
+			t.Fatalf("Failed to run pipeline: %v", chunk.Err)
+		}
+		if chunk.Node != "retrieve" {
+			t.Errorf("Expected progress from the retrieve node, got %q", chunk.Node)
+		}
+		progress = append(progress, chunk.Data)
+	}
+
+	if len(progress) != 2 {
+		t.Fatalf("Expected 2 progress chunks, got %d", len(progress))
+	}
+	last := progress[1].(map[string]interface{})
+	if last["documents"] != 2 {
+		t.Errorf("Expected 2 retrieved documents to be reported, got %v", last["documents"])
+	}
+}
+
+// countingRetriever returns fixed documents and counts the calls
+type countingRetriever struct {
+	calls int
+}
+
+func (r *countingRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]Document, error) {
+	r.calls++
+	return []Document{{PageContent: "Document about " + query}}, nil
+}
+
+func TestRAGPipeline_RetrievalCache(t *testing.T) {
+	sqliteCache, err := sqlite.NewSqliteNodeCache(sqlite.SqliteNodeCacheOptions{Path: filepath.Join(t.TempDir(), "cache.db")})
+	if err != nil {
+		t.Fatalf("Failed to create SQLite cache: %v", err)
+	}
+	defer sqliteCache.Close()
+
+	caches := map[string]graph.NodeCache{
+		"memory": graph.NewMemoryNodeCache(10),
+		// Returns the cached state as a map
+		"sqlite": sqliteCache,
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			retriever := &countingRetriever{}
+
+			config := DefaultRAGConfig()
+			config.Retriever = retriever
+			config.LLM = &mockLLM{}
+			config.RetrievalCache = &graph.CachePolicy{Cache: cache}
+
+			pipeline := NewRAGPipeline(config)
+			if err := pipeline.BuildBasicRAG(); err != nil {
+				t.Fatalf("Failed to build RAG pipeline: %v", err)
+			}
+			runnable, err := pipeline.Compile()
+			if err != nil {
+				t.Fatalf("Failed to compile pipeline: %v", err)
+			}
+
+			for _, query := range []string{"AI", "AI", "ML"} {
+				result, err := runnable.Invoke(ctx, RAGState{Query: query})
+				if err != nil {
+					t.Fatalf("Failed to run pipeline: %v", err)
+				}
+				if docs := result.(RAGState).Documents; len(docs) != 1 || docs[0].PageContent != "Document about "+query {
+					t.Errorf("Unexpected documents for %q: %v", query, docs)
+				}
+			}
+
+			if retriever.calls != 2 {
+				t.Errorf("Expected 2 retrievals, got %d", retriever.calls)
+			}
+		})
+	}
+}
+
 // mockLLM is a simple mock LLM for testing
 type mockLLM struct{}
 
@@ -0,0 +1,585 @@
+package prebuilt
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// vectorFileVersion is the version of the snapshot format
+const vectorFileVersion = 1
+
+// FileVectorStore is a thread-safe vector store kept in memory and persisted to a
+// file, so it needs no external service.
+//
+// Documents are identified by an ID, taken from their "id" metadata field or
+// generated, so they can be updated and deleted. Searches keep the top k documents
+// in a heap and can be restricted with a MetadataFilter. With WithHNSWIndex, they
+// use an approximate HNSW index instead of comparing the query with every document.
+//
+// Save writes a snapshot of the store to its file, and NewFileVectorStore loads it
+// back. Metadata are stored as JSON, so numbers are restored as float64.
+type FileVectorStore struct {
+	mutex     sync.RWMutex
+	saveMutex sync.Mutex
+	path      string
+	embedder  Embedder
+	autoSave  bool
+	hnsw      *HNSWConfig
+
+	records   map[string]*vectorRecord
+	nextSeq   int
+	dimension int
+	index     *hnswIndex
+}
+
+// vectorRecord is a stored document with its embedding
+type vectorRecord struct {
+	id        string
+	seq       int // insertion order, which breaks ties and orders snapshots
+	document  Document
+	embedding []float64
+	norm      float64
+	node      int // node of the HNSW index
+}
+
+func newVectorRecord(embedding []float64) *vectorRecord {
+	var norm float64
+	for _, v := range embedding {
+		norm += v * v
+	}
+	return &vectorRecord{embedding: embedding, norm: math.Sqrt(norm)}
+}
+
+// similarity returns the cosine similarity of the embeddings of r and other
+func (r *vectorRecord) similarity(other *vectorRecord) float64 {
+	if r.norm == 0 || other.norm == 0 {
+		return 0
+	}
+	var dot float64
+	for i, v := range r.embedding {
+		dot += v * other.embedding[i]
+	}
+	return dot / (r.norm * other.norm)
+}
+
+// FileVectorStoreOption configures a FileVectorStore
+type FileVectorStoreOption func(*FileVectorStore)
+
+// WithHNSWIndex makes the store search an HNSW index, which answers in sub-linear
+// time on large stores at the cost of approximate results. The index is built in
+// memory when the store is loaded.
+func WithHNSWIndex(config HNSWConfig) FileVectorStoreOption {
+	return func(s *FileVectorStore) {
+		s.hnsw = &config
+	}
+}
+
+// WithAutoSave saves the store to its file after every change
+func WithAutoSave(enabled bool) FileVectorStoreOption {
+	return func(s *FileVectorStore) {
+		s.autoSave = enabled
+	}
+}
+
+// NewFileVectorStore creates a vector store persisted to path, loading the snapshot
+// at path when it exists. An empty path keeps the store in memory only. The embedder
+// embeds the queries of SimilaritySearch.
+func NewFileVectorStore(path string, embedder Embedder, opts ...FileVectorStoreOption) (*FileVectorStore, error) {
+	s := &FileVectorStore{
+		path:     path,
+		embedder: embedder,
+		records:  make(map[string]*vectorRecord),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.hnsw != nil {
+		s.index = newHNSWIndex(*s.hnsw)
+	}
+
+	if path == "" {
+		return s, nil
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vector store: %w", err)
+	}
+	defer file.Close()
+
+	if err := s.Restore(file); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Path returns the file the store is saved to
+func (s *FileVectorStore) Path() string {
+	return s.path
+}
+
+// Len returns the number of documents in the store
+func (s *FileVectorStore) Len() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.records)
+}
+
+// Get returns the document stored with id
+func (s *FileVectorStore) Get(id string) (Document, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	record, ok := s.records[id]
+	if !ok {
+		return Document{}, false
+	}
+	return record.document, true
+}
+
+// AddDocuments adds documents with their embeddings to the store. A document with an
+// "id" metadata field replaces the document stored with that ID, the others get a
+// generated ID.
+func (s *FileVectorStore) AddDocuments(ctx context.Context, documents []Document, embeddings [][]float64) error {
+	ids := make([]string, len(documents))
+	for i, doc := range documents {
+		ids[i], _ = doc.Metadata["id"].(string)
+	}
+	return s.AddDocumentsWithIDs(ctx, ids, documents, embeddings)
+}
+
+// AddDocumentsWithIDs adds documents with their embeddings to the store, replacing
+// the documents stored with the same IDs. Empty IDs are generated. The ID is kept in
+// the "id" metadata field of the stored documents.
+func (s *FileVectorStore) AddDocumentsWithIDs(ctx context.Context, ids []string, documents []Document, embeddings [][]float64) error {
+	if len(documents) != len(embeddings) {
+		return fmt.Errorf("number of documents (%d) must match number of embeddings (%d)", len(documents), len(embeddings))
+	}
+	if len(ids) != len(documents) {
+		return fmt.Errorf("number of ids (%d) must match number of documents (%d)", len(ids), len(documents))
+	}
+	if len(documents) == 0 {
+		return nil
+	}
+
+	records := make([]*vectorRecord, len(documents))
+	for i, doc := range documents {
+		if len(embeddings[i]) == 0 {
+			return fmt.Errorf("document %d has an empty embedding", i)
+		}
+
+		id := ids[i]
+		if id == "" {
+			id = uuid.NewString()
+		}
+		metadata := make(map[string]interface{}, len(doc.Metadata)+1)
+		for k, v := range doc.Metadata {
+			metadata[k] = v
+		}
+		metadata["id"] = id
+
+		record := newVectorRecord(append([]float64(nil), embeddings[i]...))
+		record.id = id
+		record.document = Document{PageContent: doc.PageContent, Metadata: metadata}
+		records[i] = record
+	}
+
+	s.mutex.Lock()
+	dimension := s.dimension
+	if len(s.records) == 0 {
+		dimension = len(records[0].embedding)
+	}
+	for i, record := range records {
+		if len(record.embedding) != dimension {
+			s.mutex.Unlock()
+			return fmt.Errorf("document %d has an embedding of dimension %d, the store has dimension %d", i, len(record.embedding), dimension)
+		}
+	}
+
+	s.dimension = dimension
+	for _, record := range records {
+		s.removeLocked(record.id)
+		record.seq = s.nextSeq
+		s.nextSeq++
+		s.records[record.id] = record
+		if s.index != nil {
+			s.index.insert(record)
+		}
+	}
+	// Replaced documents leave deleted nodes behind
+	if s.index != nil && s.index.stale() {
+		s.rebuildIndexLocked()
+	}
+	s.mutex.Unlock()
+
+	return s.saveIfAuto()
+}
+
+// Delete removes the documents stored with ids. Unknown IDs are ignored.
+func (s *FileVectorStore) Delete(ctx context.Context, ids ...string) error {
+	s.mutex.Lock()
+	for _, id := range ids {
+		s.removeLocked(id)
+	}
+	if len(s.records) == 0 {
+		s.dimension = 0
+	}
+	if s.index != nil && s.index.stale() {
+		s.rebuildIndexLocked()
+	}
+	s.mutex.Unlock()
+
+	return s.saveIfAuto()
+}
+
+func (s *FileVectorStore) removeLocked(id string) {
+	record, ok := s.records[id]
+	if !ok {
+		return
+	}
+	delete(s.records, id)
+	if s.index != nil {
+		s.index.remove(record)
+	}
+}
+
+// rebuildIndexLocked rebuilds the HNSW index from the stored documents, dropping the
+// deleted ones
+func (s *FileVectorStore) rebuildIndexLocked() {
+	s.index = newHNSWIndex(*s.hnsw)
+	for _, record := range s.sortedRecordsLocked() {
+		s.index.insert(record)
+	}
+}
+
+// sortedRecordsLocked returns the records in insertion order
+func (s *FileVectorStore) sortedRecordsLocked() []*vectorRecord {
+	records := make([]*vectorRecord, 0, len(s.records))
+	for _, record := range s.records {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].seq < records[j].seq
+	})
+	return records
+}
+
+// SimilaritySearch performs similarity search and returns top k documents
+func (s *FileVectorStore) SimilaritySearch(ctx context.Context, query string, k int) ([]Document, error) {
+	results, err := s.SimilaritySearchWithScore(ctx, query, k)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]Document, len(results))
+	for i, r := range results {
+		docs[i] = r.Document
+	}
+
+	return docs, nil
+}
+
+// SimilaritySearchWithScore performs similarity search and returns documents with scores
+func (s *FileVectorStore) SimilaritySearchWithScore(ctx context.Context, query string, k int) ([]DocumentWithScore, error) {
+	return s.SimilaritySearchWithFilter(ctx, query, k, nil)
+}
+
+// SimilaritySearchWithFilter returns the top k documents whose metadata match filter
+func (s *FileVectorStore) SimilaritySearchWithFilter(ctx context.Context, query string, k int, filter MetadataFilter) ([]DocumentWithScore, error) {
+	if s.embedder == nil {
+		return nil, fmt.Errorf("vector store has no embedder to embed the query")
+	}
+
+	queryEmbedding, err := s.embedder.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	return s.SimilaritySearchByVector(ctx, queryEmbedding, k, filter)
+}
+
+// SimilaritySearchByVector returns the top k documents closest to embedding whose
+// metadata match filter, which may be nil
+func (s *FileVectorStore) SimilaritySearchByVector(ctx context.Context, embedding []float64, k int, filter MetadataFilter) ([]DocumentWithScore, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if k <= 0 || len(s.records) == 0 {
+		return []DocumentWithScore{}, nil
+	}
+	if len(embedding) != s.dimension {
+		return nil, fmt.Errorf("query embedding has dimension %d, the store has dimension %d", len(embedding), s.dimension)
+	}
+
+	query := newVectorRecord(embedding)
+	var accept func(*vectorRecord) bool
+	if len(filter) > 0 {
+		accept = func(record *vectorRecord) bool {
+			return filter.Match(record.document.Metadata)
+		}
+	}
+
+	if s.index != nil {
+		items := s.index.search(query, k, accept)
+		// The walk can miss matching documents when the filter is selective
+		if len(items) == k || len(items) == len(s.records) {
+			results := make([]DocumentWithScore, len(items))
+			for i, item := range items {
+				results[i] = DocumentWithScore{Document: s.index.nodes[item.index].record.document, Score: item.score}
+			}
+			return results, nil
+		}
+	}
+
+	top := &minScoreHeap{}
+	for _, record := range s.records {
+		if accept != nil && !accept(record) {
+			continue
+		}
+		top.pushTopK(scoredItem{index: record.seq, score: query.similarity(record), record: record}, k)
+	}
+
+	items := top.sorted()
+	results := make([]DocumentWithScore, len(items))
+	for i, item := range items {
+		results[i] = DocumentWithScore{Document: item.record.document, Score: item.score}
+	}
+	return results, nil
+}
+
+// vectorFileHeader is the first line of a snapshot
+type vectorFileHeader struct {
+	Version   int `json:"version"`
+	Dimension int `json:"dimension"`
+	Count     int `json:"count"`
+}
+
+// vectorFileRecord is a document line of a snapshot. The embedding is stored as
+// little-endian float64 values, which JSON encodes in base64.
+type vectorFileRecord struct {
+	ID          string                 `json:"id"`
+	PageContent string                 `json:"page_content"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Embedding   []byte                 `json:"embedding"`
+}
+
+// Snapshot writes the documents of the store to w as JSON lines: a header followed
+// by one line per document, in insertion order
+func (s *FileVectorStore) Snapshot(w io.Writer) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	if err := encoder.Encode(vectorFileHeader{Version: vectorFileVersion, Dimension: s.dimension, Count: len(s.records)}); err != nil {
+		return fmt.Errorf("failed to write vector store header: %w", err)
+	}
+
+	for _, record := range s.sortedRecordsLocked() {
+		line := vectorFileRecord{
+			ID:          record.id,
+			PageContent: record.document.PageContent,
+			Metadata:    record.document.Metadata,
+			Embedding:   encodeEmbedding(record.embedding),
+		}
+		if err := encoder.Encode(line); err != nil {
+			return fmt.Errorf("failed to write document %s: %w", record.id, err)
+		}
+	}
+
+	return buffered.Flush()
+}
+
+// Restore replaces the documents of the store with a snapshot written by Snapshot
+func (s *FileVectorStore) Restore(r io.Reader) error {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+
+	var header vectorFileHeader
+	if err := decoder.Decode(&header); err != nil {
+		return fmt.Errorf("failed to read vector store header: %w", err)
+	}
+	if header.Version != vectorFileVersion {
+		return fmt.Errorf("unsupported vector store version %d", header.Version)
+	}
+
+	records := make(map[string]*vectorRecord, header.Count)
+	seq := 0
+	for {
+		var line vectorFileRecord
+		err := decoder.Decode(&line)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read document: %w", err)
+		}
+
+		embedding, err := decodeEmbedding(line.Embedding)
+		if err != nil {
+			return fmt.Errorf("failed to read document %s: %w", line.ID, err)
+		}
+		if len(embedding) != header.Dimension {
+			return fmt.Errorf("document %s has an embedding of dimension %d, the store has dimension %d", line.ID, len(embedding), header.Dimension)
+		}
+
+		record := newVectorRecord(embedding)
+		record.id = line.ID
+		record.seq = seq
+		record.document = Document{PageContent: line.PageContent, Metadata: line.Metadata}
+		records[line.ID] = record
+		seq++
+	}
+	if len(records) != header.Count {
+		return fmt.Errorf("vector store holds %d documents, its header announces %d", len(records), header.Count)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.records = records
+	s.nextSeq = seq
+	s.dimension = header.Dimension
+	if s.hnsw != nil {
+		s.rebuildIndexLocked()
+	}
+	return nil
+}
+
+// Save writes a snapshot of the store to its file. The snapshot is written to a
+// temporary file renamed into place, so the file is never left half-written.
+func (s *FileVectorStore) Save() error {
+	if s.path == "" {
+		return fmt.Errorf("vector store has no file to save to")
+	}
+
+	s.saveMutex.Lock()
+	defer s.saveMutex.Unlock()
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create vector store directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to save vector store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := s.Snapshot(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save vector store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save vector store: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to save vector store: %w", err)
+	}
+	return nil
+}
+
+func (s *FileVectorStore) saveIfAuto() error {
+	if !s.autoSave || s.path == "" {
+		return nil
+	}
+	return s.Save()
+}
+
+func encodeEmbedding(embedding []float64) []byte {
+	data := make([]byte, 8*len(embedding))
+	for i, v := range embedding {
+		binary.LittleEndian.PutUint64(data[8*i:], math.Float64bits(v))
+	}
+	return data
+}
+
+func decodeEmbedding(data []byte) ([]float64, error) {
+	if len(data)%8 != 0 {
+		return nil, fmt.Errorf("embedding of %d bytes is not a list of float64", len(data))
+	}
+	embedding := make([]float64, len(data)/8)
+	for i := range embedding {
+		embedding[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[8*i:]))
+	}
+	return embedding, nil
+}
+
+// scoredItem is a search candidate. Index identifies it and breaks ties, the lower
+// index first.
+type scoredItem struct {
+	index  int
+	score  float64
+	record *vectorRecord
+}
+
+// minScoreHeap keeps the best k items, with the worst one on top
+type minScoreHeap []scoredItem
+
+func (h minScoreHeap) Len() int { return len(h) }
+
+func (h minScoreHeap) Less(i, j int) bool {
+	if h[i].score == h[j].score {
+		return h[i].index > h[j].index
+	}
+	return h[i].score < h[j].score
+}
+
+func (h minScoreHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *minScoreHeap) Push(x any) { *h = append(*h, x.(scoredItem)) }
+
+func (h *minScoreHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// pushTopK adds item if it is among the best k items
+func (h *minScoreHeap) pushTopK(item scoredItem, k int) {
+	if k <= 0 {
+		return
+	}
+	if h.Len() < k {
+		heap.Push(h, item)
+		return
+	}
+	worst := (*h)[0]
+	if item.score > worst.score || (item.score == worst.score && item.index < worst.index) {
+		(*h)[0] = item
+		heap.Fix(h, 0)
+	}
+}
+
+// sorted empties the heap and returns its items, best first
+func (h *minScoreHeap) sorted() []scoredItem {
+	items := make([]scoredItem, h.Len())
+	for i := len(items) - 1; i >= 0; i-- {
+		items[i] = heap.Pop(h).(scoredItem)
+	}
+	return items
+}
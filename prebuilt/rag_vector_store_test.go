@@ -0,0 +1,325 @@
+package prebuilt
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// randomEmbeddings returns n random embeddings of dimension dim
+func randomEmbeddings(rng *rand.Rand, n, dim int) [][]float64 {
+	embeddings := make([][]float64, n)
+	for i := range embeddings {
+		embeddings[i] = make([]float64, dim)
+		for j := range embeddings[i] {
+			embeddings[i][j] = rng.NormFloat64()
+		}
+	}
+	return embeddings
+}
+
+// numberedDocuments returns n documents with an id, a year and a lang
+func numberedDocuments(n int) []Document {
+	docs := make([]Document, n)
+	langs := []string{"en", "de", "fr"}
+	for i := range docs {
+		docs[i] = Document{
+			PageContent: fmt.Sprintf("document %d", i),
+			Metadata: map[string]interface{}{
+				"id":   fmt.Sprintf("doc-%d", i),
+				"year": 2015 + i%10,
+				"lang": langs[i%len(langs)],
+			},
+		}
+	}
+	return docs
+}
+
+func TestMetadataFilter(t *testing.T) {
+	metadata := map[string]interface{}{"source": "handbook.pdf", "year": 2021, "lang": "en"}
+
+	tests := []struct {
+		name   string
+		filter MetadataFilter
+		match  bool
+	}{
+		{"equality", MetadataFilter{"source": "handbook.pdf"}, true},
+		{"equality mismatch", MetadataFilter{"source": "faq.md"}, false},
+		{"number types", MetadataFilter{"year": 2021.0}, true},
+		{"range", MetadataFilter{"year": map[string]interface{}{"$gte": 2020, "$lt": 2022}}, true},
+		{"range mismatch", MetadataFilter{"year": map[string]interface{}{"$gt": 2021}}, false},
+		{"string range", MetadataFilter{"lang": map[string]interface{}{"$lt": "fr"}}, true},
+		{"in", MetadataFilter{"lang": map[string]interface{}{"$in": []string{"de", "en"}}}, true},
+		{"nin", MetadataFilter{"lang": map[string]interface{}{"$nin": []string{"de", "en"}}}, false},
+		{"ne", MetadataFilter{"lang": map[string]interface{}{"$ne": "de"}}, true},
+		{"missing field", MetadataFilter{"author": "alice"}, false},
+		{"missing field ne", MetadataFilter{"author": map[string]interface{}{"$ne": "alice"}}, true},
+		{"all fields", MetadataFilter{"source": "handbook.pdf", "lang": "de"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(); err != nil {
+				t.Fatalf("Unexpected validation error: %v", err)
+			}
+			if got := tt.filter.Match(metadata); got != tt.match {
+				t.Errorf("Expected match %v, got %v", tt.match, got)
+			}
+		})
+	}
+
+	invalid := []MetadataFilter{
+		{"year": map[string]interface{}{"$between": 2020}},
+		{"year": map[string]interface{}{"$gt": true}},
+		{"lang": map[string]interface{}{"$in": "en"}},
+	}
+	for _, filter := range invalid {
+		if err := filter.Validate(); err == nil {
+			t.Errorf("Expected validation error for %v", filter)
+		}
+	}
+}
+
+func TestFileVectorStore(t *testing.T) {
+	ctx := context.Background()
+	embedder := NewMockEmbedder(32)
+	store, err := NewFileVectorStore("", embedder)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	docs := []Document{
+		{PageContent: "LangGraph builds stateful agents", Metadata: map[string]interface{}{"id": "a", "year": 2023}},
+		{PageContent: "RAG combines retrieval with generation", Metadata: map[string]interface{}{"year": 2024}},
+		{PageContent: "Vector stores index embeddings", Metadata: map[string]interface{}{"id": "c", "year": 2022}},
+	}
+	texts := []string{docs[0].PageContent, docs[1].PageContent, docs[2].PageContent}
+	embeddings, _ := embedder.EmbedDocuments(ctx, texts)
+
+	if err := store.AddDocuments(ctx, docs, embeddings); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+	if store.Len() != 3 {
+		t.Fatalf("Expected 3 documents, got %d", store.Len())
+	}
+
+	// The best match of a document's own text is the document
+	results, err := store.SimilaritySearchWithScore(ctx, texts[2], 3)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(results) != 3 || results[0].Document.Metadata["id"] != "c" {
+		t.Fatalf("Expected c first, got %v", results)
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Score > results[i-1].Score {
+			t.Errorf("Expected results sorted by score, got %v", results)
+		}
+	}
+
+	// Generated IDs are kept in the metadata
+	for _, result := range results {
+		id, _ := result.Document.Metadata["id"].(string)
+		if _, ok := store.Get(id); !ok {
+			t.Errorf("Expected a stored ID, got %v", result.Document.Metadata)
+		}
+	}
+
+	filtered, err := store.SimilaritySearchWithFilter(ctx, texts[2], 3, MetadataFilter{
+		"year": map[string]interface{}{"$gte": 2023},
+	})
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(filtered) != 2 {
+		t.Fatalf("Expected 2 documents from 2023, got %v", filtered)
+	}
+	for _, result := range filtered {
+		if result.Document.Metadata["id"] == "c" {
+			t.Errorf("Expected c to be filtered out")
+		}
+	}
+
+	if _, err := store.SimilaritySearchWithFilter(ctx, texts[0], 1, MetadataFilter{"year": map[string]interface{}{"$near": 1}}); err == nil {
+		t.Error("Expected an error for an unknown operator")
+	}
+
+	// Replace a and delete c
+	updated := Document{PageContent: "LangGraph builds durable agents", Metadata: map[string]interface{}{"year": 2025}}
+	if err := store.AddDocumentsWithIDs(ctx, []string{"a"}, []Document{updated}, embeddings[:1]); err != nil {
+		t.Fatalf("Failed to update document: %v", err)
+	}
+	if err := store.Delete(ctx, "c", "unknown"); err != nil {
+		t.Fatalf("Failed to delete document: %v", err)
+	}
+	if store.Len() != 2 {
+		t.Errorf("Expected 2 documents, got %d", store.Len())
+	}
+	if doc, ok := store.Get("a"); !ok || doc.PageContent != updated.PageContent {
+		t.Errorf("Expected the updated document, got %v", doc)
+	}
+	if _, ok := store.Get("c"); ok {
+		t.Error("Expected c to be deleted")
+	}
+
+	if err := store.AddDocuments(ctx, docs[:1], [][]float64{{1, 2}}); err == nil {
+		t.Error("Expected an error for an embedding of another dimension")
+	}
+}
+
+func TestFileVectorStore_SaveAndLoad(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vectors", "store.jsonl")
+	rng := rand.New(rand.NewSource(1))
+	docs := numberedDocuments(20)
+	embeddings := randomEmbeddings(rng, len(docs), 8)
+
+	store, err := NewFileVectorStore(path, nil, WithAutoSave(true))
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	if err := store.AddDocuments(ctx, docs, embeddings); err != nil {
+		t.Fatalf("Failed to add documents: %v", err)
+	}
+	if err := store.Delete(ctx, "doc-3"); err != nil {
+		t.Fatalf("Failed to delete document: %v", err)
+	}
+
+	loaded, err := NewFileVectorStore(path, nil, WithHNSWIndex(HNSWConfig{}))
+	if err != nil {
+		t.Fatalf("Failed to load store: %v", err)
+	}
+	if loaded.Len() != 19 {
+		t.Fatalf("Expected 19 documents, got %d", loaded.Len())
+	}
+
+	filter := MetadataFilter{"year": 2019, "lang": map[string]interface{}{"$in": []string{"de", "en"}}}
+	want, err := store.SimilaritySearchByVector(ctx, embeddings[4], 5, filter)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	got, err := loaded.SimilaritySearchByVector(ctx, embeddings[4], 5, filter)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(got) != len(want) || len(got) == 0 {
+		t.Fatalf("Expected %d results, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].Document.Metadata["id"] != want[i].Document.Metadata["id"] || got[i].Score != want[i].Score {
+			t.Errorf("Result %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+
+	// A corrupt snapshot is reported instead of being loaded as an empty store
+	if err := os.WriteFile(path, []byte(`{"version":1,"dimension":8,"count":2}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileVectorStore(path, nil); err == nil {
+		t.Error("Expected an error for a truncated snapshot")
+	}
+}
+
+func TestFileVectorStore_HNSWRecall(t *testing.T) {
+	ctx := context.Background()
+	rng := rand.New(rand.NewSource(7))
+	docs := numberedDocuments(2000)
+	embeddings := randomEmbeddings(rng, len(docs), 16)
+
+	exact, _ := NewFileVectorStore("", nil)
+	approximate, _ := NewFileVectorStore("", nil, WithHNSWIndex(HNSWConfig{Seed: 7}))
+	for _, store := range []*FileVectorStore{exact, approximate} {
+		if err := store.AddDocuments(ctx, docs, embeddings); err != nil {
+			t.Fatalf("Failed to add documents: %v", err)
+		}
+	}
+
+	// Deleting most documents rebuilds the index
+	var deleted []string
+	for i := 0; i < 1200; i++ {
+		deleted = append(deleted, fmt.Sprintf("doc-%d", i))
+	}
+	for _, store := range []*FileVectorStore{exact, approximate} {
+		if err := store.Delete(ctx, deleted...); err != nil {
+			t.Fatalf("Failed to delete documents: %v", err)
+		}
+	}
+	if approximate.index.deleted != 0 || len(approximate.index.nodes) != 800 {
+		t.Errorf("Expected the index to be rebuilt, got %d nodes with %d deleted", len(approximate.index.nodes), approximate.index.deleted)
+	}
+
+	queries := randomEmbeddings(rng, 50, 16)
+	filters := []MetadataFilter{nil, {"lang": "de"}, {"year": map[string]interface{}{"$lte": 2015}}}
+	for _, filter := range filters {
+		found, total := 0, 0
+		for _, query := range queries {
+			want, _ := exact.SimilaritySearchByVector(ctx, query, 10, filter)
+			got, err := approximate.SimilaritySearchByVector(ctx, query, 10, filter)
+			if err != nil {
+				t.Fatalf("Failed to search: %v", err)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("Expected %d results, got %d", len(want), len(got))
+			}
+
+			ids := make(map[interface{}]bool)
+			for _, result := range got {
+				if !filter.Match(result.Document.Metadata) {
+					t.Fatalf("Result %v does not match %v", result.Document.Metadata, filter)
+				}
+				ids[result.Document.Metadata["id"]] = true
+			}
+			for _, result := range want {
+				if ids[result.Document.Metadata["id"]] {
+					found++
+				}
+			}
+			total += len(want)
+		}
+
+		if recall := float64(found) / float64(total); recall < 0.9 {
+			t.Errorf("Expected a recall of at least 0.9 with filter %v, got %.2f", filter, recall)
+		}
+	}
+}
+
+func TestFileVectorStore_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	store, _ := NewFileVectorStore("", nil, WithHNSWIndex(HNSWConfig{M: 8}))
+	rng := rand.New(rand.NewSource(3))
+	docs := numberedDocuments(400)
+	embeddings := randomEmbeddings(rng, len(docs), 8)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(docs); i += 4 {
+				if err := store.AddDocuments(ctx, docs[i:i+1], embeddings[i:i+1]); err != nil {
+					t.Errorf("Failed to add document: %v", err)
+				}
+				if i%8 == w {
+					_ = store.Delete(ctx, fmt.Sprintf("doc-%d", i))
+				}
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if _, err := store.SimilaritySearchByVector(ctx, embeddings[w], 5, MetadataFilter{"lang": "en"}); err != nil {
+					t.Errorf("Failed to search: %v", err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if store.Len() != 200 {
+		t.Errorf("Expected 200 documents, got %d", store.Len())
+	}
+}
@@ -2,7 +2,6 @@ package prebuilt
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/smallnest/langgraphgo/graph"
@@ -37,34 +36,14 @@ func CreateReactAgent(model llms.Model, inputTools []tools.Tool) (*graph.StateRu
 		}
 
 		// Convert tools to ToolInfo for the model
-		var toolDefs []llms.Tool
-		for _, t := range inputTools {
-			toolDefs = append(toolDefs, llms.Tool{
-				Type: "function",
-				Function: &llms.FunctionDefinition{
-					Name:        t.Name(),
-					Description: t.Description(),
-					Parameters: map[string]interface{}{
-						"type": "object",
-						"properties": map[string]interface{}{
-							"input": map[string]interface{}{
-								"type":        "string",
-								"description": "The input query for the tool",
-							},
-						},
-						"required":             []string{"input"},
-						"additionalProperties": false,
-					},
-				},
-			})
-		}
+		toolDefs := toolDefinitions(inputTools)
 
 		// We need to pass tools to the model
 		opts := []llms.CallOption{
 			llms.WithTools(toolDefs),
 		}
 
-		resp, err := model.GenerateContent(ctx, messages, opts...)
+		resp, err := graph.GenerateContent(ctx, model, messages, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -107,45 +86,16 @@ func CreateReactAgent(model llms.Model, inputTools []tools.Tool) (*graph.StateRu
 			return nil, fmt.Errorf("last message is not an AI message")
 		}
 
-		var toolMessages []llms.MessageContent
-
-		for _, part := range lastMsg.Parts {
-			if tc, ok := part.(llms.ToolCall); ok {
-				// Parse arguments to get input
-				var args map[string]interface{}
-				if err := json.Unmarshal([]byte(tc.FunctionCall.Arguments), &args); err != nil {
-					// If unmarshal fails, try to use the raw string if it's not JSON object
-				}
-
-				inputVal := ""
-				if val, ok := args["input"].(string); ok {
-					inputVal = val
-				} else {
-					inputVal = tc.FunctionCall.Arguments
-				}
-
-				// Execute tool
-				res, err := toolExecutor.Execute(ctx, ToolInvocation{
-					Tool:      tc.FunctionCall.Name,
-					ToolInput: inputVal,
-				})
-				if err != nil {
-					res = fmt.Sprintf("Error: %v", err)
-				}
-
-				// Create ToolMessage
-				toolMsg := llms.MessageContent{
-					Role: llms.ChatMessageTypeTool,
-					Parts: []llms.ContentPart{
-						llms.ToolCallResponse{
-							ToolCallID: tc.ID,
-							Name:       tc.FunctionCall.Name,
-							Content:    res,
-						},
-					},
-				}
-				toolMessages = append(toolMessages, toolMsg)
-			}
+		runner := toolCallRunner{
+			executor:       toolExecutor,
+			maxParallelism: 1,
+			errorFormat: func(name string, err error) string {
+				return fmt.Sprintf("Error: %v", err)
+			},
+		}
+		toolMessages, err := runner.run(ctx, lastMsg)
+		if err != nil {
+			return nil, err
 		}
 
 		return map[string]interface{}{
@@ -173,7 +123,7 @@ func CreateReactAgent(model llms.Model, inputTools []tools.Tool) (*graph.StateRu
 			return "tools"
 		}
 		return graph.END
-	})
+	}, "tools", graph.END)
 
 	workflow.AddEdge("tools", "agent")
 
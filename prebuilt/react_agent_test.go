@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/smallnest/langgraphgo/graph"
 	"github.com/stretchr/testify/assert"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/tools"
@@ -112,3 +113,92 @@ func TestCreateReactAgent(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, "Final Answer", textPart.Text)
 }
+
+// loopingLLM always asks for the same tool, simulating an agent that never stops
+type loopingLLM struct{}
+
+func (m *loopingLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	return &llms.ContentResponse{
+		Choices: []*llms.ContentChoice{
+			{
+				ToolCalls: []llms.ToolCall{
+					{
+						ID:   "call-loop",
+						Type: "function",
+						FunctionCall: &llms.FunctionCall{
+							Name:      "test-tool",
+							Arguments: `{"input": "again"}`,
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (m *loopingLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return "", nil
+}
+
+func TestCreateReactAgent_RecursionLimit(t *testing.T) {
+	agent, err := CreateReactAgent(&loopingLLM{}, []tools.Tool{&MockTool{name: "test-tool"}})
+	assert.NoError(t, err)
+
+	initialState := map[string]interface{}{
+		"messages": []llms.MessageContent{
+			llms.TextParts(llms.ChatMessageTypeHuman, "Run tool forever"),
+		},
+	}
+
+	_, err = agent.InvokeWithConfig(context.Background(), initialState, &graph.Config{RecursionLimit: 6})
+
+	var recursionErr *graph.GraphRecursionError
+	assert.ErrorAs(t, err, &recursionErr)
+	assert.Equal(t, 6, recursionErr.Limit)
+	assert.Equal(t, []string{"agent"}, recursionErr.NextNodes)
+
+	// human + 3 x (AI tool call + tool response)
+	mState := recursionErr.State.(map[string]interface{})
+	assert.Len(t, mState["messages"].([]llms.MessageContent), 7)
+}
+
+func TestCreateSupervisor_PropagatesRecursionLimit(t *testing.T) {
+	member, err := CreateAgent(&loopingLLM{}, []tools.Tool{&MockTool{name: "test-tool"}})
+	assert.NoError(t, err)
+
+	supervisorLLM := &MockLLM{
+		responses: []llms.ContentResponse{
+			{
+				Choices: []*llms.ContentChoice{
+					{
+						ToolCalls: []llms.ToolCall{
+							{
+								FunctionCall: &llms.FunctionCall{
+									Name:      "route",
+									Arguments: `{"next": "Looper"}`,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	supervisor, err := CreateSupervisor(supervisorLLM, map[string]*graph.StateRunnable{"Looper": member})
+	assert.NoError(t, err)
+
+	initialState := map[string]interface{}{
+		"messages": []llms.MessageContent{
+			llms.TextParts(llms.ChatMessageTypeHuman, "Loop"),
+		},
+	}
+
+	_, err = supervisor.InvokeWithConfig(context.Background(), initialState, &graph.Config{RecursionLimit: 4})
+
+	var recursionErr *graph.GraphRecursionError
+	assert.ErrorAs(t, err, &recursionErr)
+	assert.Equal(t, 4, recursionErr.Limit)
+	// The error comes from the member agent, not the supervisor graph
+	assert.Equal(t, []string{"agent"}, recursionErr.NextNodes)
+}
@@ -71,7 +71,7 @@ func CreateSupervisor(model llms.Model, members map[string]*graph.StateRunnable)
 		inputMessages = append(inputMessages, messages...)
 
 		// Call model
-		resp, err := model.GenerateContent(ctx, inputMessages,
+		resp, err := graph.GenerateContent(ctx, model, inputMessages,
 			llms.WithTools([]llms.Tool{routeTool}),
 			llms.WithToolChoice("auto"), // Let model decide, but prompt strongly encourages it
 		)
@@ -114,8 +114,8 @@ func CreateSupervisor(model llms.Model, members map[string]*graph.StateRunnable)
 
 		workflow.AddNode(agentName, "Agent: "+agentName, func(ctx context.Context, state interface{}) (interface{}, error) {
 			// Invoke agent
-			// We pass the full state
-			res, err := agentRunnable.Invoke(ctx, state)
+			// We pass the full state and propagate the recursion limit of the parent run
+			res, err := agentRunnable.InvokeWithConfig(ctx, state, memberConfig(ctx))
 			if err != nil {
 				return nil, err
 			}
@@ -149,3 +149,16 @@ func CreateSupervisor(model llms.Model, members map[string]*graph.StateRunnable)
 
 	return workflow.Compile()
 }
+
+// memberConfig builds the config used to invoke a member agent.
+// The recursion limit of the supervisor run is propagated so that a member
+// stuck in a tool loop is bounded the same way as the supervisor itself.
+func memberConfig(ctx context.Context) *graph.Config {
+	config := graph.GetConfig(ctx)
+	if config == nil || config.RecursionLimit <= 0 {
+		return nil
+	}
+	return &graph.Config{
+		RecursionLimit: config.RecursionLimit,
+	}
+}
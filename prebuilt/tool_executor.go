@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/smallnest/langgraphgo/graph"
 	"github.com/tmc/langchaingo/tools"
 )
 
@@ -36,7 +37,7 @@ func (te *ToolExecutor) Execute(ctx context.Context, invocation ToolInvocation)
 		return "", fmt.Errorf("tool not found: %s", invocation.Tool)
 	}
 
-	return tool.Call(ctx, invocation.ToolInput)
+	return graph.CallTool(ctx, invocation.Tool, invocation.ToolInput, tool.Call)
 }
 
 // ExecuteMany executes multiple tool invocations in parallel (if needed, but here sequential for simplicity)
@@ -2,8 +2,10 @@ package prebuilt
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/tools"
@@ -11,18 +13,54 @@ import (
 
 // ToolNode is a reusable node that executes tool calls from the last AI message.
 // It expects the state to be a map[string]interface{} with a "messages" key containing []llms.MessageContent.
+//
+// The tool calls of a message run concurrently, and the resulting tool messages are
+// returned in the order of the calls.
 type ToolNode struct {
 	Executor *ToolExecutor
+
+	// MaxParallelism bounds the number of tool calls running at the same time.
+	// Zero or a negative value means no limit; 1 runs the calls sequentially.
+	MaxParallelism int
+
+	// ToolTimeout bounds the duration of each tool call. A call that times out is
+	// reported to the model as an error tool message. Zero means no timeout.
+	ToolTimeout time.Duration
+}
+
+// ToolNodeOption is a function that configures a ToolNode
+type ToolNodeOption func(*ToolNode)
+
+// WithMaxParallelism sets the maximum number of tool calls executed concurrently
+func WithMaxParallelism(n int) ToolNodeOption {
+	return func(tn *ToolNode) {
+		tn.MaxParallelism = n
+	}
+}
+
+// WithToolTimeout sets the timeout of each tool call
+func WithToolTimeout(timeout time.Duration) ToolNodeOption {
+	return func(tn *ToolNode) {
+		tn.ToolTimeout = timeout
+	}
 }
 
 // NewToolNode creates a new ToolNode with the given tools.
-func NewToolNode(inputTools []tools.Tool) *ToolNode {
-	return &ToolNode{
+func NewToolNode(inputTools []tools.Tool, opts ...ToolNodeOption) *ToolNode {
+	tn := &ToolNode{
 		Executor: NewToolExecutor(inputTools),
 	}
+	for _, opt := range opts {
+		opt(tn)
+	}
+	return tn
 }
 
 // Invoke executes the tool calls found in the last message.
+// Tools implementing ToolWithSchema receive the full arguments object of the call;
+// invalid arguments are reported back to the model as the tool message content.
+// When ctx is cancelled, running calls are cancelled, pending calls are skipped
+// and the context error is returned.
 func (tn *ToolNode) Invoke(ctx context.Context, state interface{}) (interface{}, error) {
 	mState, ok := state.(map[string]interface{})
 	if !ok {
@@ -49,51 +87,17 @@ func (tn *ToolNode) Invoke(ctx context.Context, state interface{}) (interface{},
 		return nil, fmt.Errorf("last message is not an AI message")
 	}
 
-	var toolMessages []llms.MessageContent
-
-	for _, part := range lastMsg.Parts {
-		if tc, ok := part.(llms.ToolCall); ok {
-			// Parse arguments to get input
-			var args map[string]interface{}
-			// Arguments is a JSON string
-			if err := json.Unmarshal([]byte(tc.FunctionCall.Arguments), &args); err != nil {
-				// If unmarshal fails, it might be a simple string or malformed.
-				// We'll try to use it as is if it's not a JSON object, but usually it is.
-				// For now, let's log/ignore or treat as empty?
-				// Let's assume it might be a direct string input if not JSON.
-			}
-
-			inputVal := ""
-			if val, ok := args["input"].(string); ok {
-				inputVal = val
-			} else {
-				// Fallback: pass the whole arguments string if "input" key is missing
-				// This depends on how the tool expects input.
-				inputVal = tc.FunctionCall.Arguments
-			}
-
-			// Execute tool
-			res, err := tn.Executor.Execute(ctx, ToolInvocation{
-				Tool:      tc.FunctionCall.Name,
-				ToolInput: inputVal,
-			})
-			if err != nil {
-				res = fmt.Sprintf("Error executing tool %s: %v", tc.FunctionCall.Name, err)
-			}
-
-			// Create ToolMessage
-			toolMsg := llms.MessageContent{
-				Role: llms.ChatMessageTypeTool,
-				Parts: []llms.ContentPart{
-					llms.ToolCallResponse{
-						ToolCallID: tc.ID,
-						Name:       tc.FunctionCall.Name,
-						Content:    res,
-					},
-				},
-			}
-			toolMessages = append(toolMessages, toolMsg)
-		}
+	runner := toolCallRunner{
+		executor:       tn.Executor,
+		maxParallelism: tn.MaxParallelism,
+		timeout:        tn.ToolTimeout,
+		errorFormat: func(name string, err error) string {
+			return fmt.Sprintf("Error executing tool %s: %v", name, err)
+		},
+	}
+	toolMessages, err := runner.run(ctx, lastMsg)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(toolMessages) == 0 {
@@ -105,3 +109,119 @@ func (tn *ToolNode) Invoke(ctx context.Context, state interface{}) (interface{},
 		"messages": toolMessages,
 	}, nil
 }
+
+// toolCallRunner executes the tool calls of an AI message and returns one tool
+// message per call. Execution and validation errors are reported to the model
+// as the content of the tool message, formatted with errorFormat.
+type toolCallRunner struct {
+	executor       *ToolExecutor
+	maxParallelism int
+	timeout        time.Duration
+	errorFormat    func(name string, err error) string
+}
+
+func (r toolCallRunner) run(ctx context.Context, msg llms.MessageContent) ([]llms.MessageContent, error) {
+	var calls []llms.ToolCall
+	for _, part := range msg.Parts {
+		if tc, ok := part.(llms.ToolCall); ok {
+			calls = append(calls, tc)
+		}
+	}
+	if len(calls) == 0 {
+		return nil, nil
+	}
+
+	limit := r.maxParallelism
+	if limit <= 0 || limit > len(calls) {
+		limit = len(calls)
+	}
+
+	// Results are indexed by call position so the order does not depend on timing
+	toolMessages := make([]llms.MessageContent, len(calls))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i, tc := range calls {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, tc llms.ToolCall) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			toolMessages[i] = r.execute(ctx, tc)
+		}(i, tc)
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return toolMessages, nil
+}
+
+// execute runs a single tool call, enforcing the per-call timeout even when the
+// tool ignores its context.
+func (r toolCallRunner) execute(ctx context.Context, tc llms.ToolCall) llms.MessageContent {
+	name := ""
+	if tc.FunctionCall != nil {
+		name = tc.FunctionCall.Name
+	}
+
+	callCtx := ctx
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	type result struct {
+		content string
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		// A panicking tool fails its call instead of the process
+		defer func() {
+			if p := recover(); p != nil {
+				done <- result{err: fmt.Errorf("panic in tool %s: %v", name, p)}
+			}
+		}()
+		content, err := r.executor.ExecuteToolCall(callCtx, tc)
+		done <- result{content, err}
+	}()
+
+	var res result
+	select {
+	case res = <-done:
+	case <-callCtx.Done():
+		res.err = callCtx.Err()
+	}
+
+	if r.timeout > 0 && errors.Is(res.err, context.DeadlineExceeded) && ctx.Err() == nil {
+		res.err = fmt.Errorf("tool call timed out after %s", r.timeout)
+	}
+
+	content := res.content
+	if res.err != nil {
+		content = r.errorFormat(name, res.err)
+	}
+
+	return llms.MessageContent{
+		Role: llms.ChatMessageTypeTool,
+		Parts: []llms.ContentPart{
+			llms.ToolCallResponse{
+				ToolCallID: tc.ID,
+				Name:       name,
+				Content:    content,
+			},
+		},
+	}
+}
@@ -2,9 +2,13 @@ package prebuilt
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/tools"
 )
@@ -56,3 +60,165 @@ func TestToolNode(t *testing.T) {
 	assert.Equal(t, "test-tool", toolResp.Name)
 	assert.Equal(t, "Executed test-tool with test-input", toolResp.Content)
 }
+
+// SlowTool sleeps for the duration given as input and tracks concurrent calls
+type SlowTool struct {
+	name          string
+	ignoreContext bool
+	running       atomic.Int32
+	maxRunning    atomic.Int32
+}
+
+func (t *SlowTool) Name() string {
+	return t.name
+}
+
+func (t *SlowTool) Description() string {
+	return "A slow tool"
+}
+
+func (t *SlowTool) Call(ctx context.Context, input string) (string, error) {
+	n := t.running.Add(1)
+	defer t.running.Add(-1)
+	for {
+		m := t.maxRunning.Load()
+		if n <= m || t.maxRunning.CompareAndSwap(m, n) {
+			break
+		}
+	}
+
+	d, err := time.ParseDuration(input)
+	if err != nil {
+		return "", err
+	}
+
+	if t.ignoreContext {
+		time.Sleep(d)
+		return "slept " + input, nil
+	}
+
+	select {
+	case <-time.After(d):
+		return "slept " + input, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func slowToolCallState(durations ...string) map[string]interface{} {
+	aiMsg := llms.MessageContent{Role: llms.ChatMessageTypeAI}
+	for i, d := range durations {
+		aiMsg.Parts = append(aiMsg.Parts, llms.ToolCall{
+			ID:   fmt.Sprintf("call_%d", i),
+			Type: "function",
+			FunctionCall: &llms.FunctionCall{
+				Name:      "slow",
+				Arguments: fmt.Sprintf(`{"input": %q}`, d),
+			},
+		})
+	}
+	return map[string]interface{}{
+		"messages": []llms.MessageContent{aiMsg},
+	}
+}
+
+func TestToolNode_ParallelExecution(t *testing.T) {
+	slow := &SlowTool{name: "slow"}
+	toolNode := NewToolNode([]tools.Tool{slow})
+
+	start := time.Now()
+	res, err := toolNode.Invoke(context.Background(), slowToolCallState("150ms", "100ms", "50ms", "10ms"))
+	require.NoError(t, err)
+
+	// Calls overlap instead of running back to back
+	assert.Less(t, time.Since(start), 300*time.Millisecond)
+	assert.Equal(t, int32(4), slow.maxRunning.Load())
+
+	// Responses keep the order of the tool calls, not the completion order
+	messages := res.(map[string]interface{})["messages"].([]llms.MessageContent)
+	require.Len(t, messages, 4)
+	for i, d := range []string{"150ms", "100ms", "50ms", "10ms"} {
+		resp := messages[i].Parts[0].(llms.ToolCallResponse)
+		assert.Equal(t, fmt.Sprintf("call_%d", i), resp.ToolCallID)
+		assert.Equal(t, "slept "+d, resp.Content)
+	}
+}
+
+func TestToolNode_MaxParallelism(t *testing.T) {
+	slow := &SlowTool{name: "slow"}
+	toolNode := NewToolNode([]tools.Tool{slow}, WithMaxParallelism(2))
+
+	_, err := toolNode.Invoke(context.Background(), slowToolCallState("20ms", "20ms", "20ms", "20ms", "20ms"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), slow.maxRunning.Load())
+
+	slow = &SlowTool{name: "slow"}
+	toolNode = NewToolNode([]tools.Tool{slow}, WithMaxParallelism(1))
+	_, err = toolNode.Invoke(context.Background(), slowToolCallState("5ms", "5ms", "5ms"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), slow.maxRunning.Load())
+}
+
+func TestToolNode_ToolTimeout(t *testing.T) {
+	// The timeout is enforced even when the tool ignores its context
+	slow := &SlowTool{name: "slow", ignoreContext: true}
+	toolNode := NewToolNode([]tools.Tool{slow}, WithToolTimeout(50*time.Millisecond))
+
+	start := time.Now()
+	res, err := toolNode.Invoke(context.Background(), slowToolCallState("1s", "1ms"))
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	messages := res.(map[string]interface{})["messages"].([]llms.MessageContent)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "Error executing tool slow: tool call timed out after 50ms", messages[0].Parts[0].(llms.ToolCallResponse).Content)
+	assert.Equal(t, "slept 1ms", messages[1].Parts[0].(llms.ToolCallResponse).Content)
+}
+
+func TestToolNode_ContextCancellation(t *testing.T) {
+	slow := &SlowTool{name: "slow"}
+	toolNode := NewToolNode([]tools.Tool{slow}, WithMaxParallelism(2))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := toolNode.Invoke(ctx, slowToolCallState("1s", "1s", "1s", "1s"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	// Pending calls are never started
+	assert.Equal(t, int32(2), slow.maxRunning.Load())
+	assert.Eventually(t, func() bool { return slow.running.Load() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+// PanicTool panics when called
+type PanicTool struct{}
+
+func (t PanicTool) Name() string        { return "panic" }
+func (t PanicTool) Description() string { return "A panicking tool" }
+func (t PanicTool) Call(ctx context.Context, input string) (string, error) {
+	panic("kaboom")
+}
+
+func TestToolNode_ToolPanic(t *testing.T) {
+	toolNode := NewToolNode([]tools.Tool{PanicTool{}, &SlowTool{name: "slow"}})
+
+	state := slowToolCallState("1ms")
+	aiMsg := state["messages"].([]llms.MessageContent)[0]
+	aiMsg.Parts = append(aiMsg.Parts, llms.ToolCall{
+		ID:           "call_panic",
+		Type:         "function",
+		FunctionCall: &llms.FunctionCall{Name: "panic", Arguments: `{"input": "x"}`},
+	})
+	state["messages"] = []llms.MessageContent{aiMsg}
+
+	// The panic fails the call, not the node
+	res, err := toolNode.Invoke(context.Background(), state)
+	require.NoError(t, err)
+
+	messages := res.(map[string]interface{})["messages"].([]llms.MessageContent)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "slept 1ms", messages[0].Parts[0].(llms.ToolCallResponse).Content)
+	assert.Equal(t, "Error executing tool panic: panic in tool panic: kaboom", messages[1].Parts[0].(llms.ToolCallResponse).Content)
+}
@@ -0,0 +1,309 @@
+package prebuilt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// ToolWithSchema is implemented by tools that accept structured arguments.
+//
+// Schema returns the JSON Schema of the tool's arguments object. It is advertised
+// to the model instead of the default single "input" string, and the tool's Call
+// receives the full JSON arguments object produced by the model, after it has
+// been validated against the schema.
+type ToolWithSchema interface {
+	tools.Tool
+	Schema() map[string]interface{}
+}
+
+// defaultToolSchema is advertised for tools that do not implement ToolWithSchema.
+func defaultToolSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"input": map[string]interface{}{
+				"type":        "string",
+				"description": "The input query for the tool",
+			},
+		},
+		"required":             []string{"input"},
+		"additionalProperties": false,
+	}
+}
+
+// toolDefinitions converts tools to the function definitions passed to the model.
+func toolDefinitions(inputTools []tools.Tool) []llms.Tool {
+	var toolDefs []llms.Tool
+	for _, t := range inputTools {
+		parameters := defaultToolSchema()
+		if st, ok := t.(ToolWithSchema); ok {
+			if schema := st.Schema(); schema != nil {
+				parameters = schema
+			}
+		}
+
+		toolDefs = append(toolDefs, llms.Tool{
+			Type: "function",
+			Function: &llms.FunctionDefinition{
+				Name:        t.Name(),
+				Description: t.Description(),
+				Parameters:  parameters,
+			},
+		})
+	}
+	return toolDefs
+}
+
+// ExecuteToolCall executes a tool call produced by the model.
+// Tools implementing ToolWithSchema receive the whole arguments object once it
+// validates against their schema; other tools receive the "input" argument, or
+// the raw arguments when there is none.
+func (te *ToolExecutor) ExecuteToolCall(ctx context.Context, call llms.ToolCall) (string, error) {
+	if call.FunctionCall == nil {
+		return "", fmt.Errorf("tool call %s has no function call", call.ID)
+	}
+
+	name := call.FunctionCall.Name
+	tool, ok := te.tools[name]
+	if !ok {
+		return "", fmt.Errorf("tool not found: %s", name)
+	}
+
+	arguments := call.FunctionCall.Arguments
+
+	if st, ok := tool.(ToolWithSchema); ok {
+		if schema := st.Schema(); schema != nil {
+			if strings.TrimSpace(arguments) == "" {
+				arguments = "{}"
+			}
+			var args interface{}
+			if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+				return "", fmt.Errorf("invalid arguments for tool %s: %w", name, err)
+			}
+			if err := ValidateToolArguments(schema, args); err != nil {
+				return "", fmt.Errorf("invalid arguments for tool %s: %w", name, err)
+			}
+			return graph.CallTool(ctx, name, arguments, tool.Call)
+		}
+	}
+
+	var args map[string]interface{}
+	// Arguments that are not a JSON object are passed through as-is
+	_ = json.Unmarshal([]byte(arguments), &args)
+
+	input := arguments
+	if val, ok := args["input"].(string); ok {
+		input = val
+	}
+
+	return graph.CallTool(ctx, name, input, tool.Call)
+}
+
+// ValidateToolArguments validates decoded JSON arguments against a JSON Schema.
+//
+// It supports the subset of JSON Schema used for tool parameters: "type"
+// (a single type or a list), "properties", "required", "additionalProperties"
+// (boolean or schema), "items" and "enum". Other keywords are ignored.
+func ValidateToolArguments(schema map[string]interface{}, args interface{}) error {
+	return validateSchema(schema, args, "")
+}
+
+func validateSchema(schema map[string]interface{}, value interface{}, path string) error {
+	if len(schema) == 0 {
+		return nil
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if matchesType(t, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: expected %s, got %s", displayPath(path), strings.Join(types, " or "), jsonTypeName(value))
+		}
+	}
+
+	if enum, ok := schema["enum"]; ok {
+		if values := toInterfaceSlice(enum); values != nil && !containsValue(values, value) {
+			return fmt.Errorf("%s: value %v is not one of %v", displayPath(path), value, values)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+
+		for _, name := range toStringSlice(schema["required"]) {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", displayPath(path), name)
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if propSchema, ok := properties[key].(map[string]interface{}); ok {
+				if err := validateSchema(propSchema, v[key], joinPath(path, key)); err != nil {
+					return err
+				}
+				continue
+			}
+			if _, ok := properties[key]; ok {
+				continue
+			}
+
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					return fmt.Errorf("%s: unexpected property %q", displayPath(path), key)
+				}
+			case map[string]interface{}:
+				if err := validateSchema(additional, v[key], joinPath(path, key)); err != nil {
+					return err
+				}
+			}
+		}
+
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				if err := validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	default:
+		// Unknown types are not enforced
+		return true
+	}
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// schemaTypes returns the types allowed by a "type" keyword.
+func schemaTypes(v interface{}) []string {
+	if s, ok := v.(string); ok {
+		return []string{s}
+	}
+	return toStringSlice(v)
+}
+
+// toStringSlice accepts both []string (hand-written schemas) and []interface{}
+// (schemas decoded from JSON).
+func toStringSlice(v interface{}) []string {
+	switch s := v.(type) {
+	case []string:
+		return s
+	case []interface{}:
+		result := make([]string, 0, len(s))
+		for _, item := range s {
+			if str, ok := item.(string); ok {
+				result = append(result, str)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+func toInterfaceSlice(v interface{}) []interface{} {
+	switch s := v.(type) {
+	case []interface{}:
+		return s
+	case []string:
+		result := make([]interface{}, len(s))
+		for i, item := range s {
+			result[i] = item
+		}
+		return result
+	}
+	return nil
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	// Compare JSON encodings: hand-written enums may use Go numeric types,
+	// while decoded arguments are always float64
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	for _, v := range values {
+		if candidate, err := json.Marshal(v); err == nil && string(candidate) == string(encoded) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "arguments"
+	}
+	return path
+}
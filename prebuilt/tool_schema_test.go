@@ -0,0 +1,209 @@
+package prebuilt
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// MockSchemaTool implements ToolWithSchema for testing
+type MockSchemaTool struct {
+	name   string
+	inputs []string
+}
+
+func (t *MockSchemaTool) Name() string {
+	return t.name
+}
+
+func (t *MockSchemaTool) Description() string {
+	return "Adds two numbers"
+}
+
+func (t *MockSchemaTool) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"a":  map[string]interface{}{"type": "integer"},
+			"b":  map[string]interface{}{"type": "integer"},
+			"op": map[string]interface{}{"type": "string", "enum": []string{"add", "sub"}},
+		},
+		"required":             []string{"a", "b"},
+		"additionalProperties": false,
+	}
+}
+
+func (t *MockSchemaTool) Call(ctx context.Context, input string) (string, error) {
+	t.inputs = append(t.inputs, input)
+	return "ok", nil
+}
+
+func toolCallMessage(id, name, arguments string) llms.MessageContent {
+	return llms.MessageContent{
+		Role: llms.ChatMessageTypeAI,
+		Parts: []llms.ContentPart{
+			llms.ToolCall{
+				ID:           id,
+				Type:         "function",
+				FunctionCall: &llms.FunctionCall{Name: name, Arguments: arguments},
+			},
+		},
+	}
+}
+
+func TestToolDefinitions(t *testing.T) {
+	defs := toolDefinitions([]tools.Tool{&MockTool{name: "plain"}, &MockSchemaTool{name: "calc"}})
+	require.Len(t, defs, 2)
+
+	plain := defs[0].Function.Parameters.(map[string]interface{})
+	assert.Contains(t, plain["properties"], "input")
+
+	calc := defs[1].Function.Parameters.(map[string]interface{})
+	assert.Equal(t, []string{"a", "b"}, calc["required"])
+	assert.Equal(t, "calc", defs[1].Function.Name)
+}
+
+func TestToolNode_SchemaTool(t *testing.T) {
+	calc := &MockSchemaTool{name: "calc"}
+	toolNode := NewToolNode([]tools.Tool{calc, &MockTool{name: "plain"}})
+
+	msg := toolCallMessage("call_1", "calc", `{"a": 1, "b": 2, "op": "add"}`)
+	msg.Parts = append(msg.Parts, toolCallMessage("call_2", "plain", `{"input": "hello"}`).Parts...)
+
+	res, err := toolNode.Invoke(context.Background(), map[string]interface{}{
+		"messages": []llms.MessageContent{msg},
+	})
+	require.NoError(t, err)
+
+	messages := res.(map[string]interface{})["messages"].([]llms.MessageContent)
+	require.Len(t, messages, 2)
+
+	// Schema tools receive the whole arguments object
+	require.Len(t, calc.inputs, 1)
+	assert.JSONEq(t, `{"a": 1, "b": 2, "op": "add"}`, calc.inputs[0])
+	assert.Equal(t, "ok", messages[0].Parts[0].(llms.ToolCallResponse).Content)
+
+	// Plain tools still receive the "input" argument
+	assert.Equal(t, "Executed plain with hello", messages[1].Parts[0].(llms.ToolCallResponse).Content)
+}
+
+func TestToolNode_SchemaValidationErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments string
+		errorText string
+	}{
+		{"missing required", `{"a": 1}`, `missing required property "b"`},
+		{"wrong type", `{"a": 1, "b": "two"}`, "b: expected integer, got string"},
+		{"not an integer", `{"a": 1, "b": 2.5}`, "b: expected integer, got number"},
+		{"unexpected property", `{"a": 1, "b": 2, "c": 3}`, `unexpected property "c"`},
+		{"enum", `{"a": 1, "b": 2, "op": "mul"}`, "op: value mul is not one of"},
+		{"malformed", `{"a": 1,`, "invalid arguments for tool calc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc := &MockSchemaTool{name: "calc"}
+			toolNode := NewToolNode([]tools.Tool{calc})
+
+			res, err := toolNode.Invoke(context.Background(), map[string]interface{}{
+				"messages": []llms.MessageContent{toolCallMessage("call_1", "calc", tt.arguments)},
+			})
+			require.NoError(t, err)
+
+			// The tool is not called, the error is returned to the model instead
+			assert.Empty(t, calc.inputs)
+			messages := res.(map[string]interface{})["messages"].([]llms.MessageContent)
+			require.Len(t, messages, 1)
+			resp := messages[0].Parts[0].(llms.ToolCallResponse)
+			assert.Equal(t, "call_1", resp.ToolCallID)
+			assert.Contains(t, resp.Content, "Error executing tool calc")
+			assert.Contains(t, resp.Content, tt.errorText)
+		})
+	}
+}
+
+func TestValidateToolArguments_NestedSchema(t *testing.T) {
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"filter": {
+				"type": "object",
+				"properties": {"limit": {"type": ["integer", "null"]}},
+				"required": ["limit"]
+			}
+		}
+	}`), &schema))
+
+	parse := func(s string) interface{} {
+		var v interface{}
+		require.NoError(t, json.Unmarshal([]byte(s), &v))
+		return v
+	}
+
+	assert.NoError(t, ValidateToolArguments(schema, parse(`{"tags": ["a"], "filter": {"limit": null}}`)))
+	assert.ErrorContains(t, ValidateToolArguments(schema, parse(`{"tags": ["a", 1]}`)), "tags[1]: expected string")
+	assert.ErrorContains(t, ValidateToolArguments(schema, parse(`{"filter": {}}`)), `filter: missing required property "limit"`)
+	assert.ErrorContains(t, ValidateToolArguments(schema, parse(`[]`)), "arguments: expected object, got array")
+}
+
+func TestCreateAgent_AdvertisesToolSchema(t *testing.T) {
+	calc := &MockSchemaTool{name: "calc"}
+	mockLLM := &MockLLMWithOptionsCapture{
+		responses: []llms.ContentResponse{
+			{Choices: []*llms.ContentChoice{{ToolCalls: []llms.ToolCall{toolCallMessage("call-1", "calc", `{"a": 1}`).Parts[0].(llms.ToolCall)}}}},
+			{Choices: []*llms.ContentChoice{{Content: "done"}}},
+		},
+	}
+
+	agent, err := CreateAgent(mockLLM, []tools.Tool{calc})
+	require.NoError(t, err)
+
+	res, err := agent.Invoke(context.Background(), map[string]interface{}{
+		"messages": []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "add")},
+	})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, mockLLM.tools)
+	assert.Equal(t, calc.Schema(), mockLLM.tools[0][0].Function.Parameters)
+
+	// The validation error is sent back to the model as a tool message
+	messages := res.(map[string]interface{})["messages"].([]llms.MessageContent)
+	require.Len(t, messages, 4)
+	resp := messages[2].Parts[0].(llms.ToolCallResponse)
+	assert.Contains(t, resp.Content, `missing required property "b"`)
+	assert.Empty(t, calc.inputs)
+}
+
+// MockLLMWithOptionsCapture captures the tools passed with each call
+type MockLLMWithOptionsCapture struct {
+	responses []llms.ContentResponse
+	callCount int
+	tools     [][]llms.Tool
+}
+
+func (m *MockLLMWithOptionsCapture) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	m.tools = append(m.tools, opts.Tools)
+
+	if m.callCount >= len(m.responses) {
+		return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: "No more responses"}}}, nil
+	}
+	resp := m.responses[m.callCount]
+	m.callCount++
+	return &resp, nil
+}
+
+func (m *MockLLMWithOptionsCapture) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return "", nil
+}
@@ -0,0 +1,383 @@
+// Package promgraph exposes Prometheus metrics for graph runs, nodes, LLM and tool
+// calls, and checkpoint saves.
+//
+// Metrics is a graph.TraceHook: it is added to the tracer of a runnable and updates
+// the metrics from the spans of the runs. Checkpoint saves are measured by wrapping
+// the checkpoint store with InstrumentStore. Metrics is a prometheus.Collector that
+// can be registered in any registry, and Handler serves it on its own.
+//
+//	metrics := promgraph.New()
+//	runnable.SetTracer(promgraph.NewTracer(metrics))
+//	http.Handle("/metrics", metrics.Handler())
+//
+// The node, tool and error type label values come from the graph and its errors. To
+// keep the number of series bounded, each label keeps at most MaxLabelValues distinct
+// values (see WithMaxLabelValues); further values are reported as OtherLabelValue.
+package promgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+// DefaultNamespace is the namespace of the metric names
+const DefaultNamespace = "langgraph"
+
+// MaxLabelValues is the default number of distinct values kept per label
+const MaxLabelValues = 100
+
+// OtherLabelValue replaces the label values over the limit of their label
+const OtherLabelValue = "other"
+
+// Statuses of runs, nodes and calls
+const (
+	StatusSuccess     = "success"
+	StatusError       = "error"
+	StatusInterrupted = "interrupted"
+)
+
+// Option configures Metrics
+type Option func(*Metrics)
+
+// WithNamespace sets the namespace of the metric names. Defaults to DefaultNamespace.
+func WithNamespace(namespace string) Option {
+	return func(m *Metrics) {
+		m.namespace = namespace
+	}
+}
+
+// WithConstLabels adds labels with fixed values to all the metrics, e.g. the service
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(m *Metrics) {
+		m.constLabels = labels
+	}
+}
+
+// WithDurationBuckets sets the buckets, in seconds, of the run, node and tool call
+// duration histograms. Defaults to prometheus.DefBuckets.
+func WithDurationBuckets(buckets []float64) Option {
+	return func(m *Metrics) {
+		m.durationBuckets = buckets
+	}
+}
+
+// WithMaxLabelValues sets the number of distinct values kept for each of the node,
+// tool and error_type labels. Zero or less keeps all the values.
+func WithMaxLabelValues(max int) Option {
+	return func(m *Metrics) {
+		m.labels.max = max
+	}
+}
+
+// WithLabelMapper sets a function mapping the values of the node, tool and error_type
+// labels before they are limited, e.g. to group the nodes of a fan-out under one value
+func WithLabelMapper(mapper func(label, value string) string) Option {
+	return func(m *Metrics) {
+		m.labels.mapper = mapper
+	}
+}
+
+// WithErrorClassifier sets the function returning the error_type label of node errors.
+// Defaults to ErrorType.
+func WithErrorClassifier(classifier func(err error) string) Option {
+	return func(m *Metrics) {
+		m.classify = classifier
+	}
+}
+
+// Metrics collects the metrics of graph runs
+type Metrics struct {
+	namespace       string
+	constLabels     prometheus.Labels
+	durationBuckets []float64
+	classify        func(err error) string
+	labels          labelLimiter
+
+	activeRuns        prometheus.Gauge
+	runs              *prometheus.CounterVec
+	runDuration       *prometheus.HistogramVec
+	nodeDuration      *prometheus.HistogramVec
+	nodeErrors        *prometheus.CounterVec
+	nodeRetries       *prometheus.CounterVec
+	toolCalls         *prometheus.CounterVec
+	toolDuration      *prometheus.HistogramVec
+	llmCalls          *prometheus.CounterVec
+	llmTokens         *prometheus.CounterVec
+	checkpointSaves   *prometheus.HistogramVec
+	checkpointSize    prometheus.Histogram
+	collectors        []prometheus.Collector
+	startedNodesMutex sync.Mutex
+	startedNodes      map[string]bool
+}
+
+var (
+	_ graph.TraceHook      = (*Metrics)(nil)
+	_ prometheus.Collector = (*Metrics)(nil)
+)
+
+// New creates the metrics with the given options
+func New(opts ...Option) *Metrics {
+	m := &Metrics{
+		namespace:       DefaultNamespace,
+		durationBuckets: prometheus.DefBuckets,
+		classify:        ErrorType,
+		labels:          labelLimiter{max: MaxLabelValues},
+		startedNodes:    make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.activeRuns = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: m.namespace, ConstLabels: m.constLabels,
+		Name: "active_runs",
+		Help: "Number of graph runs in progress.",
+	})
+	m.runs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: m.namespace, ConstLabels: m.constLabels,
+		Name: "runs_total",
+		Help: "Number of graph runs, by status.",
+	}, []string{"status"})
+	m.runDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: m.namespace, ConstLabels: m.constLabels,
+		Name:    "run_duration_seconds",
+		Help:    "Duration of graph runs, by status.",
+		Buckets: m.durationBuckets,
+	}, []string{"status"})
+	m.nodeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: m.namespace, ConstLabels: m.constLabels,
+		Name:    "node_duration_seconds",
+		Help:    "Duration of node executions, including retries, by node and status.",
+		Buckets: m.durationBuckets,
+	}, []string{"node", "status"})
+	m.nodeErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: m.namespace, ConstLabels: m.constLabels,
+		Name: "node_errors_total",
+		Help: "Number of failed node executions, by node and error type.",
+	}, []string{"node", "error_type"})
+	m.nodeRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: m.namespace, ConstLabels: m.constLabels,
+		Name: "node_retries_total",
+		Help: "Number of node attempts retried, by node.",
+	}, []string{"node"})
+	m.toolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: m.namespace, ConstLabels: m.constLabels,
+		Name: "tool_calls_total",
+		Help: "Number of tool calls, by tool and status.",
+	}, []string{"tool", "status"})
+	m.toolDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: m.namespace, ConstLabels: m.constLabels,
+		Name:    "tool_call_duration_seconds",
+		Help:    "Duration of tool calls, by tool.",
+		Buckets: m.durationBuckets,
+	}, []string{"tool"})
+	m.llmCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: m.namespace, ConstLabels: m.constLabels,
+		Name: "llm_calls_total",
+		Help: "Number of LLM calls, by node and status.",
+	}, []string{"node", "status"})
+	m.llmTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: m.namespace, ConstLabels: m.constLabels,
+		Name: "llm_tokens_total",
+		Help: "Number of tokens used by LLM calls, by node and type (prompt or completion).",
+	}, []string{"node", "type"})
+	m.checkpointSaves = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: m.namespace, ConstLabels: m.constLabels,
+		Name:    "checkpoint_save_duration_seconds",
+		Help:    "Duration of checkpoint saves, by status.",
+		Buckets: prometheus.ExponentialBuckets(0.0005, 4, 10),
+	}, []string{"status"})
+	m.checkpointSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: m.namespace, ConstLabels: m.constLabels,
+		Name:    "checkpoint_size_bytes",
+		Help:    "Size of the saved checkpoints, encoded as JSON.",
+		Buckets: prometheus.ExponentialBuckets(256, 4, 10),
+	})
+
+	m.collectors = []prometheus.Collector{
+		m.activeRuns, m.runs, m.runDuration,
+		m.nodeDuration, m.nodeErrors, m.nodeRetries,
+		m.toolCalls, m.toolDuration,
+		m.llmCalls, m.llmTokens,
+		m.checkpointSaves, m.checkpointSize,
+	}
+	return m
+}
+
+// NewTracer creates a graph.Tracer updating metrics. The spans are not collected by
+// the tracer. To export the spans too, add metrics to the hooks of another tracer.
+func NewTracer(metrics *Metrics) *graph.Tracer {
+	tracer := graph.NewTracer().WithSpanCollection(false)
+	tracer.AddHook(metrics)
+	return tracer
+}
+
+// Describe implements prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors {
+		c.Collect(ch)
+	}
+}
+
+// Handler returns an http.Handler serving the metrics in the Prometheus and
+// OpenMetrics text formats
+func (m *Metrics) Handler() http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(m)
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{EnableOpenMetrics: true})
+}
+
+// OnEvent implements graph.TraceHook
+func (m *Metrics) OnEvent(_ context.Context, span *graph.TraceSpan) {
+	ended := !span.EndTime.IsZero()
+
+	switch span.Event {
+	case graph.TraceEventGraphStart:
+		// Subgraph runs are part of the run of their parent
+		if span.ParentID == "" {
+			m.activeRuns.Inc()
+		}
+
+	case graph.TraceEventGraphEnd:
+		if span.ParentID == "" {
+			status := status(span.Error)
+			m.activeRuns.Dec()
+			m.runs.WithLabelValues(status).Inc()
+			m.runDuration.WithLabelValues(status).Observe(span.Duration.Seconds())
+		}
+
+	case graph.TraceEventNodeStart:
+		m.startedNodesMutex.Lock()
+		m.startedNodes[span.ID] = true
+		m.startedNodesMutex.Unlock()
+
+	case graph.TraceEventNodeEnd, graph.TraceEventNodeError:
+		// The engine also reports node errors in spans of their own, which are skipped
+		m.startedNodesMutex.Lock()
+		started := m.startedNodes[span.ID]
+		delete(m.startedNodes, span.ID)
+		m.startedNodesMutex.Unlock()
+		if !started {
+			return
+		}
+
+		node := m.labels.value("node", span.NodeName)
+		status := status(span.Error)
+		m.nodeDuration.WithLabelValues(node, status).Observe(span.Duration.Seconds())
+		if status == StatusError {
+			m.nodeErrors.WithLabelValues(node, m.labels.value("error_type", m.classify(span.Error))).Inc()
+		}
+
+	case graph.TraceEventNodeRetry:
+		if ended {
+			m.nodeRetries.WithLabelValues(m.labels.value("node", span.NodeName)).Inc()
+		}
+
+	case graph.TraceEventToolEnd:
+		tool, _ := span.Metadata["tool"].(string)
+		tool = m.labels.value("tool", tool)
+		m.toolCalls.WithLabelValues(tool, status(span.Error)).Inc()
+		m.toolDuration.WithLabelValues(tool).Observe(span.Duration.Seconds())
+
+	case graph.TraceEventLLMEnd:
+		node := m.labels.value("node", span.NodeName)
+		m.llmCalls.WithLabelValues(node, status(span.Error)).Inc()
+		if tokens, ok := span.Metadata["prompt_tokens"].(int); ok {
+			m.llmTokens.WithLabelValues(node, "prompt").Add(float64(tokens))
+		}
+		if tokens, ok := span.Metadata["completion_tokens"].(int); ok {
+			m.llmTokens.WithLabelValues(node, "completion").Add(float64(tokens))
+		}
+	}
+}
+
+// status returns the status of a run, node or call that ended with err
+func status(err error) string {
+	if err == nil {
+		return StatusSuccess
+	}
+	var graphInterrupt *graph.GraphInterrupt
+	var nodeInterrupt *graph.NodeInterrupt
+	if errors.As(err, &graphInterrupt) || errors.As(err, &nodeInterrupt) {
+		return StatusInterrupted
+	}
+	return StatusError
+}
+
+// ErrorType classifies node errors: timeouts, cancellations and the errors of the node
+// policies have their own type, other errors are classified by the Go type of the
+// error they wrap
+func ErrorType(err error) string {
+	switch {
+	case errors.Is(err, graph.ErrNodeTimeout):
+		return "node_timeout"
+	case errors.Is(err, graph.ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, graph.ErrRateLimitExceeded):
+		return "rate_limited"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "deadline_exceeded"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	}
+
+	for {
+		unwrapped := errors.Unwrap(err)
+		if unwrapped == nil {
+			return fmt.Sprintf("%T", err)
+		}
+		err = unwrapped
+	}
+}
+
+// labelLimiter bounds the number of distinct values of each label
+type labelLimiter struct {
+	max    int
+	mapper func(label, value string) string
+
+	mutex  sync.Mutex
+	values map[string]map[string]bool
+}
+
+// value returns the value reported for a label
+func (l *labelLimiter) value(label, value string) string {
+	if l.mapper != nil {
+		value = l.mapper(label, value)
+	}
+	if l.max <= 0 {
+		return value
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.values == nil {
+		l.values = make(map[string]map[string]bool)
+	}
+	values, ok := l.values[label]
+	if !ok {
+		values = make(map[string]bool)
+		l.values[label] = values
+	}
+	if values[value] {
+		return value
+	}
+	if len(values) >= l.max {
+		return OtherLabelValue
+	}
+	values[value] = true
+	return value
+}
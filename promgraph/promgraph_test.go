@@ -0,0 +1,198 @@
+package promgraph
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/smallnest/langgraphgo/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+var errLookup = errors.New("lookup failed")
+
+// usageLLM answers with a fixed token usage
+type usageLLM struct{}
+
+func (m *usageLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{
+		Content:        "answer",
+		GenerationInfo: map[string]interface{}{"input_tokens": 12, "output_tokens": 5},
+	}}}, nil
+}
+
+func (m *usageLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return "answer", nil
+}
+
+// newAgentRunnable builds agent -> tools, where the tool fails when the state is "fail"
+func newAgentRunnable(t *testing.T, metrics *Metrics) *graph.StateRunnable {
+	g := graph.NewStateGraph()
+	g.AddNode("agent", "agent", func(ctx context.Context, state interface{}) (interface{}, error) {
+		if _, err := graph.GenerateContent(ctx, &usageLLM{}, nil); err != nil {
+			return nil, err
+		}
+		return state, nil
+	})
+	g.AddNode("tools", "tools", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return graph.CallTool(ctx, "search", state.(string), func(ctx context.Context, input string) (string, error) {
+			if input == "fail" {
+				return "", errLookup
+			}
+			return "found " + input, nil
+		})
+	})
+	g.AddEdge("agent", "tools")
+	g.AddEdge("tools", graph.END)
+	g.SetEntryPoint("agent")
+
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+	runnable.SetTracer(NewTracer(metrics))
+	return runnable
+}
+
+// sampleCounts returns the number of observations of a histogram, by the values of
+// label
+func sampleCounts(t *testing.T, metrics *Metrics, name, label string) map[string]uint64 {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(metrics))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	counts := make(map[string]uint64)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			value := ""
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == label {
+					value = pair.GetValue()
+				}
+			}
+			counts[value] += metric.GetHistogram().GetSampleCount()
+		}
+	}
+	return counts
+}
+
+func TestMetrics_Runs(t *testing.T) {
+	metrics := New()
+	runnable := newAgentRunnable(t, metrics)
+
+	for _, input := range []string{"a", "b", "fail"} {
+		_, _ = runnable.Invoke(context.Background(), input)
+	}
+
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.activeRuns))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.runs.WithLabelValues(StatusSuccess)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.runs.WithLabelValues(StatusError)))
+	assert.Equal(t, map[string]uint64{StatusSuccess: 2, StatusError: 1}, sampleCounts(t, metrics, "langgraph_run_duration_seconds", "status"))
+
+	assert.Equal(t, map[string]uint64{"agent": 3, "tools": 3}, sampleCounts(t, metrics, "langgraph_node_duration_seconds", "node"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.nodeErrors.WithLabelValues("tools", "*errors.errorString")))
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.toolCalls.WithLabelValues("search", StatusSuccess)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.toolCalls.WithLabelValues("search", StatusError)))
+	assert.Equal(t, 3.0, testutil.ToFloat64(metrics.llmCalls.WithLabelValues("agent", StatusSuccess)))
+	assert.Equal(t, 36.0, testutil.ToFloat64(metrics.llmTokens.WithLabelValues("agent", "prompt")))
+	assert.Equal(t, 15.0, testutil.ToFloat64(metrics.llmTokens.WithLabelValues("agent", "completion")))
+}
+
+func TestMetrics_InterruptedRun(t *testing.T) {
+	metrics := New()
+	g := graph.NewStateGraph()
+	g.AddNode("review", "review", func(ctx context.Context, state interface{}) (interface{}, error) {
+		return graph.Interrupt(ctx, "approve?")
+	})
+	g.SetEntryPoint("review")
+	g.AddEdge("review", graph.END)
+	runnable, err := g.Compile()
+	require.NoError(t, err)
+	runnable.SetTracer(NewTracer(metrics))
+
+	_, err = runnable.Invoke(context.Background(), "input")
+	require.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.runs.WithLabelValues(StatusInterrupted)))
+	assert.Equal(t, 0, testutil.CollectAndCount(metrics.nodeErrors))
+}
+
+func TestMetrics_LimitsLabelValues(t *testing.T) {
+	metrics := New(
+		WithMaxLabelValues(1),
+		WithErrorClassifier(func(err error) string { return "lookup" }),
+	)
+	runnable := newAgentRunnable(t, metrics)
+
+	_, err := runnable.Invoke(context.Background(), "fail")
+	require.Error(t, err)
+
+	// The first node keeps its name, the next one is reported as other
+	assert.Equal(t, map[string]uint64{"agent": 1, OtherLabelValue: 1}, sampleCounts(t, metrics, "langgraph_node_duration_seconds", "node"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.nodeErrors.WithLabelValues(OtherLabelValue, "lookup")))
+
+	mapped := New(WithLabelMapper(func(label, value string) string {
+		if label == "node" {
+			return "all"
+		}
+		return value
+	}))
+	runnable = newAgentRunnable(t, mapped)
+	_, err = runnable.Invoke(context.Background(), "ok")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]uint64{"all": 2}, sampleCounts(t, mapped, "langgraph_node_duration_seconds", "node"))
+}
+
+func TestMetrics_InstrumentStore(t *testing.T) {
+	metrics := New()
+	g := graph.NewCheckpointableStateGraphWithConfig(graph.CheckpointConfig{
+		Store:    metrics.InstrumentStore(graph.NewMemoryCheckpointStore()),
+		AutoSave: true,
+	})
+	for _, name := range []string{"a", "b"} {
+		name := name
+		g.AddNode(name, name, func(ctx context.Context, state interface{}) (interface{}, error) {
+			return state.(string) + name, nil
+		})
+	}
+	g.AddEdge("a", "b")
+	g.AddEdge("b", graph.END)
+	g.SetEntryPoint("a")
+	runnable, err := g.CompileCheckpointable()
+	require.NoError(t, err)
+
+	_, err = runnable.Invoke(context.Background(), "")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]uint64{StatusSuccess: 2}, sampleCounts(t, metrics, "langgraph_checkpoint_save_duration_seconds", "status"))
+	assert.Equal(t, map[string]uint64{"": 2}, sampleCounts(t, metrics, "langgraph_checkpoint_size_bytes", ""))
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	metrics := New(WithNamespace("agents"))
+	_, err := newAgentRunnable(t, metrics).Invoke(context.Background(), "a")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(metrics.Handler())
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.True(t, strings.Contains(string(body), `agents_runs_total{status="success"} 1`), string(body))
+	assert.True(t, strings.Contains(string(body), `agents_tool_calls_total{status="success",tool="search"} 1`), string(body))
+}
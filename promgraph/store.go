@@ -0,0 +1,34 @@
+package promgraph
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/smallnest/langgraphgo/graph"
+)
+
+// instrumentedStore measures the saves of a checkpoint store
+type instrumentedStore struct {
+	graph.CheckpointStore
+	metrics *Metrics
+}
+
+// InstrumentStore returns store measuring the latency and the size of its saves
+func (m *Metrics) InstrumentStore(store graph.CheckpointStore) graph.CheckpointStore {
+	return &instrumentedStore{CheckpointStore: store, metrics: m}
+}
+
+// Save implements graph.CheckpointStore
+func (s *instrumentedStore) Save(ctx context.Context, checkpoint *graph.Checkpoint) error {
+	start := time.Now()
+	err := s.CheckpointStore.Save(ctx, checkpoint)
+	s.metrics.checkpointSaves.WithLabelValues(status(err)).Observe(time.Since(start).Seconds())
+
+	if err == nil {
+		if data, marshalErr := json.Marshal(checkpoint); marshalErr == nil {
+			s.metrics.checkpointSize.Observe(float64(len(data)))
+		}
+	}
+	return err
+}
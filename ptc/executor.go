@@ -12,6 +12,8 @@ import (
 
 	"github.com/smallnest/langgraphgo/log"
 	"github.com/tmc/langchaingo/tools"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ExecutionLanguage defines the programming language for code execution
@@ -83,6 +85,14 @@ func NewCodeExecutorWithMode(language ExecutionLanguage, toolList []tools.Tool,
 	return executor
 }
 
+// SetTracerProvider sets the OpenTelemetry tracer provider of the tool call spans.
+// A nil provider, the default, uses the global one.
+func (ce *CodeExecutor) SetTracerProvider(provider trace.TracerProvider) {
+	if ce.toolServer != nil {
+		ce.toolServer.SetTracerProvider(provider)
+	}
+}
+
 // Start starts the code executor and its tool server
 // In both modes, the server is started for tool access:
 // - Direct mode: Internal server for generic tools (not exposed in wrappers)
@@ -175,6 +185,7 @@ import sys
 	defer cancel()
 
 	cmd := exec.CommandContext(execCtx, "python3", scriptPath)
+	withTraceContext(ctx, cmd)
 	output, err := cmd.CombinedOutput()
 
 	result := &ExecutionResult{
@@ -246,6 +257,7 @@ func main() {
 	defer cancel()
 
 	cmd := exec.CommandContext(execCtx, "go", "run", scriptPath)
+	withTraceContext(ctx, cmd)
 	output, err := cmd.CombinedOutput()
 
 	result := &ExecutionResult{
@@ -260,6 +272,50 @@ func main() {
 	return result, nil
 }
 
+// withTraceContext passes the trace context of ctx to the code run by cmd through the
+// TRACEPARENT and TRACESTATE environment variables. The tool wrappers send them to the
+// tool server as W3C trace context headers, so tool calls continue the trace of the run.
+func withTraceContext(ctx context.Context, cmd *exec.Cmd) {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return
+	}
+
+	cmd.Env = append(os.Environ(), "TRACEPARENT="+carrier.Get("traceparent"))
+	if tracestate := carrier.Get("tracestate"); tracestate != "" {
+		cmd.Env = append(cmd.Env, "TRACESTATE="+tracestate)
+	}
+}
+
+// pythonRequestHeaders is the Python helper returning the headers of tool server
+// requests, shared by the Python tool wrappers
+const pythonRequestHeaders = `
+def _request_headers():
+    """Headers of tool server requests, continuing the trace of the run"""
+    headers = {'Content-Type': 'application/json'}
+    if os.environ.get('TRACEPARENT'):
+        headers['traceparent'] = os.environ['TRACEPARENT']
+        if os.environ.get('TRACESTATE'):
+            headers['tracestate'] = os.environ['TRACESTATE']
+    return headers
+`
+
+// goRequestHeaders is the Go helper setting the headers of tool server requests,
+// shared by the Go tool wrappers and the tool helper program
+const goRequestHeaders = `
+// setRequestHeaders sets the headers of a tool server request, continuing the trace of the run
+func setRequestHeaders(req *http.Request) {
+	req.Header.Set("Content-Type", "application/json")
+	if traceparent := os.Getenv("TRACEPARENT"); traceparent != "" {
+		req.Header.Set("traceparent", traceparent)
+		if tracestate := os.Getenv("TRACESTATE"); tracestate != "" {
+			req.Header.Set("tracestate", tracestate)
+		}
+	}
+}
+`
+
 // generatePythonToolWrappersServer creates Python wrapper functions for tools (server mode)
 func (ce *CodeExecutor) generatePythonToolWrappersServer() string {
 	var wrappers []string
@@ -278,6 +334,7 @@ func (ce *CodeExecutor) generatePythonToolWrappersServer() string {
 	wrapper := fmt.Sprintf(`
 # Available tools: %s
 import json
+import os
 try:
     import urllib.request
 except ImportError:
@@ -285,6 +342,7 @@ except ImportError:
 
 TOOL_SERVER_URL = "%s"
 
+%s
 def call_tool(tool_name, tool_input):
     """Call a tool through the HTTP tool server"""
     try:
@@ -294,7 +352,7 @@ def call_tool(tool_name, tool_input):
             "input": tool_input
         }).encode('utf-8')
 
-        req = urllib.request.Request(url, data=data, headers={'Content-Type': 'application/json'})
+        req = urllib.request.Request(url, data=data, headers=_request_headers())
         response = urllib.request.urlopen(req)
         result = json.loads(response.read().decode('utf-8'))
 
@@ -304,7 +362,7 @@ def call_tool(tool_name, tool_input):
             return f"Error calling tool {tool_name}: {result.get('error', 'Unknown error')}"
     except Exception as e:
         return f"Error calling tool {tool_name}: {str(e)}"
-`, string(toolsJSON), serverURL)
+`, string(toolsJSON), serverURL, pythonRequestHeaders)
 
 	wrappers = append(wrappers, wrapper)
 
@@ -345,6 +403,7 @@ except ImportError:
 
 INTERNAL_TOOL_SERVER = "%s"
 
+%s
 # Helper function to call generic tools via internal server
 def _call_generic_tool(tool_name, tool_input):
     """Call a generic tool through the internal tool server"""
@@ -355,7 +414,7 @@ def _call_generic_tool(tool_name, tool_input):
             "input": tool_input
         }).encode('utf-8')
 
-        req = urllib.request.Request(url, data=data, headers={'Content-Type': 'application/json'})
+        req = urllib.request.Request(url, data=data, headers=_request_headers())
         response = urllib.request.urlopen(req)
         result = json.loads(response.read().decode('utf-8'))
 
@@ -423,7 +482,7 @@ def _write_file(file_path, content):
         return f"Successfully wrote to {file_path}"
     except Exception as e:
         return f"File write error: {str(e)}"
-`, serverURL)
+`, serverURL, pythonRequestHeaders)
 	wrappers = append(wrappers, wrapper)
 
 	// Generate embedded tool functions based on tool name patterns
@@ -579,7 +638,7 @@ func callTool(ctx context.Context, toolName string, toolInput interface{}) (stri
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %%w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	setRequestHeaders(req)
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -610,7 +669,7 @@ func callTool(ctx context.Context, toolName string, toolInput interface{}) (stri
 	}
 	return "", fmt.Errorf("tool execution failed: %%s", errorMsg)
 }
-`, serverURL)
+%s`, serverURL, goRequestHeaders)
 	wrappers = append(wrappers, wrapper)
 
 	// Generate individual tool functions
@@ -656,7 +715,7 @@ func callGenericTool(ctx context.Context, toolName string, input string) (string
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %%w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	setRequestHeaders(req)
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -750,7 +809,7 @@ func writeFile(filePath string, content string) (string, error) {
 	}
 	return fmt.Sprintf("Successfully wrote to %%s", filePath), nil
 }
-`, serverURL)
+%s`, serverURL, goRequestHeaders)
 	wrappers = append(wrappers, wrapper)
 
 	// Generate embedded tool functions based on tool name patterns
@@ -995,7 +1054,7 @@ func callToolServer(ctx context.Context, toolName string, input string) (string,
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %%w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	setRequestHeaders(req)
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -1020,7 +1079,7 @@ func callToolServer(ctx context.Context, toolName string, input string) (string,
 
 	return result.Result, nil
 }
-
+%s
 %s
 
 func main() {
@@ -1069,7 +1128,7 @@ func respondError(errMsg string) {
 	}
 	json.NewEncoder(os.Stdout).Encode(resp)
 }
-`, serverURL, strings.Join(toolFuncs, "\n"), strings.Join(toolCases, "\n"))
+`, serverURL, goRequestHeaders, strings.Join(toolFuncs, "\n"), strings.Join(toolCases, "\n"))
 
 	return source
 }
@@ -2,12 +2,16 @@ package ptc_test
 
 import (
 	"context"
+	"net/http"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/smallnest/langgraphgo/ptc"
 	"github.com/tmc/langchaingo/tools"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // TestModeDirectExecution tests that ModeDirect mode actually executes tools
@@ -92,6 +96,84 @@ print(result)
 	}
 }
 
+// TestToolCallsContinueTrace tests that tool calls made by the code are traced in the
+// trace of the caller
+func TestToolCallsContinueTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	for _, mode := range []ptc.ExecutionMode{ptc.ModeServer, ptc.ModeDirect} {
+		t.Run(string(mode), func(t *testing.T) {
+			exporter.Reset()
+			tools := []tools.Tool{
+				MockTool{
+					name:        "echo",
+					description: "Echoes input",
+					response:    "echoed: test",
+				},
+			}
+
+			executor := ptc.NewCodeExecutorWithMode(ptc.LanguagePython, tools, mode)
+			executor.SetTracerProvider(provider)
+			ctx := context.Background()
+			if err := executor.Start(ctx); err != nil {
+				t.Fatalf("Failed to start executor: %v", err)
+			}
+			defer executor.Stop(ctx)
+
+			ctx, parent := sdktrace.NewTracerProvider().Tracer("test").Start(ctx, "node")
+			result, err := executor.Execute(ctx, `print(echo("hello"))`)
+			parent.End()
+			if err != nil {
+				t.Fatalf("Failed to execute code: %v", err)
+			}
+			if !strings.Contains(result.Output, "echoed") {
+				t.Fatalf("Expected output to contain 'echoed', got: %s", result.Output)
+			}
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 || spans[0].Name != "execute_tool echo" {
+				t.Fatalf("Expected one tool call span, got %v", spans)
+			}
+			if spans[0].Parent.TraceID() != parent.SpanContext().TraceID() || spans[0].Parent.SpanID() != parent.SpanContext().SpanID() {
+				t.Errorf("Expected the tool call span in the span of the caller, got parent %v", spans[0].Parent)
+			}
+		})
+	}
+}
+
+// TestToolCallsUseGlobalTracerProvider tests that tool calls are traced with the
+// global tracer provider by default
+func TestToolCallsUseGlobalTracerProvider(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(previous)
+
+	server := ptc.NewToolServer([]tools.Tool{
+		MockTool{
+			name:        "echo",
+			description: "Echoes input",
+			response:    "echoed: test",
+		},
+	})
+	ctx := context.Background()
+	if err := server.Start(ctx); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop(ctx)
+
+	resp, err := http.Post(server.GetBaseURL()+"/call", "application/json", strings.NewReader(`{"tool_name":"echo","input":"hello"}`))
+	if err != nil {
+		t.Fatalf("Failed to call tool: %v", err)
+	}
+	resp.Body.Close()
+
+	if spans := exporter.GetSpans(); len(spans) != 1 || spans[0].Name != "execute_tool echo" {
+		t.Errorf("Expected one tool call span, got %v", spans)
+	}
+}
+
 // TestExecutorTimeout tests execution timeout
 func TestExecutorTimeout(t *testing.T) {
 	tools := []tools.Tool{
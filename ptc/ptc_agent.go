@@ -8,6 +8,7 @@ import (
 	"github.com/smallnest/langgraphgo/graph"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/tools"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // PTCAgentConfig configures a PTC agent
@@ -31,6 +32,9 @@ type PTCAgentConfig struct {
 
 	// MaxIterations is the maximum number of iterations (default: 10)
 	MaxIterations int
+
+	// TracerProvider traces the tool calls (default: the global OpenTelemetry provider)
+	TracerProvider trace.TracerProvider
 }
 
 // CreatePTCAgent creates a new agent that uses programmatic tool calling
@@ -59,6 +63,7 @@ func CreatePTCAgent(config PTCAgentConfig) (*graph.Runnable, error) {
 
 	// Create PTC tool node with execution mode
 	ptcNode := NewPTCToolNodeWithMode(config.Language, config.Tools, config.ExecutionMode)
+	ptcNode.Executor.SetTracerProvider(config.TracerProvider)
 
 	// Start the tool server
 	if err := ptcNode.Executor.Start(context.Background()); err != nil {
@@ -102,7 +107,7 @@ func CreatePTCAgent(config PTCAgentConfig) (*graph.Runnable, error) {
 
 		// Otherwise, we're done
 		return graph.END
-	})
+	}, "execute_code", graph.END)
 
 	// Add edge from execute_code back to agent
 	workflow.AddEdge("execute_code", "agent")
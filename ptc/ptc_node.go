@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/smallnest/langgraphgo/graph"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/tools"
 )
@@ -63,9 +64,12 @@ func (node *PTCToolNode) Invoke(ctx context.Context, state interface{}) (interfa
 
 	// Note: Tool server is already started in CreatePTCAgent, no need to start again
 
-	// Execute the code
+	// Execute the code, reporting progress to the active stream
+	writer := graph.GetStreamWriter(ctx)
+	writer(map[string]interface{}{"status": "executing", "language": string(node.Executor.Language)})
 	result, err := node.Executor.Execute(ctx, code)
 	if err != nil {
+		writer(map[string]interface{}{"status": "failed", "error": err.Error()})
 		// Create error message as system message
 		errorMsg := llms.MessageContent{
 			Role: llms.ChatMessageTypeHuman,
@@ -78,6 +82,8 @@ func (node *PTCToolNode) Invoke(ctx context.Context, state interface{}) (interfa
 	}
 
 	// Create success message with execution results as human message
+	writer(map[string]interface{}{"status": "completed"})
+
 	successMsg := llms.MessageContent{
 		Role: llms.ChatMessageTypeHuman,
 		Parts: []llms.ContentPart{
@@ -11,16 +11,29 @@ import (
 
 	"github.com/smallnest/langgraphgo/log"
 	"github.com/tmc/langchaingo/tools"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracerName is the instrumentation scope of the tool call spans
+const tracerName = "github.com/smallnest/langgraphgo/ptc"
+
 // ToolServer provides an HTTP API for tool execution
 // This allows code in any language to call Go tools via HTTP
+//
+// Tool calls are traced with the OpenTelemetry tracer provider set by
+// SetTracerProvider, the global one by default, continuing the trace of the W3C
+// trace context headers of the request
 type ToolServer struct {
-	tools   map[string]tools.Tool
-	server  *http.Server
-	port    int
-	mu      sync.RWMutex
-	started bool
+	tools          map[string]tools.Tool
+	server         *http.Server
+	port           int
+	mu             sync.RWMutex
+	started        bool
+	tracerProvider trace.TracerProvider
 }
 
 // ToolRequest represents a tool execution request
@@ -52,6 +65,25 @@ func NewToolServer(toolList []tools.Tool) *ToolServer {
 	}
 }
 
+// SetTracerProvider sets the tracer provider of the tool call spans.
+// A nil provider uses the global one.
+func (ts *ToolServer) SetTracerProvider(provider trace.TracerProvider) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.tracerProvider = provider
+}
+
+// tracer returns the tracer of the tool call spans
+func (ts *ToolServer) tracer() trace.Tracer {
+	ts.mu.RLock()
+	provider := ts.tracerProvider
+	ts.mu.RUnlock()
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(tracerName)
+}
+
 // Start starts the tool server on an available port
 func (ts *ToolServer) Start(ctx context.Context) error {
 	ts.mu.Lock()
@@ -198,12 +230,21 @@ func (ts *ToolServer) handleCallTool(w http.ResponseWriter, r *http.Request) {
 
 	log.Debug("Executing tool %s with input length: %d bytes", req.ToolName, len(inputStr))
 
-	// Execute tool
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	// Execute tool, continuing the trace of the code calling it
+	ctx := propagation.TraceContext{}.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx, span := ts.tracer().Start(ctx, "execute_tool "+req.ToolName,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("gen_ai.tool.name", req.ToolName)),
+	)
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	result, err := tool.Call(ctx, inputStr)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		log.Error("Tool %s execution failed: %v", req.ToolName, err)
 		ts.sendErrorResponse(w, req.ToolName, req.Input, fmt.Sprintf("Tool execution failed: %v", err))
 		return
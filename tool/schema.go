@@ -0,0 +1,25 @@
+package tool
+
+import "encoding/json"
+
+// SchemaMap converts a JSON schema of any representation (a map, raw JSON or a
+// struct) to a map, e.g. to implement the Schema method of the prebuilt
+// ToolWithSchema interface. It returns nil when the schema is empty or not an object.
+func SchemaMap(schema any) map[string]any {
+	switch s := schema.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		return s
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil
+	}
+	var result map[string]any
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil
+	}
+	return result
+}
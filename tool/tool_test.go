@@ -2,6 +2,7 @@ package tool
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -200,3 +201,17 @@ func TestBraveSearch_Call(t *testing.T) {
 	assert.Contains(t, result, "URL: http://brave.example.com")
 	assert.Contains(t, result, "Description: This is brave content.")
 }
+
+func TestSchemaMap(t *testing.T) {
+	schema := map[string]any{"type": "object"}
+	assert.Equal(t, schema, SchemaMap(schema))
+
+	// Raw JSON and structs are converted through JSON
+	assert.Equal(t, schema, SchemaMap(json.RawMessage(`{"type":"object"}`)))
+	assert.Equal(t, schema, SchemaMap(struct {
+		Type string `json:"type"`
+	}{Type: "object"}))
+
+	assert.Nil(t, SchemaMap(nil))
+	assert.Nil(t, SchemaMap("not an object"))
+}